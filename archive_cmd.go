@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"edinet-api-test/internal/archive"
+)
+
+// runArchiveCommand archiveサブコマンド（アーカイブの一覧・検証・整理）
+//
+//	archive list   -dir DIR
+//	archive verify -dir DIR
+//	archive prune  -dir DIR [-older-than 8760h] [-dry-run]
+func runArchiveCommand(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("使用方法: archive list|verify|prune -dir DIR")
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("archive "+action, flag.ContinueOnError)
+	dir := fs.String("dir", "", "アーカイブのディレクトリ（-archiveで指定したもの）")
+	olderThan := fs.Duration("older-than", 0, "prune: 取得から指定時間以上経ったエントリも削除する（0の場合は壊れたエントリのみ）")
+	dryRun := fs.Bool("dry-run", false, "prune: 削除せずに対象を表示する")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return fmt.Errorf("-dirでアーカイブのディレクトリを指定してください")
+	}
+
+	store, err := archive.Open(*dir)
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		var total int64
+		for _, e := range entries {
+			fmt.Fprintf(out, "%s\t%d\t%d\t%s\t%s\n", e.DocID, e.Type, e.Size, e.FetchedAt.Format(time.RFC3339), e.SHA256)
+			total += e.Size
+		}
+		fmt.Fprintf(out, "%d件, %d bytes\n", len(entries), total)
+		return nil
+
+	case "verify":
+		failed := 0
+		for _, e := range entries {
+			if err := store.Verify(e); err != nil {
+				fmt.Fprintf(out, "NG %s (type=%d): %v\n", e.DocID, e.Type, err)
+				failed++
+			}
+		}
+		fmt.Fprintf(out, "%d件中%d件が不正です\n", len(entries), failed)
+		if failed > 0 {
+			return fmt.Errorf("アーカイブに不正なエントリがあります（archive prune で削除できます）")
+		}
+		return nil
+
+	case "prune":
+		removed := 0
+		for _, e := range entries {
+			reason := ""
+			if err := store.Verify(e); err != nil {
+				reason = err.Error()
+			} else if *olderThan > 0 && time.Since(e.FetchedAt) >= *olderThan {
+				reason = "取得から" + olderThan.String() + "以上経過"
+			}
+			if reason == "" {
+				continue
+			}
+			fmt.Fprintf(out, "削除 %s (type=%d): %s\n", e.DocID, e.Type, reason)
+			removed++
+			if *dryRun {
+				continue
+			}
+			if err := store.Remove(e); err != nil {
+				return err
+			}
+		}
+		if *dryRun {
+			fmt.Fprintf(out, "%d件のエントリが削除対象です（-dry-runのため削除していません）\n", removed)
+			return nil
+		}
+		objects, err := store.PruneObjects()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d件のエントリと%d個のファイルを削除しました\n", removed, objects)
+		return nil
+
+	default:
+		return fmt.Errorf("不明な操作です: %s（list / verify / prune）", action)
+	}
+}
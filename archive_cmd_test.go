@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"edinet-api-test/internal/api"
+	"edinet-api-test/internal/archive"
+)
+
+func TestRunArchiveCommand(t *testing.T) {
+	dir := t.TempDir()
+	store, err := archive.Open(dir)
+	if err != nil {
+		t.Fatalf("アーカイブ作成エラー: %v", err)
+	}
+	store.Put("S100AAAA", int(api.DocumentXBRL), "", []byte("good zip"))
+	bad, _ := store.Put("S100BBBB", int(api.DocumentXBRL), "", []byte("bad zip!"))
+	// 同じサイズで内容を壊す
+	if err := os.WriteFile(store.Path(bad), []byte("corrupt!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runArchiveCommand([]string{"list", "-dir", dir}, &out); err != nil {
+		t.Fatalf("list エラー: %v", err)
+	}
+	if !strings.Contains(out.String(), "S100AAAA") || !strings.Contains(out.String(), "2件") {
+		t.Errorf("list の出力が不正です: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runArchiveCommand([]string{"verify", "-dir", dir}, &out); err == nil {
+		t.Error("壊れたエントリがある場合、verify はエラーを返すべきです")
+	}
+	if !strings.Contains(out.String(), "NG S100BBBB") {
+		t.Errorf("verify の出力が不正です: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runArchiveCommand([]string{"prune", "-dir", dir}, &out); err != nil {
+		t.Fatalf("prune エラー: %v", err)
+	}
+	if err := runArchiveCommand([]string{"verify", "-dir", dir}, &out); err != nil {
+		t.Errorf("prune 後は verify が成功するべきです: %v", err)
+	}
+	entries, _ := store.List()
+	if len(entries) != 1 || entries[0].DocID != "S100AAAA" {
+		t.Errorf("prune 後のエントリが不正です: %+v", entries)
+	}
+
+	if err := runArchiveCommand([]string{"unknown", "-dir", dir}, &out); err == nil {
+		t.Error("不明な操作の場合、エラーが発生すべきです")
+	}
+}
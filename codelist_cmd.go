@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"edinet-api-test/internal/api"
+	"edinet-api-test/internal/config"
+	"edinet-api-test/internal/edinetcode"
+	"edinet-api-test/internal/logging"
+	"edinet-api-test/internal/universe"
+)
+
+// runCodelistCommand codelistサブコマンド（EDINETコードリストの取り込み・検索）
+//
+//	codelist update [-registry FILE] [-zip Edinetcode.zip] [-url URL]
+//	codelist lookup [-registry FILE] KEY...（提出者名はあいまい検索し、複数ある場合は候補を表示）
+func runCodelistCommand(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("使用方法: codelist update|lookup [-registry FILE]")
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("codelist "+action, flag.ContinueOnError)
+	registryPath := fs.String("registry", edinetcode.DefaultPath, "取り込んだ提出者の一覧の保存先")
+	zipFile := fs.String("zip", "", "update: ダウンロード済みのEdinetcode.zipから取り込む")
+	url := fs.String("url", edinetcode.DefaultURL, "update: EDINETコードリストのダウンロードURL")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch action {
+	case "update":
+		var data []byte
+		var err error
+		if *zipFile != "" {
+			data, err = os.ReadFile(*zipFile)
+		} else {
+			fmt.Fprintf(out, "ダウンロード: %s\n", *url)
+			data, err = edinetcode.Download(ctx, &http.Client{Timeout: api.DefaultTimeout}, *url)
+		}
+		if err != nil {
+			return err
+		}
+		registry, err := edinetcode.ParseZip(data)
+		if err != nil {
+			return err
+		}
+		if err := registry.Save(*registryPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d社（%s）を %s に保存しました\n", len(registry.Companies), registry.AsOf, *registryPath)
+		return nil
+
+	case "lookup":
+		if fs.NArg() == 0 {
+			return fmt.Errorf("検索するEDINETコード・証券コード・法人番号・提出者名を指定してください")
+		}
+		registry, err := edinetcode.Load(*registryPath)
+		if err != nil {
+			return err
+		}
+		for _, key := range fs.Args() {
+			c, candidates, ok := registry.Resolve(key, maxCandidates)
+			if !ok {
+				if len(candidates) == 0 {
+					fmt.Fprintf(out, "%s: 見つかりません\n", key)
+				} else {
+					fmt.Fprintf(out, "%s: 候補が%d件あります\n%s", key, len(candidates), formatCandidates(candidates))
+				}
+				continue
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.EdinetCode, c.SecCode, c.JCN, c.Name, c.NameEnglish, c.Industry, c.FiscalYearEnd)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("不明な操作です: %s（update / lookup）", action)
+	}
+}
+
+// maxCandidates 提出者名で候補が複数ある場合に表示する最大件数
+const maxCandidates = 10
+
+// resolveTarget -code・-companyで指定した提出者を提出者の一覧から決定し、対象のEDINETコード・証券コードを設定
+//
+// -companyの提出者名が1社に決まらない場合（候補が複数、または一部の一致・類似度だけの候補）は、
+// interactiveであれば番号で選択し、そうでなければ候補を示してエラーにする。
+// 一覧にない場合はエラーにする。一覧がない場合のみ、コードの形式から判断する（4桁の証券コードは末尾に0を付ける）。
+func resolveTarget(cfg *config.Config, in io.Reader, out io.Writer, interactive bool) error {
+	key := strings.TrimSpace(cfg.Company)
+	if key == "" {
+		key = strings.TrimSpace(cfg.TargetSecCode)
+	}
+	if key == "" {
+		return nil
+	}
+
+	registry, err := loadRegistry(cfg.RegistryFile)
+	if err != nil {
+		return err
+	}
+	if registry == nil {
+		slog.Warn("提出者の一覧がないため、コードの形式から判断します（codelist update で取り込めます）", "registry", cfg.RegistryFile, "key", key)
+	}
+
+	// -codeは証券コード等のみ（提出者名では検索しない）
+	c, err := registry.ResolveKey(key, cfg.Company != "", maxCandidates)
+	var ambiguous *edinetcode.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		if !interactive {
+			return fmt.Errorf("「%s」に該当する提出者を1社に決められません。-companyにEDINETコード・証券コードを指定してください:\n%s", key, formatCandidates(ambiguous.Candidates))
+		}
+		c, err = chooseCandidate(in, out, key, ambiguous.Candidates)
+	}
+	if err != nil {
+		return err
+	}
+	cfg.TargetEdinetCode = c.EdinetCode
+	cfg.TargetSecCode = c.SecCode
+	if registry != nil {
+		slog.Info("対象の提出者", "key", key, "edinetCode", c.EdinetCode, logging.KeySecCode, c.SecCode, "name", c.Name)
+	}
+	return nil
+}
+
+// loadRegistry 提出者の一覧を読み込む（ファイルがない場合はnil）
+func loadRegistry(path string) (*edinetcode.Registry, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return edinetcode.Load(path)
+}
+
+// loadTarget 文書を絞り込む提出者（ウォッチリスト・業種等の条件、または-code・-companyで決めた1社）
+//
+// ウォッチリスト・条件の場合も、文書一覧は1日1回だけ取得し、各文書の提出者をまとめて判定する。
+func loadTarget(cfg *config.Config) (api.DocumentMatcher, error) {
+	if !cfg.HasUniverse() {
+		return api.Target{EdinetCode: cfg.TargetEdinetCode, SecCode: cfg.TargetSecCode}, nil
+	}
+
+	registry, err := loadRegistry(cfg.RegistryFile)
+	if err != nil {
+		return nil, err
+	}
+	var entries []universe.Entry
+	if cfg.Watchlist != "" {
+		if entries, err = universe.LoadWatchlist(cfg.Watchlist); err != nil {
+			return nil, err
+		}
+	}
+	u, err := universe.New(entries, universe.Filter{
+		Industries:          cfg.Industries,
+		Markets:             cfg.Markets,
+		FiscalYearEndMonths: cfg.FiscalYearEndMonths,
+	}, registry)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Watchlist != "" {
+		slog.Info("ウォッチリスト", "companies", u.Size(), "watchlist", cfg.Watchlist)
+	}
+	return u, nil
+}
+
+// formatCandidates 提出者の候補を番号付きで整形
+func formatCandidates(candidates []edinetcode.Match) string {
+	var b strings.Builder
+	for i, m := range candidates {
+		c := m.Company
+		listed := "非上場"
+		if c.Listed {
+			listed = "上場"
+		}
+		fmt.Fprintf(&b, "  %2d. %s  %-5s  %s（%s）%s・%s\n", i+1, c.EdinetCode, c.SecCode, c.Name, c.NameEnglish, c.Industry, listed)
+	}
+	return b.String()
+}
+
+// chooseCandidate 提出者の候補を表示し、番号で選択させる（空行・入力の終わりで中止）
+func chooseCandidate(in io.Reader, out io.Writer, key string, candidates []edinetcode.Match) (edinetcode.Company, error) {
+	fmt.Fprintf(out, "「%s」に該当する提出者の候補:\n%s", key, formatCandidates(candidates))
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "番号を選択してください (1-%d、空欄で中止): ", len(candidates))
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			break
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(candidates) {
+			return candidates[n-1].Company, nil
+		}
+		fmt.Fprintf(out, "%s は候補の番号ではありません\n", line)
+	}
+	return edinetcode.Company{}, fmt.Errorf("提出者の選択を中止しました")
+}
+
+// isTerminal ファイルが端末かどうか（提出者の候補を対話的に選択できるか）
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
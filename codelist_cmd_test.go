@@ -0,0 +1,220 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"edinet-api-test/internal/config"
+	"edinet-api-test/internal/edinetcode"
+	"edinet-api-test/internal/models"
+)
+
+// writeTestCodeList テスト用のEdinetcode.zipを作成
+func writeTestCodeList(t *testing.T, path string) {
+	t.Helper()
+	csv := "ダウンロード実行日,2025年07月10日現在,件数,1件\n" +
+		"ＥＤＩＮＥＴコード,提出者種別,上場区分,連結の有無,資本金,決算日,提出者名,提出者名（英字）,提出者名（ヨミ）,所在地,提出者業種,証券コード,提出者法人番号\n" +
+		"E01777,内国法人・組合,上場,有,881357,3月31日,ソニーグループ株式会社,Sony Group Corporation,ソニーグループカブシキガイシャ,東京都港区,電気機器,67580,5010401067252\n"
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(csv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("EdinetcodeDlInfo.csv")
+	w.Write([]byte(encoded))
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunCodelistCommand(t *testing.T) {
+	dir := t.TempDir()
+	zipFile := filepath.Join(dir, "Edinetcode.zip")
+	registry := filepath.Join(dir, "edinet_codes.json")
+	writeTestCodeList(t, zipFile)
+
+	var out bytes.Buffer
+	if err := runCodelistCommand(context.Background(), []string{"update", "-registry", registry, "-zip", zipFile}, &out); err != nil {
+		t.Fatalf("update エラー: %v", err)
+	}
+	if !strings.Contains(out.String(), "1社") {
+		t.Errorf("update の出力が不正です: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runCodelistCommand(context.Background(), []string{"lookup", "-registry", registry, "6758", "E99999"}, &out); err != nil {
+		t.Fatalf("lookup エラー: %v", err)
+	}
+	if !strings.Contains(out.String(), "E01777\t67580") || !strings.Contains(out.String(), "E99999: 見つかりません") {
+		t.Errorf("lookup の出力が不正です: %s", out.String())
+	}
+
+	if err := runCodelistCommand(context.Background(), []string{"unknown"}, &out); err == nil {
+		t.Error("不明な操作の場合、エラーが発生すべきです")
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	dir := t.TempDir()
+	zipFile := filepath.Join(dir, "Edinetcode.zip")
+	registry := filepath.Join(dir, "edinet_codes.json")
+	writeTestCodeList(t, zipFile)
+	if err := runCodelistCommand(context.Background(), []string{"update", "-registry", registry, "-zip", zipFile}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("update エラー: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		code       string
+		company    string
+		registry   string
+		wantEdinet string
+		wantSec    string
+	}{
+		{"", "", registry, "", ""},
+		{"6758", "", registry, "E01777", "67580"},
+		{"E01777", "", registry, "E01777", "67580"},
+		{"", "5010401067252", registry, "E01777", "67580"},
+		{"", "ソニーグループ", registry, "E01777", "67580"},
+		{"", "sony group corp.", registry, "E01777", "67580"},
+		// 一覧がない場合はコードの形式から判断する（4桁は末尾に0を付ける）
+		{"7203", "", missing, "", "72030"},
+		{"72030", "", missing, "", "72030"},
+		{"", "e02144", missing, "E02144", ""},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{TargetSecCode: tt.code, Company: tt.company, RegistryFile: tt.registry}
+		if err := resolveTarget(cfg, strings.NewReader(""), &bytes.Buffer{}, false); err != nil {
+			t.Errorf("code=%q company=%q: エラー: %v", tt.code, tt.company, err)
+			continue
+		}
+		if cfg.TargetEdinetCode != tt.wantEdinet || cfg.TargetSecCode != tt.wantSec {
+			t.Errorf("code=%q company=%q: EDINETコード=%q 証券コード=%q, 期待=%q %q", tt.code, tt.company, cfg.TargetEdinetCode, cfg.TargetSecCode, tt.wantEdinet, tt.wantSec)
+		}
+	}
+
+	// 一覧にない証券コードはエラー
+	cfg := &config.Config{TargetSecCode: "7203", RegistryFile: registry}
+	if err := resolveTarget(cfg, strings.NewReader(""), &bytes.Buffer{}, false); err == nil || !strings.Contains(err.Error(), "見つかりません") {
+		t.Errorf("一覧にない証券コードはエラーになるべきです: %v", err)
+	}
+
+	// 類似度だけの候補は1社でも確認する（対話的でない場合はエラー）
+	cfg = &config.Config{Company: "ソニーグルプ", RegistryFile: registry}
+	if err := resolveTarget(cfg, strings.NewReader(""), &bytes.Buffer{}, false); err == nil || !strings.Contains(err.Error(), "1. E01777") {
+		t.Errorf("類似度だけの候補は確認のために示すべきです: %v", err)
+	}
+	cfg = &config.Config{Company: "ソニーグルプ", RegistryFile: registry}
+	if err := resolveTarget(cfg, strings.NewReader("1\n"), &bytes.Buffer{}, true); err != nil || cfg.TargetEdinetCode != "E01777" {
+		t.Errorf("確認した提出者になるべきです: %q, %v", cfg.TargetEdinetCode, err)
+	}
+
+	// 提出者名は一覧がないと検索できない
+	cfg = &config.Config{Company: "ソニー", RegistryFile: missing}
+	if err := resolveTarget(cfg, strings.NewReader(""), &bytes.Buffer{}, false); err == nil {
+		t.Error("一覧がない場合、提出者名の指定はエラーになるべきです")
+	}
+}
+
+func TestResolveTarget_Ambiguous(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "edinet_codes.json")
+	registry := &edinetcode.Registry{Companies: []edinetcode.Company{
+		{EdinetCode: "E01777", SecCode: "67580", Name: "ソニーグループ株式会社", Listed: true},
+		{EdinetCode: "E31234", Name: "ソニー生命保険株式会社"},
+	}}
+	if err := registry.Save(registryPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// 対話的でない場合は候補を示してエラー
+	cfg := &config.Config{Company: "ソニー", RegistryFile: registryPath}
+	err := resolveTarget(cfg, strings.NewReader(""), &bytes.Buffer{}, false)
+	if err == nil || !strings.Contains(err.Error(), "1. E01777") || !strings.Contains(err.Error(), "2. E31234") {
+		t.Errorf("候補を順位付きで示すエラーになるべきです: %v", err)
+	}
+
+	// 対話的な場合は番号で選択する（不正な入力は再入力）
+	var out bytes.Buffer
+	cfg = &config.Config{Company: "ソニー", RegistryFile: registryPath}
+	if err := resolveTarget(cfg, strings.NewReader("9\n2\n"), &out, true); err != nil {
+		t.Fatalf("選択エラー: %v", err)
+	}
+	if cfg.TargetEdinetCode != "E31234" || cfg.TargetSecCode != "" {
+		t.Errorf("選択した提出者になるべきです: %q %q", cfg.TargetEdinetCode, cfg.TargetSecCode)
+	}
+	if !strings.Contains(out.String(), "9 は候補の番号ではありません") {
+		t.Errorf("不正な番号の場合は再入力を求めるべきです: %s", out.String())
+	}
+
+	// 空行で中止
+	cfg = &config.Config{Company: "ソニー", RegistryFile: registryPath}
+	if err := resolveTarget(cfg, strings.NewReader("\n"), &bytes.Buffer{}, true); err == nil {
+		t.Error("選択を中止した場合、エラーが発生すべきです")
+	}
+}
+
+func TestLoadTarget(t *testing.T) {
+	dir := t.TempDir()
+	zipFile := filepath.Join(dir, "Edinetcode.zip")
+	registry := filepath.Join(dir, "edinet_codes.json")
+	writeTestCodeList(t, zipFile)
+	if err := runCodelistCommand(context.Background(), []string{"update", "-registry", registry, "-zip", zipFile}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("update エラー: %v", err)
+	}
+	watchlist := filepath.Join(dir, "coverage.txt")
+	if err := os.WriteFile(watchlist, []byte("6758\n7203\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sony := models.DocInfo{EdinetCode: "E01777", SecCode: "67580"}
+	toyota := models.DocInfo{EdinetCode: "E02144", SecCode: "72030"}
+	nintendo := models.DocInfo{EdinetCode: "E02367", SecCode: "79740"}
+
+	// 1社の場合
+	target, err := loadTarget(&config.Config{TargetEdinetCode: "E01777", TargetSecCode: "67580", RegistryFile: registry})
+	if err != nil {
+		t.Fatalf("エラー: %v", err)
+	}
+	if !target.Matches(sony) || target.Matches(toyota) {
+		t.Error("指定した1社のみが対象であるべきです")
+	}
+
+	// ウォッチリスト（一覧にない提出者は警告を出して除く）
+	target, err = loadTarget(&config.Config{Watchlist: watchlist, RegistryFile: registry})
+	if err != nil {
+		t.Fatalf("エラー: %v", err)
+	}
+	if !target.Matches(sony) || target.Matches(toyota) || target.Matches(nintendo) {
+		t.Error("ウォッチリストのうち一覧にある提出者のみが対象であるべきです")
+	}
+
+	// 一覧がない場合のみ、証券コードの末尾に0を付ける
+	target, err = loadTarget(&config.Config{Watchlist: watchlist, RegistryFile: filepath.Join(dir, "missing.json")})
+	if err != nil {
+		t.Fatalf("エラー: %v", err)
+	}
+	if !target.Matches(sony) || !target.Matches(toyota) || target.Matches(nintendo) {
+		t.Error("ウォッチリストの提出者のみが対象であるべきです")
+	}
+
+	// ウォッチリストと業種の条件
+	target, err = loadTarget(&config.Config{Watchlist: watchlist, Industries: []string{"電気機器"}, RegistryFile: registry})
+	if err != nil {
+		t.Fatalf("エラー: %v", err)
+	}
+	if !target.Matches(sony) || target.Matches(toyota) {
+		t.Error("ウォッチリストのうち業種の条件に一致する提出者のみが対象であるべきです")
+	}
+
+	if _, err := loadTarget(&config.Config{Industries: []string{"電気機器"}, RegistryFile: filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("提出者の一覧がない場合、業種の条件はエラーになるべきです")
+	}
+}
@@ -2,4 +2,7 @@ module edinet-api-test
 
 go 1.24.5
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/text v0.28.0
+)
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"edinet-api-test/internal/models"
+)
+
+// DocumentType 書類取得APIの取得種別（type）
+type DocumentType int
+
+// 書類取得APIの取得種別
+const (
+	DocumentXBRL       DocumentType = 1 // 提出本文書及び監査報告書・XBRL（ZIP）
+	DocumentPDF        DocumentType = 2 // PDF
+	DocumentAttachment DocumentType = 3 // 代替書面・添付文書（ZIP）
+	DocumentEnglish    DocumentType = 4 // 英文ファイル（ZIP）
+	DocumentCSV        DocumentType = 5 // XBRLから変換したCSV（ZIP）
+)
+
+// DocumentTypes 全ての取得種別
+var DocumentTypes = []DocumentType{DocumentXBRL, DocumentPDF, DocumentAttachment, DocumentEnglish, DocumentCSV}
+
+// String 取得種別の名前
+func (t DocumentType) String() string {
+	switch t {
+	case DocumentXBRL:
+		return "XBRL"
+	case DocumentPDF:
+		return "PDF"
+	case DocumentAttachment:
+		return "代替書面・添付文書"
+	case DocumentEnglish:
+		return "英文ファイル"
+	case DocumentCSV:
+		return "CSV"
+	default:
+		return fmt.Sprintf("type=%d", int(t))
+	}
+}
+
+// Ext 保存する際の拡張子
+func (t DocumentType) Ext() string {
+	if t == DocumentPDF {
+		return ".pdf"
+	}
+	return ".zip"
+}
+
+// Available 書類一覧の各フラグ（xbrlFlag・pdfFlag・attachDocFlag・englishDocFlag・csvFlag）から取得できるか判定
+func (t DocumentType) Available(doc models.DocInfo) bool {
+	switch t {
+	case DocumentXBRL:
+		return doc.HasXBRL()
+	case DocumentPDF:
+		return doc.HasPDF()
+	case DocumentAttachment:
+		return doc.HasAttachment()
+	case DocumentEnglish:
+		return doc.HasEnglish()
+	case DocumentCSV:
+		return doc.HasCSV()
+	default:
+		return false
+	}
+}
+
+// AvailableTypes 書類について取得できる取得種別
+func AvailableTypes(doc models.DocInfo) []DocumentType {
+	var types []DocumentType
+	for _, t := range DocumentTypes {
+		if t.Available(doc) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// DownloadDocument 取得種別を指定して書類をダウンロード
+func (e *EdinetAPI) DownloadDocument(ctx context.Context, docID string, docType DocumentType) ([]byte, error) {
+	accept, validate := "application/zip", expectZIP
+	switch docType {
+	case DocumentXBRL, DocumentAttachment, DocumentEnglish, DocumentCSV:
+	case DocumentPDF:
+		accept, validate = "application/pdf", expectPDF
+	default:
+		return nil, fmt.Errorf("取得種別が不正です: %d", int(docType))
+	}
+
+	data, err := e.get(ctx, e.DocumentURL(docID, docType), accept, validate)
+	if err != nil {
+		return nil, fmt.Errorf("%sダウンロード失敗: %w", docType, err)
+	}
+	return data, nil
+}
+
+// expectPDF PDFのレスポンスであることを確認（本文の先頭で判定）
+func expectPDF(contentType string, body []byte) error {
+	if !bytes.HasPrefix(body, []byte("%PDF")) {
+		return fmt.Errorf("PDFではないレスポンスです: Content-Type=%s, %d bytes", contentType, len(body))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"edinet-api-test/internal/models"
+)
+
+func TestAvailableTypes(t *testing.T) {
+	doc := models.DocInfo{XbrlFlag: "1", PdfFlag: "1", AttachDocFlag: "0", EnglishDocFlag: "0", CsvFlag: "1"}
+
+	types := AvailableTypes(doc)
+	expected := []DocumentType{DocumentXBRL, DocumentPDF, DocumentCSV}
+	if len(types) != len(expected) {
+		t.Fatalf("取得種別の数不一致: 期待=%v, 実際=%v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("取得種別[%d]不一致: 期待=%s, 実際=%s", i, expected[i], types[i])
+		}
+	}
+	if DocumentAttachment.Available(doc) || DocumentEnglish.Available(doc) {
+		t.Error("フラグが0の取得種別は取得できないべきです")
+	}
+}
+
+func TestDocumentType_Ext(t *testing.T) {
+	if DocumentPDF.Ext() != ".pdf" || DocumentCSV.Ext() != ".zip" {
+		t.Errorf("拡張子不一致: PDF=%s, CSV=%s", DocumentPDF.Ext(), DocumentCSV.Ext())
+	}
+}
+
+func TestEdinetAPI_DownloadDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("type") {
+		case "2":
+			if r.Header.Get("Accept") != "application/pdf" {
+				t.Errorf("Acceptヘッダー不一致: %s", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7 dummy"))
+		case "5":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("PK\x03\x04dummy"))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>メンテナンス中</html>"))
+		}
+	}))
+	defer server.Close()
+	api := newTestAPI(server)
+
+	pdf, err := api.DownloadDocument(context.Background(), "S100ABCD", DocumentPDF)
+	if err != nil {
+		t.Fatalf("PDFダウンロードエラー: %v", err)
+	}
+	if string(pdf[:4]) != "%PDF" {
+		t.Errorf("PDFの内容が一致しません: %q", pdf)
+	}
+
+	if _, err := api.DownloadDocument(context.Background(), "S100ABCD", DocumentCSV); err != nil {
+		t.Fatalf("CSVダウンロードエラー: %v", err)
+	}
+
+	// ZIPを期待する種別でHTMLが返った場合
+	if _, err := api.DownloadDocument(context.Background(), "S100ABCD", DocumentEnglish); !errors.Is(err, ErrUnexpectedContent) {
+		t.Errorf("ZIPでないレスポンスはErrUnexpectedContentであるべきです: %v", err)
+	}
+
+	if _, err := api.DownloadDocument(context.Background(), "S100ABCD", DocumentType(9)); err == nil {
+		t.Error("不正な取得種別はエラーになるべきです")
+	}
+}
@@ -1,212 +1,296 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"edinet-api-test/internal/doctype"
+	"edinet-api-test/internal/logging"
 	"edinet-api-test/internal/models"
 )
 
-// EdinetAPI EDINET APIクライアント
+// defaultBaseURL EDINET API v2のベースURL
+const defaultBaseURL = "https://api.edinet-fsa.go.jp/api/v2"
+
+// EdinetAPI EDINET APIクライアント（複数のゴルーチンから同時に使える）
 type EdinetAPI struct {
-	client *http.Client
-	apiKey string
+	client    *http.Client
+	apiKey    string
+	baseURL   string
+	userAgent string
+	retry     RetryPolicy
+	limiter   *RateLimiter
+	sleep     func(context.Context, time.Duration) error
+	rndMu     sync.Mutex // rndは複数のゴルーチンから使われるため排他する
+	rnd       *rand.Rand
 }
 
+// 既定のレート制限（EDINETは上限を公開していないため控えめにする）
+const (
+	DefaultRequestsPerSecond = 1.0
+	DefaultBurst             = 1
+)
+
 // NewEdinetAPI 新しいEDINET APIクライアントを作成
-func NewEdinetAPI(apiKey string) *EdinetAPI {
+func NewEdinetAPI(apiKey string, opts ...Option) *EdinetAPI {
+	o := &options{
+		baseURL:   defaultBaseURL,
+		timeout:   DefaultTimeout,
+		userAgent: DefaultUserAgent,
+		retry:     DefaultRetryPolicy(),
+		limiter:   NewRateLimiter(DefaultRequestsPerSecond, DefaultBurst, 0),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return &EdinetAPI{
-		client: &http.Client{},
-		apiKey: apiKey,
+		client:    o.newHTTPClient(),
+		apiKey:    apiKey,
+		baseURL:   o.baseURL,
+		userAgent: o.userAgent,
+		retry:     o.retry,
+		limiter:   o.limiter,
+		sleep:     sleepContext,
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
 // GetDocuments 指定日の文書一覧を取得
-func (e *EdinetAPI) GetDocuments(date time.Time) (*models.DocumentListResponse, error) {
+func (e *EdinetAPI) GetDocuments(ctx context.Context, date time.Time) (*models.DocumentListResponse, error) {
 	dateStr := date.Format("2006-01-02")
-	url := fmt.Sprintf("https://api.edinet-fsa.go.jp/api/v2/documents.json?date=%s&type=2&limit=100", dateStr)
-	
-	fmt.Printf("API URL: %s\n", url)
-	
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト作成エラー: %v", err)
-	}
-	
-	req.Header.Set("Ocp-Apim-Subscription-Key", e.apiKey)
-	req.Header.Set("Accept", "application/json")
-	
-	resp, err := e.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API取得エラー: %v", err)
-	}
-	defer resp.Body.Close()
-	
-	fmt.Printf("API レスポンスステータス: %d\n", resp.StatusCode)
-	
-	body, err := ioutil.ReadAll(resp.Body)
+	url := fmt.Sprintf("%s/documents.json?date=%s&type=2&limit=100", e.baseURL, dateStr)
+
+	body, err := e.get(ctx, url, "application/json", expectJSON)
 	if err != nil {
-		return nil, fmt.Errorf("レスポンス読み込みエラー: %v", err)
-	}
-	
-	fmt.Printf("API レスポンスサイズ: %d bytes\n", len(body))
-	if len(body) < 1000 { // レスポンスが小さい場合は内容を表示
-		fmt.Printf("API レスポンス内容: %s\n", string(body))
+		return nil, err
 	}
-	
+
 	var docList models.DocumentListResponse
 	if err := json.Unmarshal(body, &docList); err != nil {
 		return nil, fmt.Errorf("JSONパースエラー: %v", err)
 	}
-	
-	fmt.Printf("取得された文書数: %d\n", len(docList.Results))
-	
+
+	logging.FromContext(ctx).Debug("文書一覧を取得", logging.KeyDay, dateStr, "url", url, "bytes", len(body),
+		"results", len(docList.Results), "count", docList.Metadata.ResultSet.Count, "processDateTime", docList.Metadata.ProcessDateTime)
 	return &docList, nil
 }
 
-// DownloadXBRLZip XBRLファイルのZIPをダウンロード
-func (e *EdinetAPI) DownloadXBRLZip(docID string) ([]byte, error) {
-	zipURL := fmt.Sprintf("https://api.edinet-fsa.go.jp/api/v2/documents/%s?type=1", docID)
-	
-	req, err := http.NewRequest("GET", zipURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("ZIPリクエスト作成エラー: %v", err)
-	}
-	
-	req.Header.Set("Ocp-Apim-Subscription-Key", e.apiKey)
-	req.Header.Set("Accept", "application/zip")
-	
-	resp, err := e.client.Do(req)
+// GetDocumentsMetadata 指定日の文書一覧のメタデータのみを取得（type=1、件数・更新日時の確認用）
+func (e *EdinetAPI) GetDocumentsMetadata(ctx context.Context, date time.Time) (*models.Metadata, error) {
+	url := fmt.Sprintf("%s/documents.json?date=%s&type=1", e.baseURL, date.Format("2006-01-02"))
+
+	body, err := e.get(ctx, url, "application/json", expectJSON)
 	if err != nil {
-		return nil, fmt.Errorf("ZIPダウンロード失敗: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	
-	zdata, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("ZIPデータ読み込みエラー: %v", err)
+
+	var docList models.DocumentListResponse
+	if err := json.Unmarshal(body, &docList); err != nil {
+		return nil, fmt.Errorf("JSONパースエラー: %v", err)
 	}
-	
-	return zdata, nil
+	return &docList.Metadata, nil
 }
 
-// FilterDocuments 文書をフィルタリング
-func FilterDocuments(docs []models.DocInfo, targetSecCode string, quarterOnly bool) []models.DocInfo {
-	var filtered []models.DocInfo
-	fmt.Printf("フィルタリング開始: 全%d件の文書を処理\n", len(docs))
-	
-	for i, doc := range docs {
-		fmt.Printf("文書[%d]: DocID=%s, DocTypeCode=%s, SecCode=%s, XbrlFlag=%s, FilerName=%s\n", 
-			i, doc.DocID, doc.DocTypeCode, doc.SecCode, doc.XbrlFlag, doc.FilerName)
-		
-		// 証券コードが指定されている場合は証券コードもチェック
-		if targetSecCode != "" {
-			if quarterOnly {
-				// 四半期報告書のみ
-				if doc.DocTypeCode == "130" && 
-				   doc.SecCode == targetSecCode && 
-				   doc.XbrlFlag == "1" {
-					fmt.Printf("  → 四半期報告書として追加\n")
-					filtered = append(filtered, doc)
-				}
-			} else {
-				// 有価証券報告書と四半期報告書
-				if (doc.DocTypeCode == "120" || doc.DocTypeCode == "130") && 
-				   doc.SecCode == targetSecCode && 
-				   doc.XbrlFlag == "1" {
-					fmt.Printf("  → 対象文書として追加\n")
-					filtered = append(filtered, doc)
-				}
+// DocumentURL 書類取得APIのURL（APIキーはヘッダーで送るため含まない）
+func (e *EdinetAPI) DocumentURL(docID string, docType DocumentType) string {
+	return fmt.Sprintf("%s/documents/%s?type=%d", e.baseURL, docID, int(docType))
+}
+
+// DownloadXBRLZip XBRL ZIPファイルをダウンロード
+func (e *EdinetAPI) DownloadXBRLZip(ctx context.Context, docID string) ([]byte, error) {
+	return e.DownloadDocument(ctx, docID, DocumentXBRL)
+}
+
+// get 再試行方針に従ってGETリクエストを送り、検証済みのレスポンス本文を返す
+// ctxが終了した場合は再試行せず、ctxのエラーを返す
+func (e *EdinetAPI) get(ctx context.Context, url, accept string, validate func(contentType string, body []byte) error) ([]byte, error) {
+	retries := make(map[error]int)
+	for {
+		body, err := e.fetch(ctx, url, accept, validate)
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		kind, rule, ok := e.retry.ruleFor(err)
+		if !ok || retries[kind] >= rule.MaxRetries {
+			return nil, err
+		}
+		e.rndMu.Lock()
+		wait := rule.delay(retries[kind], e.rnd)
+		e.rndMu.Unlock()
+		retries[kind]++
+
+		// Retry-Afterが指定されていればそれ以上待ち、他のリクエストも止める
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			if apiErr.RetryAfter > wait {
+				wait = apiErr.RetryAfter
 			}
-		} else {
-			// 証券コードが指定されていない場合
-			if quarterOnly {
-				// 四半期報告書のみ
-				if doc.DocTypeCode == "130" && 
-				   doc.XbrlFlag == "1" {
-					fmt.Printf("  → 四半期報告書として追加\n")
-					filtered = append(filtered, doc)
-				}
-			} else {
-				// 有価証券報告書と四半期報告書
-				if (doc.DocTypeCode == "120" || doc.DocTypeCode == "130") && 
-				   doc.XbrlFlag == "1" {
-					fmt.Printf("  → 対象文書として追加\n")
-					filtered = append(filtered, doc)
-				}
+			if e.limiter != nil {
+				e.limiter.Defer(apiErr.RetryAfter)
 			}
 		}
+		logging.FromContext(ctx).Warn("再試行", "attempt", retries[kind], "max", rule.MaxRetries, "wait", wait.String(), "url", url, "error", err)
+		if err := e.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
-	
-	fmt.Printf("フィルタリング結果: %d件の文書が対象\n", len(filtered))
-	return filtered
-} 
+}
 
-// GetSecCodeMapping 証券コードマッピングを取得
-func (e *EdinetAPI) GetSecCodeMapping() (map[string]string, error) {
-	url := "https://api.edinet-fsa.go.jp/api/v2/companies.json"
-	
-	req, err := http.NewRequest("GET", url, nil)
+// fetch 1回分のGETリクエスト
+func (e *EdinetAPI) fetch(ctx context.Context, url, accept string, validate func(contentType string, body []byte) error) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("リクエスト作成エラー: %v", err)
 	}
 	
 	req.Header.Set("Ocp-Apim-Subscription-Key", e.apiKey)
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
+	if e.userAgent != "" {
+		req.Header.Set("User-Agent", e.userAgent)
+	}
 	
+	if e.limiter != nil {
+		if err := e.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
 	resp, err := e.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("API取得エラー: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 	
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("レスポンス読み込みエラー: %v", err)
+		return nil, fmt.Errorf("%w: レスポンス読み込みエラー: %v", ErrNetwork, err)
 	}
-	
-	var companyList struct {
-		Results []struct {
-			SecCode string `json:"secCode"`
-			JCN     string `json:"jcn"`
-		} `json:"results"`
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK {
+		_, message, _ := parseErrorBody(body)
+		apiErr := newAPIError(resp.StatusCode, message, url)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, apiErr
 	}
-	
-	if err := json.Unmarshal(body, &companyList); err != nil {
-		return nil, fmt.Errorf("JSONパースエラー: %v", err)
+
+	// HTTPステータスが200でも、本文がEDINETのエラー形式の場合がある
+	if isJSON(contentType) {
+		if status, message, ok := parseErrorBody(body); ok {
+			return nil, newAPIError(status, message, url)
+		}
 	}
-	
-	// 4桁→5桁のマッピングを作成
-	mapping := make(map[string]string)
-	for _, company := range companyList.Results {
-		if len(company.SecCode) == 5 && company.SecCode != "" {
-			// 5桁の証券コードから4桁を抽出（末尾の0を除去）
-			fourDigit := strings.TrimSuffix(company.SecCode, "0")
-			if len(fourDigit) == 4 {
-				mapping[fourDigit] = company.SecCode
+
+	if err := validate(contentType, body); err != nil {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: err.Error(), URL: url, kind: ErrUnexpectedContent}
+	}
+	return body, nil
+}
+
+// isJSON Content-TypeがJSONかどうか
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
+
+// expectJSON JSONのレスポンスであることを確認
+func expectJSON(contentType string, body []byte) error {
+	if !isJSON(contentType) {
+		return fmt.Errorf("JSONではないレスポンスです: Content-Type=%s", contentType)
+	}
+	return nil
+}
+
+// expectZIP ZIPのレスポンスであることを確認（Content-Typeは一定しないため本文の先頭で判定）
+func expectZIP(contentType string, body []byte) error {
+	if !bytes.HasPrefix(body, []byte("PK")) {
+		return fmt.Errorf("ZIPではないレスポンスです: Content-Type=%s, %d bytes", contentType, len(body))
+	}
+	return nil
+}
+
+// DocumentMatcher 文書が対象の提出者のものかどうかを判定する（Target・ウォッチリスト等）
+type DocumentMatcher interface {
+	Matches(doc models.DocInfo) bool
+}
+
+// Target 対象の提出者（両方とも空の場合は全企業）
+type Target struct {
+	EdinetCode string // EDINETコード（証券コードの変更・上場廃止の前後でも変わらないため優先する）
+	SecCode    string // 5桁の証券コード（EDINETコードが分からない場合のみ使う）
+}
+
+// Matches 文書が対象の提出者のものかどうか
+func (t Target) Matches(doc models.DocInfo) bool {
+	switch {
+	case t.EdinetCode != "":
+		return doc.EdinetCode == t.EdinetCode
+	case t.SecCode != "":
+		return doc.SecCode == t.SecCode
+	}
+	return true
+}
+
+// FilterDocuments 文書をフィルタリング
+func FilterDocuments(docs []models.DocInfo, target DocumentMatcher, quarterOnly bool) []models.DocInfo {
+	var filtered []models.DocInfo
+	for _, doc := range docs {
+		// 提出者が指定されている場合はEDINETコード（または証券コード）もチェック
+		if target != nil && !target.Matches(doc) {
+			continue
+		}
+		// 取下書・取り下げられた書類はXBRLがなくても突き合わせ（reconcile）のために残す
+		if !doc.HasXBRL() && !doc.IsWithdrawn() {
+			continue
+		}
+
+		// 書類種別の登録情報から対象かどうかを判定（訂正書類も含め、原本との突き合わせは呼び出し側で行う）
+		docType, ok := doctype.Lookup(doc.DocTypeCode)
+		if !ok {
+			continue
+		}
+		switch docType.Category {
+		case doctype.CategoryQuarterly, doctype.CategorySemiAnnual:
+			filtered = append(filtered, doc)
+		case doctype.CategoryAnnual:
+			// 四半期・半期報告書のみの場合は有価証券報告書を除外
+			if !quarterOnly {
+				filtered = append(filtered, doc)
 			}
 		}
 	}
-	
-	return mapping, nil
+	return filtered
 }
 
-// ConvertSecCode 4桁の証券コードを5桁のEDINET証券コードに変換
-func (e *EdinetAPI) ConvertSecCode(fourDigitCode string) (string, error) {
-	mapping, err := e.GetSecCodeMapping()
-	if err != nil {
-		return "", err
-	}
-	
-	if fiveDigitCode, exists := mapping[fourDigitCode]; exists {
-		return fiveDigitCode, nil
+// FilterInterim 中間期の系列のため、書類一覧の期間から第1・第3四半期と判断できる四半期報告書を除く
+//
+// 判断できない書類（期間がない、3か月の期間で決算月が不明等）は残し、ダウンロード後にDEIで確認する。
+// fiscalYearEndMonthは提出者のEDINETコードから決算月を返す（nil、または不明な場合は0）。
+func FilterInterim(docs []models.DocInfo, fiscalYearEndMonth func(edinetCode string) int) []models.DocInfo {
+	var filtered []models.DocInfo
+	for _, doc := range docs {
+		if docType, ok := doctype.Lookup(doc.DocTypeCode); ok && docType.Category == doctype.CategoryQuarterly && !doc.IsWithdrawn() {
+			month := 0
+			if fiscalYearEndMonth != nil {
+				month = fiscalYearEndMonth(doc.EdinetCode)
+			}
+			if q := doc.QuarterFromPeriod(month); q == 1 || q == 3 {
+				continue
+			}
+		}
+		filtered = append(filtered, doc)
 	}
-	
-	// マッピングにない場合は、末尾に0を付けて試す
-	fiveDigitCode := fourDigitCode + "0"
-	return fiveDigitCode, nil
-} 
\ No newline at end of file
+	return filtered
+}
@@ -1,7 +1,13 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"edinet-api-test/internal/models"
 )
@@ -32,14 +38,14 @@ func TestFilterDocuments(t *testing.T) {
 		},
 		{
 			DocID:       "S100EFGH",
-			DocTypeCode: "130",
+			DocTypeCode: "140",
 			SecCode:     "12345",
 			FilerName:   "テスト株式会社",
 			XbrlFlag:    "1",
 		},
 		{
 			DocID:       "S100IJKL",
-			DocTypeCode: "140",
+			DocTypeCode: "030",
 			SecCode:     "12345",
 			FilerName:   "テスト株式会社",
 			XbrlFlag:    "1",
@@ -53,7 +59,7 @@ func TestFilterDocuments(t *testing.T) {
 		},
 	}
 
-	result := FilterDocuments(docs, "12345", false)
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, false)
 	if len(result) != 2 {
 		t.Errorf("期待される結果数: 2, 実際: %d", len(result))
 	}
@@ -74,14 +80,14 @@ func TestFilterDocuments_QuarterOnly(t *testing.T) {
 		},
 		{
 			DocID:       "S100EFGH",
-			DocTypeCode: "130",
+			DocTypeCode: "140",
 			SecCode:     "12345",
 			FilerName:   "テスト株式会社",
 			XbrlFlag:    "1",
 		},
 	}
 
-	result := FilterDocuments(docs, "12345", true)
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, true)
 	if len(result) != 1 {
 		t.Errorf("期待される結果数: 1, 実際: %d", len(result))
 	}
@@ -91,18 +97,49 @@ func TestFilterDocuments_QuarterOnly(t *testing.T) {
 	}
 }
 
+func TestFilterInterim(t *testing.T) {
+	docs := []models.DocInfo{
+		{DocID: "S100Q1AA", EdinetCode: "E00001", DocTypeCode: "140", PeriodStart: "2024-04-01", PeriodEnd: "2024-06-30"},
+		{DocID: "S100Q2AA", EdinetCode: "E00001", DocTypeCode: "140", PeriodStart: "2024-07-01", PeriodEnd: "2024-09-30"},
+		{DocID: "S100Q3AA", EdinetCode: "E00001", DocTypeCode: "140", PeriodStart: "2024-04-01", PeriodEnd: "2024-12-31"},
+		{DocID: "S100HYAA", EdinetCode: "E00001", DocTypeCode: "160", PeriodStart: "2024-04-01", PeriodEnd: "2024-09-30"},
+		// 決算月が不明な提出者の3か月の期間は判断できないため残す
+		{DocID: "S100UNKN", EdinetCode: "E00002", DocTypeCode: "140", PeriodStart: "2024-04-01", PeriodEnd: "2024-06-30"},
+	}
+	months := func(edinetCode string) int {
+		if edinetCode == "E00001" {
+			return 3
+		}
+		return 0
+	}
+
+	result := FilterInterim(docs, months)
+	var ids []string
+	for _, doc := range result {
+		ids = append(ids, doc.DocID)
+	}
+	if strings.Join(ids, ",") != "S100Q2AA,S100HYAA,S100UNKN" {
+		t.Errorf("第1・第3四半期報告書のみを除くべきです: %v", ids)
+	}
+
+	// 決算月を調べられない場合は累計期間から判断できる書類のみを除く
+	if result := FilterInterim(docs, nil); len(result) != 4 {
+		t.Errorf("期首からの累計期間の第3四半期報告書のみを除くべきです: %d件", len(result))
+	}
+}
+
 func TestFilterDocuments_EmptyResult(t *testing.T) {
 	docs := []models.DocInfo{
 		{
 			DocID:       "S100ABCD",
-			DocTypeCode: "140",
+			DocTypeCode: "030",
 			SecCode:     "12345",
 			FilerName:   "テスト株式会社",
 			XbrlFlag:    "1",
 		},
 	}
 
-	result := FilterDocuments(docs, "12345", false)
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, false)
 	if len(result) != 0 {
 		t.Errorf("期待される結果数: 0, 実際: %d", len(result))
 	}
@@ -111,7 +148,7 @@ func TestFilterDocuments_EmptyResult(t *testing.T) {
 func TestFilterDocuments_EmptyInput(t *testing.T) {
 	docs := []models.DocInfo{}
 
-	result := FilterDocuments(docs, "12345", false)
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, false)
 	if len(result) != 0 {
 		t.Errorf("期待される結果数: 0, 実際: %d", len(result))
 	}
@@ -141,4 +178,288 @@ func TestDownloadXBRLZipFunction(t *testing.T) {
 		t.Error("APIクライアントが作成できません")
 	}
 	t.Log("DownloadXBRLZip関数の存在確認")
-} 
\ No newline at end of file
+} 
+
+func TestFilterDocuments_IncludesAmendments(t *testing.T) {
+	docs := []models.DocInfo{
+		{DocID: "S100AAAA", DocTypeCode: "130", SecCode: "12345", XbrlFlag: "1", ParentDocID: "S100ZZZZ"},
+		{DocID: "S100BBBB", DocTypeCode: "150", SecCode: "12345", XbrlFlag: "1", ParentDocID: "S100YYYY"},
+		{DocID: "S100CCCC", DocTypeCode: "140", SecCode: "12345", XbrlFlag: "0"},
+	}
+
+	// 訂正報告書は原本との突き合わせのために残し、XBRLのない書類は対象外
+	if result := FilterDocuments(docs, Target{SecCode: "12345"}, false); len(result) != 2 {
+		t.Errorf("期待される結果数: 2, 実際: %d", len(result))
+	}
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, true)
+	if len(result) != 1 || result[0].DocID != "S100BBBB" {
+		t.Errorf("四半期報告書のみの場合は訂正四半期報告書のみが対象であるべきです: %+v", result)
+	}
+}
+
+func TestFilterDocuments_SemiAnnual(t *testing.T) {
+	docs := []models.DocInfo{
+		{DocID: "S100AAAA", DocTypeCode: "120", SecCode: "12345", XbrlFlag: "1"},
+		{DocID: "S100BBBB", DocTypeCode: "140", SecCode: "12345", XbrlFlag: "1"},
+		{DocID: "S100CCCC", DocTypeCode: "160", SecCode: "12345", XbrlFlag: "1"},
+		{DocID: "S100DDDD", DocTypeCode: "170", SecCode: "12345", XbrlFlag: "1"},
+	}
+
+	if result := FilterDocuments(docs, Target{SecCode: "12345"}, false); len(result) != 4 {
+		t.Errorf("期待される結果数: 4, 実際: %d", len(result))
+	}
+
+	// 四半期報告書のみの指定では、四半期報告書と半期報告書（訂正を含む）が対象
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, true)
+	if len(result) != 3 || result[0].DocID != "S100BBBB" || result[1].DocID != "S100CCCC" {
+		t.Errorf("四半期報告書と半期報告書がフィルタリングされるべきです: %+v", result)
+	}
+}
+
+func TestFilterDocuments_KeepsWithdrawn(t *testing.T) {
+	docs := []models.DocInfo{
+		{DocID: "S100AAAA", DocTypeCode: "120", SecCode: "12345", XbrlFlag: "0", WithdrawalStatus: models.WithdrawalWithdrawn},
+		{DocID: "S100BBBB", DocTypeCode: "120", SecCode: "12345", XbrlFlag: "0", WithdrawalStatus: models.WithdrawalNotice, ParentDocID: "S100AAAA"},
+		{DocID: "S100CCCC", DocTypeCode: "120", SecCode: "12345", XbrlFlag: "0", WithdrawalStatus: models.WithdrawalNone},
+	}
+
+	// 取下げに関する書類はXBRLがなくても突き合わせのために残す
+	result := FilterDocuments(docs, Target{SecCode: "12345"}, false)
+	if len(result) != 2 || result[0].DocID != "S100AAAA" || result[1].DocID != "S100BBBB" {
+		t.Errorf("取下げに関する書類が残されるべきです: %+v", result)
+	}
+}
+
+// newTestAPI テスト用サーバーに接続し、待ち時間なしで再試行するクライアントを作成
+func newTestAPI(server *httptest.Server, opts ...Option) *EdinetAPI {
+	opts = append([]Option{WithBaseURL(server.URL), WithRateLimiter(nil)}, opts...)
+	api := NewEdinetAPI("test-key", opts...)
+	api.sleep = func(context.Context, time.Duration) error { return nil }
+	return api
+}
+
+func TestEdinetAPI_DownloadXBRLZip_ErrorBody(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"APIキー無効", http.StatusUnauthorized, `{"statusCode": 401, "message": "Access denied due to invalid subscription key."}`, ErrUnauthorized},
+		{"書類なし", http.StatusNotFound, `{"statusCode": 404, "message": "Not Found"}`, ErrNotFound},
+		// HTTPステータスが200でも本文がエラーの場合がある
+		{"本文のみエラー", http.StatusOK, `{"statusCode": 404, "message": "Not Found"}`, ErrNotFound},
+	}
+
+	for _, tc := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+
+		_, err := newTestAPI(server).DownloadXBRLZip(context.Background(), "S100ABCD")
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("%s: %v として判定されるべきです: %v", tc.name, tc.kind, err)
+		}
+		server.Close()
+	}
+}
+
+func TestEdinetAPI_DownloadXBRLZip_UnexpectedContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>メンテナンス中</html>"))
+	}))
+	defer server.Close()
+
+	_, err := newTestAPI(server).DownloadXBRLZip(context.Background(), "S100ABCD")
+	if !errors.Is(err, ErrUnexpectedContent) {
+		t.Errorf("ZIPでないレスポンスはErrUnexpectedContentであるべきです: %v", err)
+	}
+}
+
+func TestEdinetAPI_DownloadXBRLZip_RetryServerError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test-key" {
+			t.Error("APIキーがヘッダーに設定されていません")
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("PK\x03\x04dummy"))
+	}))
+	defer server.Close()
+
+	data, err := newTestAPI(server).DownloadXBRLZip(context.Background(), "S100ABCD")
+	if err != nil {
+		t.Fatalf("再試行後に成功するべきです: %v", err)
+	}
+	if calls != 3 || string(data[:2]) != "PK" {
+		t.Errorf("呼び出し回数またはデータが不正です: calls=%d", calls)
+	}
+}
+
+func TestEdinetAPI_GetDocuments_RetryLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	api := newTestAPI(server, WithRetryPolicy(RetryPolicy{RateLimited: RetryRule{MaxRetries: 2, BaseDelay: time.Millisecond}}))
+
+	_, err := api.GetDocuments(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("ErrRateLimitedであるべきです: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("呼び出し回数不一致: 期待=3（初回+再試行2回）, 実際=%d", calls)
+	}
+}
+
+func TestEdinetAPI_GetDocuments_NoRetryOnUnauthorized(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"statusCode": 401, "message": "Access denied"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestAPI(server).GetDocuments(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ErrUnauthorizedであるべきです: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("認証エラーは再試行しないべきです: 呼び出し回数=%d", calls)
+	}
+}
+
+func TestEdinetAPI_GetDocuments_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/documents.json" || r.URL.Query().Get("date") != "2025-06-25" || r.URL.Query().Get("type") != "2" {
+			t.Errorf("リクエストが不正です: %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"metadata": {"status": "200", "message": "OK", "resultset": {"count": 1}}, "results": [{"docID": "S100ABCD", "docTypeCode": "120"}]}`))
+	}))
+	defer server.Close()
+
+	docList, err := newTestAPI(server).GetDocuments(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("文書一覧取得エラー: %v", err)
+	}
+	if len(docList.Results) != 1 || docList.Results[0].DocID != "S100ABCD" {
+		t.Errorf("文書一覧が不正です: %+v", docList.Results)
+	}
+}
+
+func TestEdinetAPI_RetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "45")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"metadata": {"status": "200"}, "results": []}`))
+	}))
+	defer server.Close()
+
+	limiter, clock := newTestLimiter(1000, 1, 0, time.Now())
+	api := newTestAPI(server, WithRateLimiter(limiter))
+	var waits []time.Duration
+	api.sleep = func(ctx context.Context, d time.Duration) error { waits = append(waits, d); return nil }
+
+	if _, err := api.GetDocuments(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("再試行後に成功するべきです: %v", err)
+	}
+	if len(waits) != 1 || waits[0] < 45*time.Second {
+		t.Errorf("Retry-Afterの時間以上待つべきです: %v", waits)
+	}
+	if clock.slept < 44*time.Second {
+		t.Errorf("レート制限器もRetry-Afterの間は止めるべきです: %v", clock.slept)
+	}
+	if limiter.Used() != 2 {
+		t.Errorf("リクエスト数不一致: 期待=2, 実際=%d", limiter.Used())
+	}
+}
+
+func TestEdinetAPI_Canceled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// 再試行の待ち時間中に中断された場合は、それ以上再試行しない
+	ctx, cancel := context.WithCancel(context.Background())
+	api := newTestAPI(server)
+	api.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	_, err := api.DownloadXBRLZip(ctx, "S100ABCD")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("context.Canceledであるべきです: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("呼び出し回数不一致: 期待=1, 実際=%d", calls)
+	}
+
+	// 開始前に中断されている場合はリクエストを送らない
+	if _, err := api.GetDocuments(ctx, time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC)); !errors.Is(err, context.Canceled) {
+		t.Errorf("context.Canceledであるべきです: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("中断後にリクエストが送られています: %d", calls)
+	}
+}
+
+func TestEdinetAPI_GetDocumentsMetadata(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"metadata": {"status": "200", "message": "OK", "resultset": {"count": 42}, "processDateTime": "2025-06-25 18:00"}}`))
+	}))
+	defer server.Close()
+
+	metadata, err := newTestAPI(server).GetDocumentsMetadata(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("メタデータ取得エラー: %v", err)
+	}
+	if query != "date=2025-06-25&type=1" {
+		t.Errorf("クエリ不一致: %s", query)
+	}
+	if metadata.ResultSet.Count != 42 || metadata.ProcessDateTime != "2025-06-25 18:00" {
+		t.Errorf("メタデータ不一致: %+v", metadata)
+	}
+}
+
+func TestFilterDocuments_ByEdinetCode(t *testing.T) {
+	docs := []models.DocInfo{
+		{DocID: "S100AAAA", DocTypeCode: "120", EdinetCode: "E01777", SecCode: "67580", XbrlFlag: "1"},
+		// 証券コードが変わった後・上場廃止後の書類もEDINETコードで対象にする
+		{DocID: "S100BBBB", DocTypeCode: "140", EdinetCode: "E01777", SecCode: "", XbrlFlag: "1"},
+		{DocID: "S100CCCC", DocTypeCode: "120", EdinetCode: "E99999", SecCode: "67580", XbrlFlag: "1"},
+	}
+
+	result := FilterDocuments(docs, Target{EdinetCode: "E01777", SecCode: "67580"}, false)
+	if len(result) != 2 || result[0].DocID != "S100AAAA" || result[1].DocID != "S100BBBB" {
+		t.Errorf("EDINETコードでフィルタリングされるべきです: %+v", result)
+	}
+	if result := FilterDocuments(docs, Target{}, false); len(result) != 3 {
+		t.Errorf("提出者の指定がない場合は全件が対象であるべきです: %d件", len(result))
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// エラーの種類（errors.Isで判定する）
+var (
+	// ErrBadRequest リクエストの誤り（日付・パラメータの指定誤り等）
+	ErrBadRequest = errors.New("リクエストが不正です")
+	// ErrUnauthorized APIキーが無効
+	ErrUnauthorized = errors.New("APIキーが無効です")
+	// ErrNotFound 書類が存在しない
+	ErrNotFound = errors.New("書類が見つかりません")
+	// ErrRateLimited リクエストの制限を超えた
+	ErrRateLimited = errors.New("リクエストが制限されています")
+	// ErrServerUnavailable EDINET側の障害・メンテナンス
+	ErrServerUnavailable = errors.New("EDINETのサーバーが利用できません")
+	// ErrNetwork 通信エラー
+	ErrNetwork = errors.New("通信エラー")
+	// ErrUnexpectedContent 期待した形式ではないレスポンス
+	ErrUnexpectedContent = errors.New("レスポンスの形式が不正です")
+)
+
+// APIError EDINET APIのエラー
+type APIError struct {
+	StatusCode int    // HTTPステータス、またはエラー本文のstatusCode
+	Message    string // エラー本文のメッセージ
+	URL        string
+	RetryAfter time.Duration // Retry-Afterヘッダーの待ち時間（指定がない場合は0）
+	kind       error
+}
+
+// Error エラーメッセージ
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%v (status=%d, url=%s)", e.kind, e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("%v (status=%d, message=%s, url=%s)", e.kind, e.StatusCode, e.Message, e.URL)
+}
+
+// Unwrap エラーの種類を返す
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// newAPIError ステータスコードからエラーの種類を決めてAPIErrorを作成
+func newAPIError(status int, message, url string) *APIError {
+	return &APIError{StatusCode: status, Message: message, URL: url, kind: kindOf(status)}
+}
+
+// kindOf ステータスコードに対応するエラーの種類
+func kindOf(status int) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrServerUnavailable
+	case status >= 400:
+		return ErrBadRequest
+	default:
+		return ErrUnexpectedContent
+	}
+}
+
+// parseRetryAfter Retry-Afterヘッダー（秒数またはHTTP日付）を待ち時間に変換
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// errorBody EDINETのエラー本文
+//
+// 書類取得APIは{"statusCode":…,"message":…}、書類一覧APIは{"metadata":{"status":…,"message":…}}の
+// 形式で返す。statusCodeは数値の場合と文字列の場合がある。
+type errorBody struct {
+	StatusCode json.RawMessage `json:"statusCode"`
+	Message    string          `json:"message"`
+	Metadata   *struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"metadata"`
+}
+
+// parseErrorBody エラー本文からステータスとメッセージを取り出す（エラー本文でない場合はfalse）
+func parseErrorBody(body []byte) (int, string, bool) {
+	var eb errorBody
+	if err := json.Unmarshal(body, &eb); err != nil {
+		return 0, "", false
+	}
+	if len(eb.StatusCode) > 0 {
+		raw := string(eb.StatusCode)
+		if unquoted, err := strconv.Unquote(raw); err == nil {
+			raw = unquoted
+		}
+		if status, err := strconv.Atoi(raw); err == nil && status != http.StatusOK {
+			return status, eb.Message, true
+		}
+	}
+	if eb.Metadata != nil && eb.Metadata.Status != "" {
+		if status, err := strconv.Atoi(eb.Metadata.Status); err == nil && status != http.StatusOK {
+			return status, eb.Metadata.Message, true
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	testCases := []struct {
+		body    string
+		status  int
+		message string
+		ok      bool
+	}{
+		{`{"statusCode": 401, "message": "Access denied due to invalid subscription key."}`, 401, "Access denied due to invalid subscription key.", true},
+		{`{"statusCode": "404", "message": "Not Found"}`, 404, "Not Found", true},
+		{`{"metadata": {"status": "400", "message": "Bad Request"}}`, 400, "Bad Request", true},
+		{`{"metadata": {"status": "200", "message": "OK"}, "results": []}`, 0, "", false},
+		{`PK...`, 0, "", false},
+	}
+
+	for _, tc := range testCases {
+		status, message, ok := parseErrorBody([]byte(tc.body))
+		if status != tc.status || message != tc.message || ok != tc.ok {
+			t.Errorf("%s: 期待=(%d, %s, %t), 実際=(%d, %s, %t)", tc.body, tc.status, tc.message, tc.ok, status, message, ok)
+		}
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	testCases := []struct {
+		status int
+		kind   error
+	}{
+		{400, ErrBadRequest},
+		{401, ErrUnauthorized},
+		{403, ErrUnauthorized},
+		{404, ErrNotFound},
+		{429, ErrRateLimited},
+		{500, ErrServerUnavailable},
+		{503, ErrServerUnavailable},
+	}
+
+	for _, tc := range testCases {
+		err := error(newAPIError(tc.status, "", "https://example.com"))
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("status=%d: %v として判定されるべきです", tc.status, tc.kind)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.status {
+			t.Errorf("status=%d: APIErrorとして取り出せません", tc.status)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 25, 1, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"Wed, 25 Jun 2025 01:00:30 GMT", 30 * time.Second},
+		{"Wed, 25 Jun 2025 00:59:00 GMT", 0},
+		{"invalid", 0},
+	}
+
+	for _, tc := range testCases {
+		if result := parseRetryAfter(tc.value, now); result != tc.expected {
+			t.Errorf("%q: 期待=%v, 実際=%v", tc.value, tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 既定のHTTP設定
+const (
+	DefaultTimeout   = 2 * time.Minute
+	DefaultUserAgent = "edinet-api-test"
+)
+
+// Option EdinetAPIクライアントの設定
+type Option func(*options)
+
+// options NewEdinetAPIに渡された設定
+type options struct {
+	baseURL   string
+	transport http.RoundTripper
+	timeout   time.Duration
+	userAgent string
+	proxy     *url.URL
+	retry     RetryPolicy
+	limiter   *RateLimiter
+}
+
+// WithBaseURL APIのベースURLを変更（ミラーや結合テスト用のサーバーを使う場合）
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithTransport HTTPのトランスポートを差し替える（記録・再生のフィクスチャ等）
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout 1回のリクエストのタイムアウト（0の場合はタイムアウトなし）
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent User-Agentヘッダーを変更
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithProxy プロキシを指定（未指定の場合は環境変数HTTPS_PROXY等に従う）
+// WithTransportで*http.Transport以外を指定した場合は使われない
+func WithProxy(proxy *url.URL) Option {
+	return func(o *options) {
+		o.proxy = proxy
+	}
+}
+
+// WithRetryPolicy 再試行方針を変更
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithRateLimiter レート制限器を変更（nilの場合は制限しない）
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+// newHTTPClient 設定からHTTPクライアントを作成
+func (o *options) newHTTPClient() *http.Client {
+	transport := o.transport
+	if o.proxy != nil {
+		base, ok := transport.(*http.Transport)
+		if transport == nil {
+			base, ok = http.DefaultTransport.(*http.Transport)
+		}
+		if ok {
+			t := base.Clone()
+			t.Proxy = http.ProxyURL(o.proxy)
+			transport = t
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: o.timeout}
+}
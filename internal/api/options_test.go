@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc 関数をhttp.RoundTripperとして使う
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewEdinetAPI_Defaults(t *testing.T) {
+	api := NewEdinetAPI("test-key")
+
+	if api.baseURL != defaultBaseURL {
+		t.Errorf("baseURL不一致: 期待=%s, 実際=%s", defaultBaseURL, api.baseURL)
+	}
+	if api.client.Timeout != DefaultTimeout {
+		t.Errorf("Timeout不一致: 期待=%v, 実際=%v", DefaultTimeout, api.client.Timeout)
+	}
+	if api.userAgent != DefaultUserAgent {
+		t.Errorf("userAgent不一致: 期待=%s, 実際=%s", DefaultUserAgent, api.userAgent)
+	}
+	if api.limiter == nil {
+		t.Error("既定ではレート制限器が設定されるべきです")
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var path, userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"metadata": {"status": "200"}, "results": []}`))
+	}))
+	defer server.Close()
+
+	// 末尾のスラッシュは取り除く
+	api := NewEdinetAPI("test-key", WithBaseURL(server.URL+"/mirror/v2/"), WithRateLimiter(nil), WithUserAgent("test-agent/1.0"))
+	if _, err := api.GetDocuments(context.Background(), time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("文書一覧取得エラー: %v", err)
+	}
+	if path != "/mirror/v2/documents.json" {
+		t.Errorf("パス不一致: 期待=/mirror/v2/documents.json, 実際=%s", path)
+	}
+	if userAgent != "test-agent/1.0" {
+		t.Errorf("User-Agent不一致: 期待=test-agent/1.0, 実際=%s", userAgent)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var requested string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+			Body:       io.NopCloser(strings.NewReader("PK\x03\x04")),
+			Request:    req,
+		}, nil
+	})
+
+	api := NewEdinetAPI("test-key", WithTransport(transport), WithRateLimiter(nil), WithTimeout(0))
+	if _, err := api.DownloadXBRLZip(context.Background(), "S100ABCD"); err != nil {
+		t.Fatalf("ダウンロードエラー: %v", err)
+	}
+	if requested != defaultBaseURL+"/documents/S100ABCD?type=1" {
+		t.Errorf("URL不一致: %s", requested)
+	}
+	if api.client.Timeout != 0 {
+		t.Errorf("Timeoutが0になっていません: %v", api.client.Timeout)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:8080")
+	api := NewEdinetAPI("test-key", WithProxy(proxyURL))
+
+	transport, ok := api.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("*http.Transportであるべきです: %T", api.client.Transport)
+	}
+	req, _ := http.NewRequest("GET", defaultBaseURL+"/documents.json", nil)
+	got, err := transport.Proxy(req)
+	if err != nil || got.String() != proxyURL.String() {
+		t.Errorf("プロキシ不一致: 期待=%s, 実際=%v (%v)", proxyURL, got, err)
+	}
+
+	// 既定のトランスポートは変更しない
+	if http.DefaultTransport.(*http.Transport).Proxy != nil {
+		if p, _ := http.DefaultTransport.(*http.Transport).Proxy(req); p != nil && p.String() == proxyURL.String() {
+			t.Error("http.DefaultTransportが変更されています")
+		}
+	}
+}
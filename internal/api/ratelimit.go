@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"edinet-api-test/internal/logging"
+)
+
+// jst 日次の上限を数える基準のタイムゾーン（EDINETの運用日）
+var jst = time.FixedZone("JST", 9*60*60)
+
+// RateLimiter リクエストの間隔と1日あたりの回数を制限する
+//
+// トークンバケット（毎秒ratePerSecond個補充、最大burst個）で間隔を空け、
+// dailyBudgetを使い切った場合は翌日（日本時間）まで待つ。Retry-Afterを受けた場合は
+// Deferで指定された時刻まで全てのリクエストを止める。
+type RateLimiter struct {
+	mu           sync.Mutex
+	interval     time.Duration
+	burst        int
+	tat          time.Time // 次のトークンが補充される理論上の時刻
+	dailyBudget  int
+	day          time.Time // 日次の上限を数えている日（日本時間の0時）
+	used         int
+	blockedUntil time.Time
+	now          func() time.Time
+	sleep        func(context.Context, time.Duration) error
+}
+
+// NewRateLimiter 新しいレート制限器を作成（dailyBudgetが0の場合は日次の上限なし）
+func NewRateLimiter(ratePerSecond float64, burst int, dailyBudget int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval:    time.Duration(float64(time.Second) / ratePerSecond),
+		burst:       burst,
+		dailyBudget: dailyBudget,
+		now:         time.Now,
+		sleep:       sleepContext,
+	}
+}
+
+// Wait 次のリクエストを送ってよい時刻まで待つ（ctxが終了した場合はそのエラーを返す）
+//
+// 日次の上限は送信を予定した時刻の日付で数える。上限に達して翌日に予定したリクエストがある場合、
+// 同時に呼び出した他のリクエストも当日ではなく翌日以降の分として数える（数える日は戻さない）。
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	start := now
+	if l.day.After(start) {
+		start = l.day
+	}
+
+	for {
+		// Retry-Afterによる停止
+		if l.blockedUntil.After(start) {
+			start = l.blockedUntil
+		}
+
+		// トークンバケット
+		tat := l.tat
+		if tat.Before(start) {
+			tat = start
+		}
+		if allowAt := tat.Add(-time.Duration(l.burst-1) * l.interval); allowAt.After(start) {
+			start = allowAt
+		}
+
+		// 日次の上限（送信する日の日本時間の日付が変わったら数え直す）
+		if day := jstDay(start); day.After(l.day) {
+			l.day, l.used = day, 0
+		}
+		if l.dailyBudget == 0 || l.used < l.dailyBudget {
+			break
+		}
+		start = l.day.AddDate(0, 0, 1)
+		logging.FromContext(ctx).Warn("1日あたりのリクエスト上限に達したため待機します", "dailyLimit", l.dailyBudget, "until", start.Format("2006-01-02 15:04 MST"))
+	}
+
+	tat := l.tat
+	if tat.Before(start) {
+		tat = start
+	}
+	l.tat = tat.Add(l.interval)
+	l.used++
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		return l.sleep(ctx, wait)
+	}
+	return ctx.Err()
+}
+
+// jstDay 日本時間の日付の0時
+func jstDay(t time.Time) time.Time {
+	y, m, d := t.In(jst).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, jst)
+}
+
+// sleepContext 指定時間待つ（ctxが終了した場合はすぐに戻る）
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Defer 指定時間が経過するまで以降のリクエストを止める（Retry-After用）
+func (l *RateLimiter) Defer(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// Used 当日（日本時間）に送ったリクエスト数
+func (l *RateLimiter) Used() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !jstDay(l.now()).Equal(l.day) {
+		return 0
+	}
+	return l.used
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock テスト用の時計（sleepで時刻が進む）
+type fakeClock struct {
+	t     time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	c.t = c.t.Add(d)
+	c.slept += d
+	return ctx.Err()
+}
+
+func newTestLimiter(rate float64, burst, daily int, start time.Time) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: start}
+	l := NewRateLimiter(rate, burst, daily)
+	l.now = clock.now
+	l.sleep = clock.sleep
+	return l, clock
+}
+
+func TestRateLimiter_Interval(t *testing.T) {
+	start := time.Date(2025, 6, 25, 10, 0, 0, 0, jst)
+	l, clock := newTestLimiter(2, 1, 0, start)
+
+	for i := 0; i < 5; i++ {
+		l.Wait(context.Background())
+	}
+	// 毎秒2回なので、5回目は2秒後
+	if elapsed := clock.t.Sub(start); elapsed != 2*time.Second {
+		t.Errorf("経過時間不一致: 期待=2s, 実際=%v", elapsed)
+	}
+}
+
+func TestRateLimiter_Burst(t *testing.T) {
+	start := time.Date(2025, 6, 25, 10, 0, 0, 0, jst)
+	l, clock := newTestLimiter(1, 3, 0, start)
+
+	for i := 0; i < 3; i++ {
+		l.Wait(context.Background())
+	}
+	if clock.slept != 0 {
+		t.Errorf("バースト内は待たないべきです: %v", clock.slept)
+	}
+	l.Wait(context.Background())
+	if clock.slept != time.Second {
+		t.Errorf("バーストを超えたら間隔を空けるべきです: %v", clock.slept)
+	}
+}
+
+func TestRateLimiter_DailyBudget(t *testing.T) {
+	start := time.Date(2025, 6, 25, 23, 0, 0, 0, jst)
+	l, clock := newTestLimiter(1000, 1, 2, start)
+
+	l.Wait(context.Background())
+	l.Wait(context.Background())
+	if l.Used() != 2 {
+		t.Errorf("使用回数不一致: 期待=2, 実際=%d", l.Used())
+	}
+
+	// 上限に達したら翌日0時（日本時間）まで待つ
+	l.Wait(context.Background())
+	expected := time.Date(2025, 6, 26, 0, 0, 0, 0, jst)
+	if !clock.t.Equal(expected) {
+		t.Errorf("待機後の時刻不一致: 期待=%v, 実際=%v", expected, clock.t)
+	}
+	if l.Used() != 1 {
+		t.Errorf("翌日の使用回数不一致: 期待=1, 実際=%d", l.Used())
+	}
+}
+
+func TestRateLimiter_DailyBudgetConcurrent(t *testing.T) {
+	now := time.Date(2025, 6, 25, 23, 0, 0, 0, jst)
+	l := NewRateLimiter(1000, 1, 2)
+	l.now = func() time.Time { return now }
+
+	// 各リクエストを送る予定の時刻を記録する（時計は進めない）
+	var mu sync.Mutex
+	var scheduled []time.Time
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		scheduled = append(scheduled, now.Add(d))
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Wait(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	// 待たずに送った2回を含め、どの日も上限の2回以内
+	perDay := map[string]int{now.Format("2006-01-02"): 10 - len(scheduled)}
+	for _, at := range scheduled {
+		perDay[at.In(jst).Format("2006-01-02")]++
+	}
+	if len(perDay) != 5 {
+		t.Errorf("10回は5日に分けて送るべきです: %v", perDay)
+	}
+	for day, n := range perDay {
+		if n > 2 {
+			t.Errorf("%s の使用回数が上限を超えています: %d", day, n)
+		}
+	}
+}
+
+func TestRateLimiter_Defer(t *testing.T) {
+	start := time.Date(2025, 6, 25, 10, 0, 0, 0, jst)
+	l, clock := newTestLimiter(1000, 1, 0, start)
+
+	l.Defer(30 * time.Second)
+	l.Wait(context.Background())
+	if elapsed := clock.t.Sub(start); elapsed != 30*time.Second {
+		t.Errorf("Retry-Afterの時間だけ待つべきです: %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l := NewRateLimiter(0.001, 1, 0)
+	l.Wait(context.Background())
+
+	// 次のトークンまで長く待つ場合でも、キャンセルされたらすぐに戻る
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("context.Canceledが返されるべきです: %v", err)
+	}
+}
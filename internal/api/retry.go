@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryRule 再試行の回数と待ち時間
+type RetryRule struct {
+	MaxRetries int           // 再試行の最大回数（0で再試行しない）
+	BaseDelay  time.Duration // 1回目の再試行までの待ち時間
+	MaxDelay   time.Duration // 待ち時間の上限
+}
+
+// RetryPolicy エラーの種類ごとの再試行方針
+type RetryPolicy struct {
+	RateLimited RetryRule // 429
+	Server      RetryRule // 5xx
+	Network     RetryRule // 通信エラー・タイムアウト
+}
+
+// DefaultRetryPolicy 既定の再試行方針
+// 認証エラー・書類なし等の4xxは再試行しても結果が変わらないため再試行しない
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		RateLimited: RetryRule{MaxRetries: 5, BaseDelay: 2 * time.Second, MaxDelay: time.Minute},
+		Server:      RetryRule{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second},
+		Network:     RetryRule{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second},
+	}
+}
+
+// ruleFor エラーの種類と対応する再試行ルール（再試行しないエラーはfalse）
+func (p RetryPolicy) ruleFor(err error) (error, RetryRule, bool) {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return ErrRateLimited, p.RateLimited, true
+	case errors.Is(err, ErrServerUnavailable):
+		return ErrServerUnavailable, p.Server, true
+	case errors.Is(err, ErrNetwork):
+		return ErrNetwork, p.Network, true
+	default:
+		return nil, RetryRule{}, false
+	}
+}
+
+// delay attempt回目（0始まり）の再試行までの待ち時間
+// 指数バックオフの値を上限で切り、その半分から全体までの範囲でジッターを加える
+func (r RetryRule) delay(attempt int, rnd *rand.Rand) time.Duration {
+	d := r.BaseDelay
+	for i := 0; i < attempt && (r.MaxDelay <= 0 || d < r.MaxDelay); i++ {
+		d *= 2
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rnd.Int63n(int64(d-half)+1))
+}
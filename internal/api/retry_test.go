@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRetryRule_Delay(t *testing.T) {
+	rule := RetryRule{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	rnd := rand.New(rand.NewSource(1))
+
+	testCases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second}, // 上限で切る
+		{10, 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		for i := 0; i < 20; i++ {
+			d := rule.delay(tc.attempt, rnd)
+			if d < tc.max/2 || d > tc.max {
+				t.Errorf("attempt=%d: 待ち時間が範囲外です: %v（%v〜%v）", tc.attempt, d, tc.max/2, tc.max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicy_RuleFor(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	testCases := []struct {
+		err   error
+		kind  error
+		retry bool
+	}{
+		{newAPIError(429, "", ""), ErrRateLimited, true},
+		{newAPIError(503, "", ""), ErrServerUnavailable, true},
+		{fmt.Errorf("%w: connection reset", ErrNetwork), ErrNetwork, true},
+		{newAPIError(401, "", ""), nil, false},
+		{newAPIError(404, "", ""), nil, false},
+	}
+
+	for _, tc := range testCases {
+		kind, _, ok := policy.ruleFor(tc.err)
+		if kind != tc.kind || ok != tc.retry {
+			t.Errorf("%v: 期待=(%v, %t), 実際=(%v, %t)", tc.err, tc.kind, tc.retry, kind, ok)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"edinet-api-test/internal/utils"
+)
+
+// Entry アーカイブに保存した書類のメタデータ
+type Entry struct {
+	DocID     string    `json:"docID"`
+	Type      int       `json:"type"`   // 書類取得APIの取得種別
+	SHA256    string    `json:"sha256"` // 内容のSHA-256（保存先のファイル名）
+	Size      int64     `json:"size"`
+	URL       string    `json:"url,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Archive 書類管理番号ごとにダウンロードしたファイルを保存するディレクトリ
+//
+// ファイルの内容はSHA-256で名前を付けてobjects/に保存し（同じ内容は1つだけ保存する）、
+// 書類管理番号・取得種別ごとのメタデータをindex/に保存する。書き込みは一時ファイルの
+// 名前変更で行うため、中断しても書きかけのファイルが参照されることはない。
+// 複数のゴルーチンから同時に使える。
+type Archive struct {
+	root string
+	now  func() time.Time
+}
+
+// Open アーカイブのディレクトリを開く（なければ作成する）
+func Open(root string) (*Archive, error) {
+	for _, dir := range []string{"objects", "index"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			return nil, fmt.Errorf("アーカイブの作成エラー: %v", err)
+		}
+	}
+	return &Archive{root: root, now: time.Now}, nil
+}
+
+// Root アーカイブのディレクトリ
+func (a *Archive) Root() string {
+	return a.root
+}
+
+// Path 書類の内容を保存したファイルのパス
+func (a *Archive) Path(e Entry) string {
+	return a.objectPath(e.SHA256)
+}
+
+// Get 書類管理番号・取得種別のエントリを取得
+// 内容のファイルがない、またはサイズが一致しない場合は見つからないものとして扱う
+func (a *Archive) Get(docID string, typ int) (Entry, bool, error) {
+	data, err := os.ReadFile(a.indexPath(docID, typ))
+	if os.IsNotExist(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("アーカイブの読み込みエラー: %v", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Entry{}, false, fmt.Errorf("アーカイブのメタデータが不正です (%s): %v", docID, err)
+	}
+	info, err := os.Stat(a.Path(e))
+	if err != nil || info.Size() != e.Size {
+		return Entry{}, false, nil
+	}
+	return e, true, nil
+}
+
+// Put 書類の内容を保存し、エントリを返す
+func (a *Archive) Put(docID string, typ int, url string, data []byte) (Entry, error) {
+	if docID == "" || strings.ContainsAny(docID, `/\.`) {
+		return Entry{}, fmt.Errorf("書類管理番号が不正です: %q", docID)
+	}
+	sum := sha256.Sum256(data)
+	e := Entry{
+		DocID:     docID,
+		Type:      typ,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+		URL:       url,
+		FetchedAt: a.now().UTC(),
+	}
+
+	objectPath := a.Path(e)
+	if info, err := os.Stat(objectPath); err != nil || info.Size() != e.Size {
+		if err := utils.WriteFileAtomic(objectPath, data); err != nil {
+			return Entry{}, fmt.Errorf("アーカイブの保存エラー: %v", err)
+		}
+	}
+
+	meta, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("アーカイブのメタデータ作成エラー: %v", err)
+	}
+	if err := utils.WriteFileAtomic(a.indexPath(docID, typ), meta); err != nil {
+		return Entry{}, fmt.Errorf("アーカイブの保存エラー: %v", err)
+	}
+	return e, nil
+}
+
+// List 全てのエントリを書類管理番号・取得種別の順に返す
+func (a *Archive) List() ([]Entry, error) {
+	files, err := os.ReadDir(filepath.Join(a.root, "index"))
+	if err != nil {
+		return nil, fmt.Errorf("アーカイブの読み込みエラー: %v", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(a.root, "index", f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("アーカイブの読み込みエラー: %v", err)
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("アーカイブのメタデータが不正です (%s): %v", f.Name(), err)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].DocID != entries[j].DocID {
+			return entries[i].DocID < entries[j].DocID
+		}
+		return entries[i].Type < entries[j].Type
+	})
+	return entries, nil
+}
+
+// Verify 保存した内容がメタデータのサイズ・チェックサムと一致するか確認
+func (a *Archive) Verify(e Entry) error {
+	f, err := os.Open(a.Path(e))
+	if err != nil {
+		return fmt.Errorf("ファイルがありません: %v", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Errorf("ファイルの読み込みエラー: %v", err)
+	}
+	if size != e.Size {
+		return fmt.Errorf("サイズが一致しません: 期待=%d, 実際=%d", e.Size, size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != e.SHA256 {
+		return fmt.Errorf("チェックサムが一致しません: 期待=%s, 実際=%s", e.SHA256, sum)
+	}
+	return nil
+}
+
+// Remove エントリを削除（内容のファイルはPruneObjectsで削除する）
+func (a *Archive) Remove(e Entry) error {
+	if err := os.Remove(a.indexPath(e.DocID, e.Type)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("アーカイブの削除エラー: %v", err)
+	}
+	return nil
+}
+
+// PruneObjects どのエントリからも参照されていない内容のファイルを削除し、削除した数を返す
+func (a *Archive) PruneObjects() (int, error) {
+	entries, err := a.List()
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[string]bool)
+	for _, e := range entries {
+		used[a.Path(e)] = true
+	}
+
+	removed := 0
+	err = filepath.WalkDir(filepath.Join(a.root, "objects"), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || used[path] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("アーカイブの削除エラー: %v", err)
+	}
+	return removed, nil
+}
+
+// objectPath 内容のファイルのパス（ディレクトリが大きくなり過ぎないよう先頭2文字で分ける）
+func (a *Archive) objectPath(sum string) string {
+	if len(sum) < 2 {
+		return filepath.Join(a.root, "objects", sum)
+	}
+	return filepath.Join(a.root, "objects", sum[:2], sum)
+}
+
+// indexPath メタデータのファイルのパス
+func (a *Archive) indexPath(docID string, typ int) string {
+	return filepath.Join(a.root, "index", docID+"_"+strconv.Itoa(typ)+".json")
+}
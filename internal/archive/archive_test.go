@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"edinet-api-test/internal/api"
+)
+
+func newTestArchive(t *testing.T) *Archive {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("アーカイブ作成エラー: %v", err)
+	}
+	a.now = func() time.Time { return time.Date(2025, 6, 25, 6, 0, 0, 0, time.UTC) }
+	return a
+}
+
+func TestArchive_PutGet(t *testing.T) {
+	a := newTestArchive(t)
+
+	if _, ok, err := a.Get("S100ABCD", int(api.DocumentXBRL)); ok || err != nil {
+		t.Fatalf("保存前は見つからないべきです: ok=%v, err=%v", ok, err)
+	}
+
+	data := []byte("PK\x03\x04 test zip")
+	put, err := a.Put("S100ABCD", int(api.DocumentXBRL), "https://example.com/documents/S100ABCD?type=1", data)
+	if err != nil {
+		t.Fatalf("保存エラー: %v", err)
+	}
+
+	got, ok, err := a.Get("S100ABCD", int(api.DocumentXBRL))
+	if !ok || err != nil {
+		t.Fatalf("保存後は見つかるべきです: ok=%v, err=%v", ok, err)
+	}
+	if got != put {
+		t.Errorf("エントリ不一致: 期待=%+v, 実際=%+v", put, got)
+	}
+	if got.Size != int64(len(data)) || len(got.SHA256) != 64 {
+		t.Errorf("サイズ・チェックサムが不正です: %+v", got)
+	}
+
+	content, err := os.ReadFile(a.Path(got))
+	if err != nil || string(content) != string(data) {
+		t.Errorf("保存した内容が一致しません: %q (%v)", content, err)
+	}
+
+	// 取得種別が異なれば別のエントリ
+	if _, ok, _ := a.Get("S100ABCD", 2); ok {
+		t.Error("取得種別が異なるエントリが見つかっています")
+	}
+}
+
+func TestArchive_PutInvalidDocID(t *testing.T) {
+	a := newTestArchive(t)
+	for _, docID := range []string{"", "../S100ABCD", "a/b"} {
+		if _, err := a.Put(docID, int(api.DocumentXBRL), "", []byte("x")); err == nil {
+			t.Errorf("%q: エラーが発生すべきです", docID)
+		}
+	}
+}
+
+func TestArchive_ContentAddressed(t *testing.T) {
+	a := newTestArchive(t)
+
+	// 同じ内容は1つのファイルを共有する
+	e1, _ := a.Put("S100AAAA", int(api.DocumentXBRL), "", []byte("same"))
+	e2, _ := a.Put("S100BBBB", int(api.DocumentXBRL), "", []byte("same"))
+	if a.Path(e1) != a.Path(e2) {
+		t.Errorf("同じ内容のパスが異なります: %s, %s", a.Path(e1), a.Path(e2))
+	}
+
+	entries, err := a.List()
+	if err != nil {
+		t.Fatalf("一覧取得エラー: %v", err)
+	}
+	if len(entries) != 2 || entries[0].DocID != "S100AAAA" || entries[1].DocID != "S100BBBB" {
+		t.Errorf("一覧が不正です: %+v", entries)
+	}
+}
+
+func TestArchive_Verify(t *testing.T) {
+	a := newTestArchive(t)
+	e, _ := a.Put("S100ABCD", int(api.DocumentXBRL), "", []byte("original"))
+
+	if err := a.Verify(e); err != nil {
+		t.Errorf("保存直後は検証に成功するべきです: %v", err)
+	}
+
+	// 同じサイズで内容を書き換える
+	if err := os.WriteFile(a.Path(e), []byte("modified"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Verify(e); err == nil {
+		t.Error("内容が変わった場合、検証に失敗するべきです")
+	}
+
+	// サイズが変わった場合は見つからないものとして扱う
+	if err := os.WriteFile(a.Path(e), []byte("short"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := a.Get("S100ABCD", int(api.DocumentXBRL)); ok {
+		t.Error("サイズが一致しない場合は見つからないべきです")
+	}
+}
+
+func TestArchive_RemoveAndPrune(t *testing.T) {
+	a := newTestArchive(t)
+	keep, _ := a.Put("S100AAAA", int(api.DocumentXBRL), "", []byte("keep"))
+	drop, _ := a.Put("S100BBBB", int(api.DocumentXBRL), "", []byte("drop"))
+
+	if err := a.Remove(drop); err != nil {
+		t.Fatalf("削除エラー: %v", err)
+	}
+	removed, err := a.PruneObjects()
+	if err != nil {
+		t.Fatalf("削除エラー: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("削除数不一致: 期待=1, 実際=%d", removed)
+	}
+	if _, err := os.Stat(a.Path(drop)); !os.IsNotExist(err) {
+		t.Error("参照されていないファイルが残っています")
+	}
+	if err := a.Verify(keep); err != nil {
+		t.Errorf("参照されているファイルが削除されています: %v", err)
+	}
+}
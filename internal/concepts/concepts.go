@@ -0,0 +1,214 @@
+package concepts
+
+import (
+	"strings"
+
+	"edinet-api-test/internal/models"
+)
+
+// Standard 会計基準（DEIのAccountingStandardsDEIと同じ表記）
+type Standard string
+
+const (
+	StandardJapanGAAP Standard = "Japan GAAP"
+	StandardIFRS      Standard = "IFRS"
+	StandardUSGAAP    Standard = "US GAAP"
+	StandardJMIS      Standard = "JMIS"
+)
+
+// AccountingStandardsDEI 会計基準を表すDEI要素
+const AccountingStandardsDEI = "jpdei_cor:AccountingStandardsDEI"
+
+// mapping 標準科目（J-GAAPの要素名）ごとの会計基準別の要素名候補
+//
+// J-GAAPの候補は標準科目の要素名の後に試す。IFRS・米国基準の候補で見つからない場合も
+// J-GAAPの候補を試す（IFRS適用会社の単体財務諸表はjppfs_corで報告されるため）。
+// 米国基準の提出者は財務諸表本表を詳細タグ付けしないため、主要な経営指標等
+// （SummaryOfBusinessResults）の要素を使う。
+type mapping struct {
+	JapanGAAP []string
+	IFRS      []string
+	USGAAP    []string
+}
+
+var mappings = map[string]mapping{
+	// 損益計算書
+	"jppfs_cor:NetSales": {
+		JapanGAAP: []string{"jpcrp_cor:NetSalesSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:RevenueIFRS", "jpigp_cor:NetSalesIFRS", "jpcrp_cor:RevenueIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:RevenuesUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:GrossProfit": {
+		IFRS: []string{"jpigp_cor:GrossProfitIFRS"},
+	},
+	"jppfs_cor:OperatingIncome": {
+		IFRS:   []string{"jpigp_cor:OperatingProfitLossIFRS"},
+		USGAAP: []string{"jpcrp_cor:OperatingIncomeLossUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:OrdinaryIncome": {
+		JapanGAAP: []string{"jpcrp_cor:OrdinaryIncomeLossSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:IncomeBeforeIncomeTaxes": {
+		IFRS:   []string{"jpigp_cor:ProfitLossBeforeTaxIFRS", "jpcrp_cor:ProfitLossBeforeTaxIFRSSummaryOfBusinessResults"},
+		USGAAP: []string{"jpcrp_cor:ProfitLossBeforeTaxUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:ProfitLoss": {
+		IFRS: []string{"jpigp_cor:ProfitLossIFRS"},
+	},
+	"jppfs_cor:ProfitLossAttributableToOwnersOfParent": {
+		JapanGAAP: []string{"jpcrp_cor:ProfitLossAttributableToOwnersOfParentSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:ProfitLossAttributableToOwnersOfParentIFRS", "jpcrp_cor:ProfitLossAttributableToOwnersOfParentIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:NetIncomeLossAttributableToOwnersOfParentUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:BasicEarningsLossPerShareSummaryOfBusinessResults": {
+		JapanGAAP: []string{"jpcrp_cor:BasicEarningsLossPerShareSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:BasicEarningsLossPerShareIFRS", "jpcrp_cor:BasicEarningsLossPerShareIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:BasicEarningsLossPerShareUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:CostOfSales": {
+		IFRS: []string{"jpigp_cor:CostOfSalesIFRS"},
+	},
+	"jppfs_cor:SellingGeneralAndAdministrativeExpenses": {
+		IFRS: []string{"jpigp_cor:SellingGeneralAndAdministrativeExpensesIFRS"},
+	},
+	"jppfs_cor:IncomeTaxes": {
+		IFRS: []string{"jpigp_cor:IncomeTaxExpenseIFRS"},
+	},
+	// 貸借対照表
+	"jppfs_cor:TotalAssets": {
+		JapanGAAP: []string{"jpcrp_cor:TotalAssetsSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:AssetsIFRS", "jpcrp_cor:TotalAssetsIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:TotalAssetsUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:CurrentAssets": {
+		IFRS: []string{"jpigp_cor:TotalCurrentAssetsIFRS"},
+	},
+	"jppfs_cor:NoncurrentAssets": {
+		IFRS: []string{"jpigp_cor:NonCurrentAssetsIFRS"},
+	},
+	"jppfs_cor:Liabilities": {
+		IFRS: []string{"jpigp_cor:LiabilitiesIFRS"},
+	},
+	"jppfs_cor:CurrentLiabilities": {
+		IFRS: []string{"jpigp_cor:TotalCurrentLiabilitiesIFRS"},
+	},
+	"jppfs_cor:NoncurrentLiabilities": {
+		// タクソノミーの要素名の綴り（Labilities）のまま
+		IFRS: []string{"jpigp_cor:NonCurrentLabilitiesIFRS"},
+	},
+	"jppfs_cor:NetAssets": {
+		JapanGAAP: []string{"jpcrp_cor:NetAssetsSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:EquityIFRS"},
+		USGAAP:    []string{"jpcrp_cor:EquityIncludingPortionAttributableToNonControllingInterestUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:ShareholdersEquity": {
+		IFRS:   []string{"jpigp_cor:EquityAttributableToOwnersOfParentIFRS"},
+		USGAAP: []string{"jpcrp_cor:EquityAttributableToOwnersOfParentUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:CapitalStock": {
+		IFRS: []string{"jpigp_cor:ShareCapitalIFRS"},
+	},
+	"jppfs_cor:CapitalSurplus": {
+		IFRS: []string{"jpigp_cor:CapitalSurplusIFRS"},
+	},
+	"jppfs_cor:RetainedEarnings": {
+		IFRS: []string{"jpigp_cor:RetainedEarningsIFRS"},
+	},
+	"jppfs_cor:TreasuryStock": {
+		IFRS: []string{"jpigp_cor:TreasurySharesIFRS"},
+	},
+	"jppfs_cor:NotesAndAccountsReceivableTrade": {
+		IFRS: []string{"jpigp_cor:TradeAndOtherReceivablesCAIFRS"},
+	},
+	"jppfs_cor:Inventories": {
+		IFRS: []string{"jpigp_cor:InventoriesIFRS"},
+	},
+	"jppfs_cor:PropertyPlantAndEquipment": {
+		IFRS: []string{"jpigp_cor:PropertyPlantAndEquipmentIFRS"},
+	},
+	"jppfs_cor:IntangibleAssets": {
+		IFRS: []string{"jpigp_cor:IntangibleAssetsIFRS"},
+	},
+	"jppfs_cor:NotesAndAccountsPayableTrade": {
+		IFRS: []string{"jpigp_cor:TradeAndOtherPayablesCLIFRS"},
+	},
+	"jppfs_cor:NetAssetsPerShareSummaryOfBusinessResults": {
+		JapanGAAP: []string{"jpcrp_cor:NetAssetsPerShareSummaryOfBusinessResults"},
+		IFRS:      []string{"jpcrp_cor:EquityAttributableToOwnersOfParentPerShareIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:EquityAttributableToOwnersOfParentPerShareUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:EquityToAssetRatioSummaryOfBusinessResults": {
+		JapanGAAP: []string{"jpcrp_cor:EquityToAssetRatioSummaryOfBusinessResults"},
+		IFRS:      []string{"jpcrp_cor:RatioOfOwnersEquityToGrossAssetsIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:EquityToAssetRatioUSGAAPSummaryOfBusinessResults"},
+	},
+	// キャッシュ・フロー計算書
+	"jppfs_cor:NetCashProvidedByUsedInOperatingActivities": {
+		JapanGAAP: []string{"jpcrp_cor:NetCashProvidedByUsedInOperatingActivitiesSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:NetCashProvidedByUsedInOperatingActivitiesIFRS", "jpcrp_cor:CashFlowsFromUsedInOperatingActivitiesIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:CashFlowsFromUsedInOperatingActivitiesUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:NetCashProvidedByUsedInInvestmentActivities": {
+		JapanGAAP: []string{"jpcrp_cor:NetCashProvidedByUsedInInvestingActivitiesSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:NetCashProvidedByUsedInInvestingActivitiesIFRS", "jpcrp_cor:CashFlowsFromUsedInInvestingActivitiesIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:CashFlowsFromUsedInInvestingActivitiesUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:NetCashProvidedByUsedInFinancingActivities": {
+		JapanGAAP: []string{"jpcrp_cor:NetCashProvidedByUsedInFinancingActivitiesSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:NetCashProvidedByUsedInFinancingActivitiesIFRS", "jpcrp_cor:CashFlowsFromUsedInFinancingActivitiesIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:CashFlowsFromUsedInFinancingActivitiesUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:CashAndCashEquivalents": {
+		JapanGAAP: []string{"jpcrp_cor:CashAndCashEquivalentsSummaryOfBusinessResults"},
+		IFRS:      []string{"jpigp_cor:CashAndCashEquivalentsIFRS", "jpcrp_cor:CashAndCashEquivalentsIFRSSummaryOfBusinessResults"},
+		USGAAP:    []string{"jpcrp_cor:CashAndEquivalentsUSGAAPSummaryOfBusinessResults"},
+	},
+	"jppfs_cor:Depreciation": {
+		IFRS: []string{"jpigp_cor:DepreciationAndAmortizationOpeCFIFRS"},
+	},
+	// 企業情報
+	"jppfs_cor:NumberOfEmployees": {
+		JapanGAAP: []string{"jpcrp_cor:NumberOfEmployees"},
+	},
+	"jppfs_cor:ResearchAndDevelopmentExpenses": {
+		JapanGAAP: []string{"jppfs_cor:ResearchAndDevelopmentExpensesSGA", "jpcrp_cor:ResearchAndDevelopmentExpensesResearchAndDevelopmentActivities"},
+	},
+}
+
+// Candidates 標準科目に対応する要素名の候補を優先順に返す
+func Candidates(canonical string, std Standard) []string {
+	m := mappings[canonical]
+
+	var candidates []string
+	switch std {
+	case StandardIFRS:
+		candidates = append(candidates, m.IFRS...)
+	case StandardUSGAAP:
+		candidates = append(candidates, m.USGAAP...)
+	}
+	candidates = append(candidates, canonical)
+	candidates = append(candidates, m.JapanGAAP...)
+	return candidates
+}
+
+// Detect インスタンスの会計基準を判定
+// DEIの値を優先し、DEIがない場合は使われている要素から推定する
+func Detect(instance *models.XBRLInstance) Standard {
+	if std := instance.DEI().AccountingStandards; std != "" {
+		return Standard(std)
+	}
+
+	usgaap := false
+	for _, f := range instance.Facts {
+		if strings.HasPrefix(f.Name, "jpigp_cor:") {
+			return StandardIFRS
+		}
+		if strings.HasSuffix(f.Name, "USGAAPSummaryOfBusinessResults") {
+			usgaap = true
+		}
+	}
+	if usgaap {
+		return StandardUSGAAP
+	}
+	return StandardJapanGAAP
+}
@@ -0,0 +1,99 @@
+package concepts
+
+import (
+	"testing"
+
+	"edinet-api-test/internal/models"
+)
+
+func TestCandidates(t *testing.T) {
+	testCases := []struct {
+		canonical string
+		standard  Standard
+		expected  []string
+	}{
+		{"jppfs_cor:NetSales", StandardJapanGAAP, []string{
+			"jppfs_cor:NetSales", "jpcrp_cor:NetSalesSummaryOfBusinessResults",
+		}},
+		{"jppfs_cor:NetSales", StandardIFRS, []string{
+			"jpigp_cor:RevenueIFRS", "jpigp_cor:NetSalesIFRS", "jpcrp_cor:RevenueIFRSSummaryOfBusinessResults",
+			"jppfs_cor:NetSales", "jpcrp_cor:NetSalesSummaryOfBusinessResults",
+		}},
+		{"jppfs_cor:NetSales", StandardUSGAAP, []string{
+			"jpcrp_cor:RevenuesUSGAAPSummaryOfBusinessResults",
+			"jppfs_cor:NetSales", "jpcrp_cor:NetSalesSummaryOfBusinessResults",
+		}},
+		// 対応表にない科目は標準科目の要素名のみ
+		{"jppfs_cor:BondsPayable", StandardIFRS, []string{"jppfs_cor:BondsPayable"}},
+	}
+
+	for _, tc := range testCases {
+		result := Candidates(tc.canonical, tc.standard)
+		if len(result) != len(tc.expected) {
+			t.Errorf("%s/%s: 候補数不一致: 期待=%v, 実際=%v", tc.canonical, tc.standard, tc.expected, result)
+			continue
+		}
+		for i := range result {
+			if result[i] != tc.expected[i] {
+				t.Errorf("%s/%s: 候補[%d]不一致: 期待=%s, 実際=%s", tc.canonical, tc.standard, i, tc.expected[i], result[i])
+			}
+		}
+	}
+}
+
+func TestCandidates_IFRSBalanceSheet(t *testing.T) {
+	// 貸借対照表の科目はjpigp_corの要素名を最初に試す
+	expected := map[string]string{
+		"jppfs_cor:TotalAssets":                     "jpigp_cor:AssetsIFRS",
+		"jppfs_cor:CurrentAssets":                   "jpigp_cor:TotalCurrentAssetsIFRS",
+		"jppfs_cor:NoncurrentAssets":                "jpigp_cor:NonCurrentAssetsIFRS",
+		"jppfs_cor:Liabilities":                     "jpigp_cor:LiabilitiesIFRS",
+		"jppfs_cor:CurrentLiabilities":              "jpigp_cor:TotalCurrentLiabilitiesIFRS",
+		"jppfs_cor:NoncurrentLiabilities":           "jpigp_cor:NonCurrentLabilitiesIFRS",
+		"jppfs_cor:NetAssets":                       "jpigp_cor:EquityIFRS",
+		"jppfs_cor:ShareholdersEquity":              "jpigp_cor:EquityAttributableToOwnersOfParentIFRS",
+		"jppfs_cor:CapitalStock":                    "jpigp_cor:ShareCapitalIFRS",
+		"jppfs_cor:CapitalSurplus":                  "jpigp_cor:CapitalSurplusIFRS",
+		"jppfs_cor:RetainedEarnings":                "jpigp_cor:RetainedEarningsIFRS",
+		"jppfs_cor:TreasuryStock":                   "jpigp_cor:TreasurySharesIFRS",
+		"jppfs_cor:NotesAndAccountsReceivableTrade": "jpigp_cor:TradeAndOtherReceivablesCAIFRS",
+		"jppfs_cor:Inventories":                     "jpigp_cor:InventoriesIFRS",
+		"jppfs_cor:PropertyPlantAndEquipment":       "jpigp_cor:PropertyPlantAndEquipmentIFRS",
+		"jppfs_cor:IntangibleAssets":                "jpigp_cor:IntangibleAssetsIFRS",
+		"jppfs_cor:NotesAndAccountsPayableTrade":    "jpigp_cor:TradeAndOtherPayablesCLIFRS",
+	}
+
+	for canonical, want := range expected {
+		result := Candidates(canonical, StandardIFRS)
+		if result[0] != want {
+			t.Errorf("%s: IFRSの候補不一致: 期待=%s, 実際=%v", canonical, want, result)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	// DEIを優先
+	dei := models.NewXBRLInstance()
+	dei.AddFact(models.Fact{Name: AccountingStandardsDEI, Value: "US GAAP"})
+	dei.AddFact(models.Fact{Name: "jpigp_cor:RevenueIFRS", Value: "1"})
+	if std := Detect(dei); std != StandardUSGAAP {
+		t.Errorf("DEIの会計基準不一致: 期待=%s, 実際=%s", StandardUSGAAP, std)
+	}
+
+	// DEIがない場合は要素から推定
+	ifrs := models.NewXBRLInstance()
+	ifrs.AddFact(models.Fact{Name: "jpigp_cor:RevenueIFRS", Value: "1"})
+	if std := Detect(ifrs); std != StandardIFRS {
+		t.Errorf("IFRSの推定不一致: 実際=%s", std)
+	}
+
+	usgaap := models.NewXBRLInstance()
+	usgaap.AddFact(models.Fact{Name: "jpcrp_cor:RevenuesUSGAAPSummaryOfBusinessResults", Value: "1"})
+	if std := Detect(usgaap); std != StandardUSGAAP {
+		t.Errorf("米国基準の推定不一致: 実際=%s", std)
+	}
+
+	if std := Detect(models.NewXBRLInstance()); std != StandardJapanGAAP {
+		t.Errorf("既定の会計基準不一致: 実際=%s", std)
+	}
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"flag"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"edinet-api-test/internal/logging"
 )
 
 // Config アプリケーション設定
@@ -12,10 +17,58 @@ type Config struct {
 	StartDate    string
 	EndDate      string
 	TargetSecCode string
+	TargetEdinetCode string // 対象の提出者のEDINETコード（-code・-companyから決定）
+	Company      string // -companyで指定した提出者（証券コード・EDINETコード・法人番号・提出者名）
+	Watchlist    string // 対象の提出者の一覧（テキスト・CSV、または設定ファイル.json#名前）
+	Industries   []string // 対象の提出者業種
+	Markets      []string // 対象の上場区分（listed / unlisted）・市場区分
+	FiscalYearEndMonths []int // 対象の決算月
+	Verbose      bool   // デバッグログも出力する（-v）
+	Quiet        bool   // 警告・エラーのみ出力する（-q）
+	LogFormat    string // ログの形式（text / json）
 	OutputFile   string
 	QuarterOnly  bool
+	Interim      bool
+	Consolidation string
+	Source       string
+	CrossCheck   bool
+	CrossCheckCSV bool // XBRLとtype=5のCSVの両方を解析し、差異を表示する
+	RequestsPerSecond float64
+	DailyLimit   int
+	BaseURL      string
+	Proxy        *url.URL
+	Timeout      time.Duration
+	Workers      int
+	ArchiveDir   string
+	ListCacheDir string
+	RecentDays   int
+	RefreshLists bool
+	Sync         bool   // syncサブコマンドで実行する（フラグではなくmainで設定）
+	StateFile    string // syncモードの状態ファイル
+	JournalFile  string // 処理結果を記録するジャーナル
+	Resume       bool   // ジャーナルから中断した実行を再開する
+	PDFDir       string // 書類のPDFを保存するディレクトリ（空の場合は保存しない）
+	RegistryFile string // EDINETコードリストから取り込んだ提出者の一覧
 }
 
+// 連結・単体の出力モード
+const (
+	ConsolidationConsolidated    = "consolidated"
+	ConsolidationNonConsolidated = "nonconsolidated"
+	ConsolidationBoth            = "both"
+)
+
+// 財務データの読み込み元
+const (
+	SourceAuto  = "auto"  // .xbrlを優先し、なければiXBRL
+	SourceXBRL  = "xbrl"  // PublicDocの.xbrl
+	SourceIXBRL = "ixbrl" // PublicDocのiXBRL（*_ixbrl.htm）
+	SourceCSV   = "csv"   // XBRLをCSVに変換したファイル（書類取得APIのtype=5）
+)
+
+// OutputStdout 標準出力にCSVを書き込む場合の出力ファイル名
+const OutputStdout = "-"
+
 // JapaneseHeaders 日本語ヘッダー
 var JapaneseHeaders = []string{
 	"日付", "証券コード", "会社名", "文書タイプ", "会計期間",
@@ -57,9 +110,12 @@ var JapaneseHeaders = []string{
 	"有形固定資産取得による支出", "有形固定資産売却による収入", "無形固定資産取得による支出", "無形固定資産売却による収入",
 	"短期借入金による収入", "短期借入金返済額", "長期借入金による収入", "長期借入金返済額",
 	"社債発行による収入", "社債償還額",
+	// 追加メタデータ
+	"採用コンテキスト", "書類管理番号", "訂正履歴", "PDF",
 }
 
-// FinancialTags 財務タグ
+// FinancialTags 財務タグ（JapaneseHeadersの6列目以降と同じ順序）
+// 比率・メタデータ等の計算値もjppfs_cor接頭辞付きのキーで表す
 var FinancialTags = []string{
 	// 基本財務データ
 	"jppfs_cor:NetSales", "jppfs_cor:GrossProfit", "jppfs_cor:OperatingIncome", 
@@ -76,9 +132,18 @@ var FinancialTags = []string{
 	// 企業基本情報
 	"jppfs_cor:DateOfEstablishment", "jppfs_cor:DateOfListing", 
 	"jppfs_cor:NumberOfEmployees", "jppfs_cor:ResearchAndDevelopmentExpenses",
+	"jppfs_cor:ResearchAndDevelopmentExpenseRatio",
 	"jppfs_cor:AccountingStandards", "jppfs_cor:NameOfIndependentAuditor",
 	"jppfs_cor:ConsolidatedOrNonConsolidatedFinancialStatements",
 	"jppfs_cor:FiscalYearEnd", "jppfs_cor:FiscalYearStart",
+	// 収益性指標
+	"jppfs_cor:OperatingIncomeRatio", "jppfs_cor:OrdinaryIncomeRatio",
+	"jppfs_cor:ProfitLossRatio", "jppfs_cor:GrossProfitRatio",
+	"jppfs_cor:TotalAssetsTurnover", "jppfs_cor:NetAssetsTurnover",
+	"jppfs_cor:OperatingCashFlowRatio", "jppfs_cor:InvestmentCashFlowRatio",
+	// 安全性指標
+	"jppfs_cor:WorkingCapital", "jppfs_cor:DebtRatio", "jppfs_cor:FixedRatio", 
+	"jppfs_cor:FixedLongTermCoverageRatio",
 	// 追加財務データ
 	"jppfs_cor:CostOfSales", "jppfs_cor:SellingGeneralAndAdministrativeExpenses",
 	"jppfs_cor:NonOperatingIncome", "jppfs_cor:NonOperatingExpenses",
@@ -92,23 +157,19 @@ var FinancialTags = []string{
 	"jppfs_cor:BondsPayable", "jppfs_cor:ProvisionForRetirementBenefits",
 	"jppfs_cor:ShareholdersEquity", "jppfs_cor:CapitalSurplus",
 	"jppfs_cor:ValuationDifferenceOnAvailableForSaleSecurities", "jppfs_cor:TreasuryStock",
+	// 追加比率指標
+	"jppfs_cor:CurrentRatio", "jppfs_cor:QuickRatio", "jppfs_cor:AccountsReceivableTurnoverDays",
+	"jppfs_cor:InventoryTurnoverDays", "jppfs_cor:PropertyPlantAndEquipmentTurnover",
+	"jppfs_cor:TotalCapitalTurnover", "jppfs_cor:OperatingCapitalTurnover",
+	"jppfs_cor:InterestCoverageRatio", "jppfs_cor:DividendPayoutRatio",
+	"jppfs_cor:DividendYield",
 	// キャッシュフロー関連
 	"jppfs_cor:Depreciation", "jppfs_cor:IncreaseDecreaseInProvision",
 	"jppfs_cor:IncreaseDecreaseInWorkingCapital", "jppfs_cor:ProceedsFromSalesOfInvestmentSecurities",
 	"jppfs_cor:PaymentsForPurchaseOfInvestmentSecurities", "jppfs_cor:ProceedsFromLongTermLoansPayable",
 	"jppfs_cor:RepaymentsOfLongTermLoansPayable",
-	// 計算値（EDINETデータから算出）
-	"jppfs_cor:OperatingIncomeRatio", "jppfs_cor:OrdinaryIncomeRatio",
-	"jppfs_cor:ProfitLossRatio", "jppfs_cor:GrossProfitRatio",
-	"jppfs_cor:TotalAssetsTurnover", "jppfs_cor:NetAssetsTurnover",
-	"jppfs_cor:OperatingCashFlowRatio", "jppfs_cor:InvestmentCashFlowRatio",
-	"jppfs_cor:WorkingCapital", "jppfs_cor:DebtRatio", "jppfs_cor:FixedRatio", 
-	"jppfs_cor:FixedLongTermCoverageRatio", "jppfs_cor:CurrentRatio",
-	"jppfs_cor:QuickRatio", "jppfs_cor:AccountsReceivableTurnoverDays",
-	"jppfs_cor:InventoryTurnoverDays", "jppfs_cor:PropertyPlantAndEquipmentTurnover",
-	"jppfs_cor:TotalCapitalTurnover", "jppfs_cor:OperatingCapitalTurnover",
-	"jppfs_cor:InterestCoverageRatio", "jppfs_cor:DividendPayoutRatio",
-	"jppfs_cor:DividendYield", "jppfs_cor:FreeCashFlow", "jppfs_cor:CashFlowCoverageRatio",
+	"jppfs_cor:FreeCashFlow", "jppfs_cor:CashFlowCoverageRatio",
+	// 成長性指標
 	"jppfs_cor:NetSalesGrowthRate", "jppfs_cor:OperatingIncomeGrowthRate",
 	"jppfs_cor:ProfitLossGrowthRate", "jppfs_cor:TotalAssetsGrowthRate",
 	"jppfs_cor:NetSalesPerEmployee", "jppfs_cor:OperatingIncomePerEmployee",
@@ -121,6 +182,8 @@ var FinancialTags = []string{
 	"jppfs_cor:ProceedsFromShortTermLoansPayable", "jppfs_cor:RepaymentsOfShortTermLoansPayable",
 	"jppfs_cor:ProceedsFromLongTermLoansPayable", "jppfs_cor:RepaymentsOfLongTermLoansPayable",
 	"jppfs_cor:ProceedsFromIssuanceOfBonds", "jppfs_cor:RedemptionOfBonds",
+	// 追加メタデータ
+	"jppfs_cor:SelectedContexts", "jppfs_cor:DocID", "jppfs_cor:RevisionHistory", "jppfs_cor:PDFPath",
 }
 
 // LoadConfig 設定を読み込み
@@ -131,14 +194,58 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// コマンドライン引数を定義
-	var startDate, endDate, targetSecCode, outputFile string
-	var quarterOnly bool
+	var startDate, endDate, targetSecCode, company, outputFile, consolidation, source string
+	var quarterOnly, interim, crossCheck, crossCheckCSV bool
+	var requestsPerSecond float64
+	var dailyLimit int
+	var baseURL, proxy string
+	var timeout time.Duration
+	var workers int
+	var archiveDir, listCacheDir string
+	var recentDays int
+	var refreshLists bool
+	var stateFile string
+	var journalFile string
+	var resume bool
+	var pdfDir string
+	var registryFile string
+	var watchlist, industries, markets, fiscalYearEnd string
+	var verbose, quiet bool
+	var logFormat string
 	
 	flag.StringVar(&startDate, "start", "", "開始日 (YYYY-MM-DD形式)")
 	flag.StringVar(&endDate, "end", "", "終了日 (YYYY-MM-DD形式)")
 	flag.StringVar(&targetSecCode, "code", "", "対象証券コード（4桁または5桁、空文字列で全企業）")
-	flag.StringVar(&outputFile, "output", "", "出力ファイル名")
-	flag.BoolVar(&quarterOnly, "quarter", false, "四半期報告書のみを対象にする")
+	flag.StringVar(&company, "company", "", "対象の提出者（証券コード・EDINETコード・法人番号・提出者名のあいまい検索）")
+	flag.StringVar(&watchlist, "watchlist", "", "対象の提出者の一覧（1行に1件のテキスト、CSV、または設定ファイル.json#名前）")
+	flag.StringVar(&industries, "industry", "", "対象の提出者業種（カンマ区切り、例: 電気機器,輸送用機器）")
+	flag.StringVar(&markets, "market", "", "対象の上場区分（listed / unlisted）・市場区分（カンマ区切り、市場区分はCSVのウォッチリストの列で判定）")
+	flag.StringVar(&fiscalYearEnd, "fiscal-year-end", "", "対象の決算月（カンマ区切り、例: 3,12）")
+	flag.StringVar(&outputFile, "output", "", "出力ファイル名（-で標準出力）")
+	flag.BoolVar(&quarterOnly, "quarter", false, "四半期報告書・半期報告書のみを対象にする")
+	flag.BoolVar(&interim, "interim", false, "中間期の系列を出力する（2024年3月以前は第2四半期報告書、以降は半期報告書）")
+	flag.StringVar(&consolidation, "consolidation", ConsolidationConsolidated, "連結・単体の出力 (consolidated / nonconsolidated / both)")
+	flag.StringVar(&source, "source", SourceAuto, "財務データの読み込み元 (auto / xbrl / ixbrl / csv)")
+	flag.BoolVar(&crossCheck, "crosscheck", false, ".xbrlとiXBRLの両方を解析し、数値の差異を表示する")
+	flag.BoolVar(&crossCheckCSV, "crosscheck-csv", false, "XBRLとXBRLから変換したCSV（type=5）の両方を解析し、数値の差異を表示する")
+	flag.Float64Var(&requestsPerSecond, "rate", 1, "EDINET APIへの1秒あたりの最大リクエスト数")
+	flag.IntVar(&dailyLimit, "daily-limit", 0, "1日（日本時間）あたりの最大リクエスト数（0の場合は制限なし）")
+	flag.StringVar(&baseURL, "base-url", "", "EDINET APIのベースURL（ミラーを使う場合、空文字列で公式のURL）")
+	flag.StringVar(&proxy, "proxy", "", "HTTPプロキシのURL（空文字列で環境変数HTTPS_PROXY等に従う）")
+	flag.DurationVar(&timeout, "timeout", 2*time.Minute, "1回のリクエストのタイムアウト（0でタイムアウトなし）")
+	flag.IntVar(&workers, "workers", 4, "書類のダウンロード・解析を並行に行う数")
+	flag.StringVar(&archiveDir, "archive", "", "ダウンロードしたZIPを保存し、次回から再利用するディレクトリ（空文字列で保存しない）")
+	flag.StringVar(&listCacheDir, "list-cache", "", "日ごとの文書一覧を保存し、次回から再利用するディレクトリ（空文字列で保存しない）")
+	flag.IntVar(&recentDays, "recent-days", 7, "文書一覧のキャッシュを使わずに毎回取得する直近の日数")
+	flag.BoolVar(&refreshLists, "refresh-lists", false, "文書一覧のキャッシュを使わずに全て取得し直す")
+	flag.StringVar(&stateFile, "state", "sync_state.json", "syncモードの状態ファイル（前回同期した日付と出力済みの書類）")
+	flag.StringVar(&journalFile, "journal", "", "日・書類ごとの処理結果を記録するファイル（デフォルト: 出力ファイル名.journal.jsonl、標準出力の場合は記録しない）")
+	flag.StringVar(&pdfDir, "pdf-dir", "", "書類のPDFを保存するディレクトリ（CSVの「PDF」列にパスを出力）")
+	flag.StringVar(&registryFile, "registry", "edinet_codes.json", "EDINETコードリストから取り込んだ提出者の一覧（codelist updateで作成）")
+	flag.BoolVar(&verbose, "v", false, "詳細なログ（APIのリクエスト・解析したファイル等）も出力する")
+	flag.BoolVar(&quiet, "q", false, "警告・エラーのログのみ出力する")
+	flag.StringVar(&logFormat, "log-format", logging.FormatText, "ログの形式 (text / json)、ログは標準エラー出力に出す")
+	flag.BoolVar(&resume, "resume", false, "ジャーナルから中断した実行を再開する（処理済みの書類を飛ばし、失敗した書類を再試行する）")
 	
 	flag.Parse()
 
@@ -155,10 +262,83 @@ func LoadConfig() (*Config, error) {
 	if outputFile == "" {
 		outputFile = "xbrl_financial_items.csv"
 	}
+	if journalFile == "" && outputFile != OutputStdout {
+		journalFile = outputFile + ".journal.jsonl"
+	}
+	if outputFile == OutputStdout && resume {
+		return nil, &ConfigError{Message: "-output -（標準出力）では-resumeを指定できません。"}
+	}
+
+	if verbose && quiet {
+		return nil, &ConfigError{Message: "-vと-qは同時に指定できません。"}
+	}
+	switch logFormat {
+	case logging.FormatText, logging.FormatJSON:
+	default:
+		return nil, &ConfigError{Message: "-log-formatにはtext、jsonのいずれかを指定してください。"}
+	}
+
+	if targetSecCode != "" && company != "" {
+		return nil, &ConfigError{Message: "-codeと-companyは同時に指定できません。"}
+	}
+	var months []int
+	for _, s := range splitList(fiscalYearEnd) {
+		month, err := strconv.Atoi(strings.TrimSuffix(s, "月"))
+		if err != nil || month < 1 || month > 12 {
+			return nil, &ConfigError{Message: "-fiscal-year-endには1〜12の月をカンマ区切りで指定してください。"}
+		}
+		months = append(months, month)
+	}
+	if (targetSecCode != "" || company != "") && (watchlist != "" || industries != "" || markets != "" || len(months) > 0) {
+		return nil, &ConfigError{Message: "-code・-companyと-watchlist・-industry・-market・-fiscal-year-endは同時に指定できません。"}
+	}
+
+	switch consolidation {
+	case ConsolidationConsolidated, ConsolidationNonConsolidated, ConsolidationBoth:
+	default:
+		return nil, &ConfigError{Message: "-consolidationにはconsolidated、nonconsolidated、bothのいずれかを指定してください。"}
+	}
+
+	if quarterOnly && interim {
+		return nil, &ConfigError{Message: "-quarterと-interimは同時に指定できません。"}
+	}
+
+	switch source {
+	case SourceAuto, SourceXBRL, SourceIXBRL, SourceCSV:
+	default:
+		return nil, &ConfigError{Message: "-sourceにはauto、xbrl、ixbrl、csvのいずれかを指定してください。"}
+	}
+	if source == SourceCSV && crossCheck {
+		return nil, &ConfigError{Message: "-crosscheckはZIPの.xbrlとiXBRLを比べるため、-source csvとは同時に指定できません。-crosscheck-csvを使ってください。"}
+	}
+
+	if requestsPerSecond <= 0 {
+		return nil, &ConfigError{Message: "-rateには0より大きい値を指定してください。"}
+	}
+	if dailyLimit < 0 {
+		return nil, &ConfigError{Message: "-daily-limitには0以上の値を指定してください。"}
+	}
 
-	// 4桁の証券コードの場合は5桁に変換
-	if len(targetSecCode) == 4 {
-		targetSecCode = targetSecCode + "0"
+	if baseURL != "" && !isHTTPURL(baseURL) {
+		return nil, &ConfigError{Message: "-base-urlにはhttp://またはhttps://で始まるURLを指定してください。"}
+	}
+	var proxyURL *url.URL
+	if proxy != "" {
+		if !isHTTPURL(proxy) {
+			return nil, &ConfigError{Message: "-proxyにはhttp://またはhttps://で始まるURLを指定してください。"}
+		}
+		proxyURL, _ = url.Parse(proxy)
+	}
+	if timeout < 0 {
+		return nil, &ConfigError{Message: "-timeoutには0以上の値を指定してください。"}
+	}
+
+	if workers < 1 {
+		return nil, &ConfigError{Message: "-workersには1以上の値を指定してください。"}
+	}
+
+	if recentDays < 0 {
+		return nil, &ConfigError{Message: "-recent-daysには0以上の値を指定してください。"}
 	}
 
 	return &Config{
@@ -166,11 +346,64 @@ func LoadConfig() (*Config, error) {
 		StartDate:     startDate,
 		EndDate:       endDate,
 		TargetSecCode: targetSecCode,
+		Company:       company,
+		Watchlist:     watchlist,
+		Industries:    splitList(industries),
+		Markets:       splitList(markets),
+		FiscalYearEndMonths: months,
+		Verbose:       verbose,
+		Quiet:         quiet,
+		LogFormat:     logFormat,
 		OutputFile:    outputFile,
 		QuarterOnly:   quarterOnly,
+		Interim:       interim,
+		Consolidation: consolidation,
+		Source:        source,
+		CrossCheck:    crossCheck,
+		CrossCheckCSV: crossCheckCSV,
+		RequestsPerSecond: requestsPerSecond,
+		DailyLimit:    dailyLimit,
+		BaseURL:       baseURL,
+		Proxy:         proxyURL,
+		Timeout:       timeout,
+		Workers:       workers,
+		ArchiveDir:    archiveDir,
+		ListCacheDir:  listCacheDir,
+		RecentDays:    recentDays,
+		RefreshLists:  refreshLists,
+		StateFile:     stateFile,
+		JournalFile:   journalFile,
+		Resume:        resume,
+		PDFDir:        pdfDir,
+		RegistryFile:  registryFile,
 	}, nil
 }
 
+// splitList カンマ区切りの値を分割（空の値は除く）
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isHTTPURL http・httpsの絶対URLかどうか
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// HasUniverse ウォッチリスト・業種・市場区分・決算月で対象の提出者を絞り込むかどうか
+func (c *Config) HasUniverse() bool {
+	return c.Watchlist != "" || len(c.Industries) > 0 || len(c.Markets) > 0 || len(c.FiscalYearEndMonths) > 0
+}
+
 // GetDateRange 日付範囲を取得
 func (c *Config) GetDateRange() (time.Time, time.Time, error) {
 	const layout = "2006-01-02"
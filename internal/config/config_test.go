@@ -5,6 +5,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"edinet-api-test/internal/logging"
 )
 
 func TestLoadConfig_Success(t *testing.T) {
@@ -112,7 +114,7 @@ func TestConfigError_Error(t *testing.T) {
 }
 
 func TestJapaneseHeaders_Length(t *testing.T) {
-	expectedLength := 28 // 実際のヘッダー数
+	expectedLength := 120 // 実際のヘッダー数
 	if len(JapaneseHeaders) != expectedLength {
 		t.Errorf("日本語ヘッダーの長さ不一致: 期待=%d, 実際=%d", expectedLength, len(JapaneseHeaders))
 	}
@@ -124,10 +126,16 @@ func TestJapaneseHeaders_Length(t *testing.T) {
 			t.Errorf("ヘッダー[%d]不一致: 期待=%s, 実際=%s", i, expected, JapaneseHeaders[i])
 		}
 	}
+
+	// 追加した列は末尾に並ぶ（既存の列の位置を変えない）
+	expectedLastHeader := "PDF"
+	if JapaneseHeaders[len(JapaneseHeaders)-1] != expectedLastHeader {
+		t.Errorf("最後のヘッダー不一致: 期待=%s, 実際=%s", expectedLastHeader, JapaneseHeaders[len(JapaneseHeaders)-1])
+	}
 }
 
 func TestFinancialTags_Length(t *testing.T) {
-	expectedLength := 23 // 実際の財務タグの数
+	expectedLength := len(JapaneseHeaders) - 5 // 基本情報5列を除いたヘッダー数
 	if len(FinancialTags) != expectedLength {
 		t.Errorf("財務タグの長さ不一致: 期待=%d, 実際=%d", expectedLength, len(FinancialTags))
 	}
@@ -139,7 +147,7 @@ func TestFinancialTags_Length(t *testing.T) {
 	}
 
 	// 最後のタグを確認
-	expectedLastTag := "jppfs_cor:DividendsFromSurplus"
+	expectedLastTag := "jppfs_cor:PDFPath"
 	if FinancialTags[len(FinancialTags)-1] != expectedLastTag {
 		t.Errorf("最後の財務タグ不一致: 期待=%s, 実際=%s", expectedLastTag, FinancialTags[len(FinancialTags)-1])
 	}
@@ -170,8 +178,12 @@ func TestLoadConfig_WithCommandLineArgs(t *testing.T) {
 	if cfg.EndDate != "2025-01-31" {
 		t.Errorf("EndDate不一致: 期待=2025-01-31, 実際=%s", cfg.EndDate)
 	}
-	if cfg.TargetSecCode != "67580" {
-		t.Errorf("TargetSecCode不一致: 期待=67580, 実際=%s", cfg.TargetSecCode)
+	// 5桁への変換はEDINETコードリストで行う（main）
+	if cfg.TargetSecCode != "6758" {
+		t.Errorf("TargetSecCode不一致: 期待=6758, 実際=%s", cfg.TargetSecCode)
+	}
+	if cfg.RegistryFile != "edinet_codes.json" {
+		t.Errorf("RegistryFile不一致: 期待=edinet_codes.json, 実際=%s", cfg.RegistryFile)
 	}
 	if cfg.OutputFile != "test_output.csv" {
 		t.Errorf("OutputFile不一致: 期待=test_output.csv, 実際=%s", cfg.OutputFile)
@@ -179,6 +191,18 @@ func TestLoadConfig_WithCommandLineArgs(t *testing.T) {
 	if cfg.QuarterOnly != false {
 		t.Errorf("QuarterOnly不一致: 期待=false, 実際=%t", cfg.QuarterOnly)
 	}
+	if cfg.Consolidation != ConsolidationConsolidated {
+		t.Errorf("Consolidation不一致: 期待=%s, 実際=%s", ConsolidationConsolidated, cfg.Consolidation)
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("Workers不一致: 期待=4, 実際=%d", cfg.Workers)
+	}
+	if cfg.Source != SourceAuto {
+		t.Errorf("Source不一致: 期待=%s, 実際=%s", SourceAuto, cfg.Source)
+	}
+	if cfg.CrossCheck {
+		t.Error("CrossCheckはデフォルトでfalseであるべきです")
+	}
 }
 
 func TestLoadConfig_WithQuarterOnly(t *testing.T) {
@@ -200,4 +224,493 @@ func TestLoadConfig_WithQuarterOnly(t *testing.T) {
 	if cfg.QuarterOnly != true {
 		t.Errorf("QuarterOnly不一致: 期待=true, 実際=%t", cfg.QuarterOnly)
 	}
-} 
\ No newline at end of file
+} 
+func TestLoadConfig_WithInterim(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-interim"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+
+	if !cfg.Interim {
+		t.Error("Interimがtrueになっていません")
+	}
+}
+
+func TestLoadConfig_QuarterAndInterim(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-quarter", "-interim"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	_, err := LoadConfig()
+	if _, ok := err.(*ConfigError); !ok {
+		t.Errorf("ConfigError型のエラーが返されるべきです: %v", err)
+	}
+}
+
+func TestLoadConfig_WithConsolidation(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-consolidation", "both"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+
+	if cfg.Consolidation != ConsolidationBoth {
+		t.Errorf("Consolidation不一致: 期待=%s, 実際=%s", ConsolidationBoth, cfg.Consolidation)
+	}
+}
+
+func TestLoadConfig_InvalidConsolidation(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-consolidation", "group"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	_, err := LoadConfig()
+	if _, ok := err.(*ConfigError); !ok {
+		t.Errorf("ConfigError型のエラーが返されるべきです: %v", err)
+	}
+}
+
+func TestLoadConfig_WithSource(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-source", "ixbrl", "-crosscheck"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+
+	if cfg.Source != SourceIXBRL {
+		t.Errorf("Source不一致: 期待=%s, 実際=%s", SourceIXBRL, cfg.Source)
+	}
+	if !cfg.CrossCheck {
+		t.Error("CrossCheckがtrueになっていません")
+	}
+}
+
+func TestLoadConfig_WithCSVSource(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-source", "csv", "-crosscheck-csv"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.Source != SourceCSV {
+		t.Errorf("Source不一致: 期待=%s, 実際=%s", SourceCSV, cfg.Source)
+	}
+	if !cfg.CrossCheckCSV {
+		t.Error("CrossCheckCSVがtrueになっていません")
+	}
+
+	// CSVには.xbrlとiXBRLがないため-crosscheckは指定できない
+	os.Args = []string{"test", "-source", "csv", "-crosscheck"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	if _, err := LoadConfig(); err == nil {
+		t.Error("-source csvと-crosscheckの同時指定はエラーになるべきです")
+	} else if _, ok := err.(*ConfigError); !ok {
+		t.Errorf("ConfigError型のエラーが返されるべきです: %v", err)
+	}
+}
+
+func TestLoadConfig_InvalidSource(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-source", "pdf"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	_, err := LoadConfig()
+	if _, ok := err.(*ConfigError); !ok {
+		t.Errorf("ConfigError型のエラーが返されるべきです: %v", err)
+	}
+}
+
+func TestLoadConfig_WithRateLimit(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-rate", "0.5", "-daily-limit", "5000"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+
+	if cfg.RequestsPerSecond != 0.5 {
+		t.Errorf("RequestsPerSecond不一致: 期待=0.5, 実際=%v", cfg.RequestsPerSecond)
+	}
+	if cfg.DailyLimit != 5000 {
+		t.Errorf("DailyLimit不一致: 期待=5000, 実際=%d", cfg.DailyLimit)
+	}
+}
+
+func TestLoadConfig_InvalidRate(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	for _, args := range [][]string{{"-rate", "0"}, {"-daily-limit", "-1"}} {
+		// コマンドライン引数を設定
+		os.Args = append([]string{"test"}, args...)
+
+		// flagパッケージをリセット
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+		_, err := LoadConfig()
+		if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです: %v", args, err)
+		}
+	}
+}
+
+func TestLoadConfig_WithHTTPOptions(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-base-url", "http://mirror.example.com/api/v2", "-proxy", "http://proxy.example.com:8080", "-timeout", "30s"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+
+	if cfg.BaseURL != "http://mirror.example.com/api/v2" {
+		t.Errorf("BaseURL不一致: 実際=%s", cfg.BaseURL)
+	}
+	if cfg.Proxy == nil || cfg.Proxy.Host != "proxy.example.com:8080" {
+		t.Errorf("Proxy不一致: 実際=%v", cfg.Proxy)
+	}
+	if cfg.Timeout != 30*time.Second {
+		t.Errorf("Timeout不一致: 期待=30s, 実際=%v", cfg.Timeout)
+	}
+}
+
+func TestLoadConfig_InvalidHTTPOptions(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	for _, args := range [][]string{{"-base-url", "api.example.com"}, {"-proxy", "ftp://proxy.example.com"}, {"-timeout", "-1s"}} {
+		// コマンドライン引数を設定
+		os.Args = append([]string{"test"}, args...)
+
+		// flagパッケージをリセット
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+		_, err := LoadConfig()
+		if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです: %v", args, err)
+		}
+	}
+}
+
+func TestLoadConfig_WithWorkersAndArchive(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-workers", "8", "-archive", "edinet_archive"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.Workers != 8 {
+		t.Errorf("Workers不一致: 期待=8, 実際=%d", cfg.Workers)
+	}
+	if cfg.ArchiveDir != "edinet_archive" {
+		t.Errorf("ArchiveDir不一致: 期待=edinet_archive, 実際=%s", cfg.ArchiveDir)
+	}
+
+	// 0以下はエラー
+	os.Args = []string{"test", "-workers", "0"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	if _, err := LoadConfig(); err == nil {
+		t.Error("-workers 0の場合、エラーが発生すべきです")
+	}
+}
+
+func TestLoadConfig_WithListCache(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-list-cache", "edinet_lists", "-recent-days", "3", "-refresh-lists"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.ListCacheDir != "edinet_lists" {
+		t.Errorf("ListCacheDir不一致: 期待=edinet_lists, 実際=%s", cfg.ListCacheDir)
+	}
+	if cfg.RecentDays != 3 {
+		t.Errorf("RecentDays不一致: 期待=3, 実際=%d", cfg.RecentDays)
+	}
+	if !cfg.RefreshLists {
+		t.Error("RefreshListsがtrueになっていません")
+	}
+	if cfg.StateFile != "sync_state.json" {
+		t.Errorf("StateFile不一致: 期待=sync_state.json, 実際=%s", cfg.StateFile)
+	}
+
+	// 負の日数はエラー
+	os.Args = []string{"test", "-recent-days", "-1"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	if _, err := LoadConfig(); err == nil {
+		t.Error("-recent-days -1の場合、エラーが発生すべきです")
+	}
+}
+
+func TestLoadConfig_WithStateFile(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-state", "state/edinet.json"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.StateFile != "state/edinet.json" {
+		t.Errorf("StateFile不一致: 期待=state/edinet.json, 実際=%s", cfg.StateFile)
+	}
+	if cfg.Sync {
+		t.Error("Syncはフラグでは設定されません")
+	}
+}
+
+func TestLoadConfig_WithResume(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// ジャーナルは出力ファイル名から決まる
+	os.Args = []string{"test", "-output", "backfill.csv", "-resume"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if !cfg.Resume {
+		t.Error("Resumeがtrueになっていません")
+	}
+	if cfg.JournalFile != "backfill.csv.journal.jsonl" {
+		t.Errorf("JournalFile不一致: 期待=backfill.csv.journal.jsonl, 実際=%s", cfg.JournalFile)
+	}
+
+	// 明示的に指定
+	os.Args = []string{"test", "-journal", "runs/backfill.jsonl"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.JournalFile != "runs/backfill.jsonl" {
+		t.Errorf("JournalFile不一致: 期待=runs/backfill.jsonl, 実際=%s", cfg.JournalFile)
+	}
+}
+
+func TestLoadConfig_WithPDFDir(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-pdf-dir", "edinet_pdf"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.PDFDir != "edinet_pdf" {
+		t.Errorf("PDFDir不一致: 期待=edinet_pdf, 実際=%s", cfg.PDFDir)
+	}
+}
+
+func TestLoadConfig_WithCompany(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-company", "ソニー"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.Company != "ソニー" {
+		t.Errorf("Company不一致: 期待=ソニー, 実際=%s", cfg.Company)
+	}
+
+	// -codeとは同時に指定できない
+	os.Args = []string{"test", "-code", "6758", "-company", "ソニー"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	_, err = LoadConfig()
+	if _, ok := err.(*ConfigError); !ok {
+		t.Errorf("ConfigError型のエラーが返されるべきです: %v", err)
+	}
+}
+
+func TestLoadConfig_WithUniverse(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-watchlist", "watchlists.json#coverage", "-industry", "電気機器, 輸送用機器", "-market", "listed", "-fiscal-year-end", "3,12月"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if cfg.Watchlist != "watchlists.json#coverage" {
+		t.Errorf("Watchlist不一致: %s", cfg.Watchlist)
+	}
+	if len(cfg.Industries) != 2 || cfg.Industries[1] != "輸送用機器" {
+		t.Errorf("Industries不一致: %v", cfg.Industries)
+	}
+	if len(cfg.Markets) != 1 || cfg.Markets[0] != "listed" {
+		t.Errorf("Markets不一致: %v", cfg.Markets)
+	}
+	if len(cfg.FiscalYearEndMonths) != 2 || cfg.FiscalYearEndMonths[0] != 3 || cfg.FiscalYearEndMonths[1] != 12 {
+		t.Errorf("FiscalYearEndMonths不一致: %v", cfg.FiscalYearEndMonths)
+	}
+	if !cfg.HasUniverse() {
+		t.Error("HasUniverseがtrueになるべきです")
+	}
+
+	for _, args := range [][]string{
+		{"test", "-fiscal-year-end", "13"},
+		{"test", "-code", "6758", "-watchlist", "coverage.txt"},
+		{"test", "-company", "ソニー", "-industry", "電気機器"},
+	} {
+		os.Args = args
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		if _, err := LoadConfig(); err == nil {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです", args[1:])
+		} else if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです: %v", args[1:], err)
+		}
+	}
+}
+
+func TestLoadConfig_WithLogging(t *testing.T) {
+	// テスト用の環境変数を設定
+	os.Setenv("EDINET_API_KEY", "test-api-key")
+	defer os.Unsetenv("EDINET_API_KEY")
+
+	// コマンドライン引数を設定
+	os.Args = []string{"test", "-q", "-log-format", "json", "-output", "-"}
+
+	// flagパッケージをリセット
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("設定読み込みエラー: %v", err)
+	}
+	if !cfg.Quiet || cfg.Verbose || cfg.LogFormat != logging.FormatJSON {
+		t.Errorf("ログの設定不一致: quiet=%t verbose=%t format=%s", cfg.Quiet, cfg.Verbose, cfg.LogFormat)
+	}
+	// 標準出力に書き込む場合はジャーナルを記録しない
+	if cfg.OutputFile != OutputStdout || cfg.JournalFile != "" {
+		t.Errorf("標準出力の設定不一致: output=%s journal=%s", cfg.OutputFile, cfg.JournalFile)
+	}
+
+	for _, args := range [][]string{
+		{"test", "-v", "-q"},
+		{"test", "-log-format", "xml"},
+		{"test", "-output", "-", "-resume"},
+	} {
+		os.Args = args
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		if _, err := LoadConfig(); err == nil {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです", args[1:])
+		} else if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("%v: ConfigError型のエラーが返されるべきです: %v", args[1:], err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package doctype
+
+// Category 財務データ抽出における書類の分類
+type Category int
+
+const (
+	// CategoryOther 財務データの抽出対象外
+	CategoryOther Category = iota
+	// CategoryAnnual 有価証券報告書
+	CategoryAnnual
+	// CategoryQuarterly 四半期報告書
+	CategoryQuarterly
+	// CategorySemiAnnual 半期報告書
+	CategorySemiAnnual
+)
+
+// 主な書類種別コード
+const (
+	CodeAnnualReport            = "120" // 有価証券報告書
+	CodeAmendedAnnualReport     = "130" // 訂正有価証券報告書
+	CodeQuarterlyReport         = "140" // 四半期報告書
+	CodeAmendedQuarterlyReport  = "150" // 訂正四半期報告書
+	CodeSemiAnnualReport        = "160" // 半期報告書
+	CodeAmendedSemiAnnualReport = "170" // 訂正半期報告書
+)
+
+// UnknownName 登録されていないコードの表示名
+const UnknownName = "その他"
+
+// DocType 書類種別（EDINET API仕様書の書類種別コード）
+type DocType struct {
+	Code     string
+	Name     string
+	Original string // 訂正書類の場合は訂正元の書類種別コード
+	Category Category
+}
+
+// IsAmendment 訂正書類かどうか
+func (d DocType) IsAmendment() bool {
+	return d.Original != ""
+}
+
+// docTypes 書類種別コード一覧（EDINET API仕様書 Version 2 の順）
+var docTypes = []DocType{
+	{Code: "010", Name: "有価証券通知書"},
+	{Code: "020", Name: "変更通知書（有価証券通知書）", Original: "010"},
+	{Code: "030", Name: "有価証券届出書"},
+	{Code: "040", Name: "訂正有価証券届出書", Original: "030"},
+	{Code: "050", Name: "届出の取下げ願い"},
+	{Code: "060", Name: "発行登録通知書"},
+	{Code: "070", Name: "変更通知書（発行登録通知書）", Original: "060"},
+	{Code: "080", Name: "発行登録書"},
+	{Code: "090", Name: "訂正発行登録書", Original: "080"},
+	{Code: "100", Name: "発行登録追補書類"},
+	{Code: "110", Name: "発行登録取下届出書"},
+	{Code: CodeAnnualReport, Name: "有価証券報告書", Category: CategoryAnnual},
+	{Code: CodeAmendedAnnualReport, Name: "訂正有価証券報告書", Original: CodeAnnualReport, Category: CategoryAnnual},
+	{Code: "135", Name: "確認書"},
+	{Code: "136", Name: "訂正確認書", Original: "135"},
+	{Code: CodeQuarterlyReport, Name: "四半期報告書", Category: CategoryQuarterly},
+	{Code: CodeAmendedQuarterlyReport, Name: "訂正四半期報告書", Original: CodeQuarterlyReport, Category: CategoryQuarterly},
+	{Code: CodeSemiAnnualReport, Name: "半期報告書", Category: CategorySemiAnnual},
+	{Code: CodeAmendedSemiAnnualReport, Name: "訂正半期報告書", Original: CodeSemiAnnualReport, Category: CategorySemiAnnual},
+	{Code: "180", Name: "臨時報告書"},
+	{Code: "190", Name: "訂正臨時報告書", Original: "180"},
+	{Code: "200", Name: "親会社等状況報告書"},
+	{Code: "210", Name: "訂正親会社等状況報告書", Original: "200"},
+	{Code: "220", Name: "自己株券買付状況報告書"},
+	{Code: "230", Name: "訂正自己株券買付状況報告書", Original: "220"},
+	{Code: "235", Name: "内部統制報告書"},
+	{Code: "236", Name: "訂正内部統制報告書", Original: "235"},
+	{Code: "240", Name: "公開買付届出書"},
+	{Code: "250", Name: "訂正公開買付届出書", Original: "240"},
+	{Code: "260", Name: "公開買付撤回届出書"},
+	{Code: "270", Name: "公開買付報告書"},
+	{Code: "280", Name: "訂正公開買付報告書", Original: "270"},
+	{Code: "290", Name: "意見表明報告書"},
+	{Code: "300", Name: "訂正意見表明報告書", Original: "290"},
+	{Code: "310", Name: "対質問回答報告書"},
+	{Code: "320", Name: "訂正対質問回答報告書", Original: "310"},
+	{Code: "330", Name: "別途買付け禁止の特例を受けるための申出書"},
+	{Code: "340", Name: "訂正別途買付け禁止の特例を受けるための申出書", Original: "330"},
+	{Code: "350", Name: "大量保有報告書"},
+	{Code: "360", Name: "訂正大量保有報告書", Original: "350"},
+	{Code: "370", Name: "基準日の届出書"},
+	{Code: "380", Name: "変更の届出書"},
+}
+
+// byCode コードから書類種別への索引
+var byCode = func() map[string]DocType {
+	m := make(map[string]DocType, len(docTypes))
+	for _, d := range docTypes {
+		m[d.Code] = d
+	}
+	return m
+}()
+
+// All 書類種別の一覧をコード順に返す
+func All() []DocType {
+	return append([]DocType(nil), docTypes...)
+}
+
+// Lookup 書類種別コードから書類種別を返す
+func Lookup(code string) (DocType, bool) {
+	d, ok := byCode[code]
+	return d, ok
+}
+
+// Name 書類種別コードの日本語名（登録されていない場合は「その他」）
+func Name(code string) string {
+	if d, ok := byCode[code]; ok {
+		return d.Name
+	}
+	return UnknownName
+}
+
+// CategoryOf 書類種別コードの分類（登録されていない場合はCategoryOther）
+func CategoryOf(code string) Category {
+	return byCode[code].Category
+}
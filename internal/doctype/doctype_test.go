@@ -0,0 +1,120 @@
+package doctype
+
+import "testing"
+
+// specDocTypes EDINET API仕様書（Version 2）の書類種別コード一覧
+var specDocTypes = []struct {
+	code string
+	name string
+}{
+	{"010", "有価証券通知書"},
+	{"020", "変更通知書（有価証券通知書）"},
+	{"030", "有価証券届出書"},
+	{"040", "訂正有価証券届出書"},
+	{"050", "届出の取下げ願い"},
+	{"060", "発行登録通知書"},
+	{"070", "変更通知書（発行登録通知書）"},
+	{"080", "発行登録書"},
+	{"090", "訂正発行登録書"},
+	{"100", "発行登録追補書類"},
+	{"110", "発行登録取下届出書"},
+	{"120", "有価証券報告書"},
+	{"130", "訂正有価証券報告書"},
+	{"135", "確認書"},
+	{"136", "訂正確認書"},
+	{"140", "四半期報告書"},
+	{"150", "訂正四半期報告書"},
+	{"160", "半期報告書"},
+	{"170", "訂正半期報告書"},
+	{"180", "臨時報告書"},
+	{"190", "訂正臨時報告書"},
+	{"200", "親会社等状況報告書"},
+	{"210", "訂正親会社等状況報告書"},
+	{"220", "自己株券買付状況報告書"},
+	{"230", "訂正自己株券買付状況報告書"},
+	{"235", "内部統制報告書"},
+	{"236", "訂正内部統制報告書"},
+	{"240", "公開買付届出書"},
+	{"250", "訂正公開買付届出書"},
+	{"260", "公開買付撤回届出書"},
+	{"270", "公開買付報告書"},
+	{"280", "訂正公開買付報告書"},
+	{"290", "意見表明報告書"},
+	{"300", "訂正意見表明報告書"},
+	{"310", "対質問回答報告書"},
+	{"320", "訂正対質問回答報告書"},
+	{"330", "別途買付け禁止の特例を受けるための申出書"},
+	{"340", "訂正別途買付け禁止の特例を受けるための申出書"},
+	{"350", "大量保有報告書"},
+	{"360", "訂正大量保有報告書"},
+	{"370", "基準日の届出書"},
+	{"380", "変更の届出書"},
+}
+
+func TestAll_MatchesSpec(t *testing.T) {
+	all := All()
+	if len(all) != len(specDocTypes) {
+		t.Fatalf("書類種別の件数不一致: 期待=%d, 実際=%d", len(specDocTypes), len(all))
+	}
+	for i, want := range specDocTypes {
+		if all[i].Code != want.code || all[i].Name != want.name {
+			t.Errorf("書類種別[%d]不一致: 期待=%s %s, 実際=%s %s", i, want.code, want.name, all[i].Code, all[i].Name)
+		}
+	}
+}
+
+func TestAll_AmendmentsReferToOriginal(t *testing.T) {
+	for _, d := range All() {
+		if !d.IsAmendment() {
+			continue
+		}
+		original, ok := Lookup(d.Original)
+		if !ok {
+			t.Errorf("%s の訂正元 %s が登録されていません", d.Code, d.Original)
+			continue
+		}
+		if original.Category != d.Category {
+			t.Errorf("%s と訂正元 %s の分類が異なります", d.Code, d.Original)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected string
+	}{
+		{"120", "有価証券報告書"},
+		{"130", "訂正有価証券報告書"},
+		{"140", "四半期報告書"},
+		{"160", "半期報告書"},
+		{"999", "その他"},
+		{"", "その他"},
+	}
+
+	for _, tc := range testCases {
+		if result := Name(tc.code); result != tc.expected {
+			t.Errorf("コード %s の名称不一致: 期待=%s, 実際=%s", tc.code, tc.expected, result)
+		}
+	}
+}
+
+func TestCategoryOf(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected Category
+	}{
+		{CodeAnnualReport, CategoryAnnual},
+		{CodeAmendedAnnualReport, CategoryAnnual},
+		{CodeQuarterlyReport, CategoryQuarterly},
+		{CodeSemiAnnualReport, CategorySemiAnnual},
+		{"180", CategoryOther},
+		{"999", CategoryOther},
+	}
+
+	for _, tc := range testCases {
+		if result := CategoryOf(tc.code); result != tc.expected {
+			t.Errorf("コード %s の分類不一致: 期待=%d, 実際=%d", tc.code, tc.expected, result)
+		}
+	}
+}
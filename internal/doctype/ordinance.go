@@ -0,0 +1,59 @@
+package doctype
+
+// Ordinance 府令（EDINET API仕様書の府令コード）
+type Ordinance struct {
+	Code string
+	Name string
+}
+
+// ordinances 府令コード一覧
+var ordinances = []Ordinance{
+	{Code: "010", Name: "企業内容等の開示に関する内閣府令"},
+	{Code: "015", Name: "財務計算に関する書類その他の情報の適正性を確保するための体制に関する内閣府令"},
+	{Code: "020", Name: "外国債等の発行者の内容等の開示に関する内閣府令"},
+	{Code: "030", Name: "特定有価証券の内容等の開示に関する内閣府令"},
+	{Code: "040", Name: "発行者以外の者による株券等の公開買付けの開示に関する内閣府令"},
+	{Code: "050", Name: "発行者による上場株券等の公開買付けの開示に関する内閣府令"},
+	{Code: "060", Name: "株券等の大量保有の状況の開示に関する内閣府令"},
+}
+
+// OrdinanceName 府令コードの名称（登録されていない場合は「その他」）
+func OrdinanceName(code string) string {
+	for _, o := range ordinances {
+		if o.Code == code {
+			return o.Name
+		}
+	}
+	return UnknownName
+}
+
+// Form 様式（府令コードと様式コードの組）
+type Form struct {
+	OrdinanceCode string
+	FormCode      string
+	Name          string
+	DocTypeCode   string
+}
+
+// forms 財務データの抽出対象となる主な様式
+// 様式コードの全量はEDINETの様式コード一覧を参照のこと
+var forms = []Form{
+	{OrdinanceCode: "010", FormCode: "030000", Name: "第三号様式 有価証券報告書", DocTypeCode: CodeAnnualReport},
+	{OrdinanceCode: "010", FormCode: "030001", Name: "第三号様式 訂正有価証券報告書", DocTypeCode: CodeAmendedAnnualReport},
+	{OrdinanceCode: "010", FormCode: "043000", Name: "第四号の三様式 四半期報告書", DocTypeCode: CodeQuarterlyReport},
+	{OrdinanceCode: "010", FormCode: "043001", Name: "第四号の三様式 訂正四半期報告書", DocTypeCode: CodeAmendedQuarterlyReport},
+	{OrdinanceCode: "010", FormCode: "043A00", Name: "第四号の三様式 半期報告書", DocTypeCode: CodeSemiAnnualReport},
+	{OrdinanceCode: "010", FormCode: "043A01", Name: "第四号の三様式 訂正半期報告書", DocTypeCode: CodeAmendedSemiAnnualReport},
+	{OrdinanceCode: "010", FormCode: "050000", Name: "第五号様式 半期報告書", DocTypeCode: CodeSemiAnnualReport},
+	{OrdinanceCode: "010", FormCode: "050001", Name: "第五号様式 訂正半期報告書", DocTypeCode: CodeAmendedSemiAnnualReport},
+}
+
+// LookupForm 府令コードと様式コードから様式を返す
+func LookupForm(ordinanceCode, formCode string) (Form, bool) {
+	for _, f := range forms {
+		if f.OrdinanceCode == ordinanceCode && f.FormCode == formCode {
+			return f, true
+		}
+	}
+	return Form{}, false
+}
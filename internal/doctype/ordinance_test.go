@@ -0,0 +1,45 @@
+package doctype
+
+import "testing"
+
+func TestOrdinanceName(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected string
+	}{
+		{"010", "企業内容等の開示に関する内閣府令"},
+		{"015", "財務計算に関する書類その他の情報の適正性を確保するための体制に関する内閣府令"},
+		{"020", "外国債等の発行者の内容等の開示に関する内閣府令"},
+		{"030", "特定有価証券の内容等の開示に関する内閣府令"},
+		{"040", "発行者以外の者による株券等の公開買付けの開示に関する内閣府令"},
+		{"050", "発行者による上場株券等の公開買付けの開示に関する内閣府令"},
+		{"060", "株券等の大量保有の状況の開示に関する内閣府令"},
+		{"999", "その他"},
+	}
+
+	for _, tc := range testCases {
+		if result := OrdinanceName(tc.code); result != tc.expected {
+			t.Errorf("府令コード %s の名称不一致: 期待=%s, 実際=%s", tc.code, tc.expected, result)
+		}
+	}
+}
+
+func TestLookupForm(t *testing.T) {
+	form, ok := LookupForm("010", "030000")
+	if !ok {
+		t.Fatal("第三号様式が見つかりません")
+	}
+	if form.DocTypeCode != CodeAnnualReport {
+		t.Errorf("書類種別コード不一致: 期待=%s, 実際=%s", CodeAnnualReport, form.DocTypeCode)
+	}
+
+	for _, f := range forms {
+		if _, ok := Lookup(f.DocTypeCode); !ok {
+			t.Errorf("様式 %s の書類種別コード %s が登録されていません", f.FormCode, f.DocTypeCode)
+		}
+	}
+
+	if _, ok := LookupForm("030", "030000"); ok {
+		t.Error("府令コードが異なる様式は見つからないべきです")
+	}
+}
@@ -0,0 +1,264 @@
+package edinetcode
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+
+	"edinet-api-test/internal/utils"
+)
+
+// DefaultURL EDINETコードリスト（EdinetcodeDlInfo.csvのZIP）のダウンロードURL
+const DefaultURL = "https://disclosure2dl.edinet-fsa.go.jp/searchdocument/codelist/Edinetcode.zip"
+
+// DefaultPath 取り込んだ提出者の一覧の既定の保存先
+const DefaultPath = "edinet_codes.json"
+
+// EdinetcodeDlInfo.csvの列名
+const (
+	columnEdinetCode    = "ＥＤＩＮＥＴコード"
+	columnFilerType     = "提出者種別"
+	columnListed        = "上場区分"
+	columnConsolidated  = "連結の有無"
+	columnFiscalYearEnd = "決算日"
+	columnName          = "提出者名"
+	columnNameEnglish   = "提出者名（英字）"
+	columnNameKana      = "提出者名（ヨミ）"
+	columnIndustry      = "提出者業種"
+	columnSecCode       = "証券コード"
+	columnJCN           = "提出者法人番号"
+)
+
+// Company EDINETコードリストの提出者
+type Company struct {
+	EdinetCode    string `json:"edinetCode"`              // EDINETコード（E + 5桁）
+	SecCode       string `json:"secCode,omitempty"`       // 証券コード（5桁）
+	JCN           string `json:"jcn,omitempty"`           // 法人番号（13桁）
+	Name          string `json:"name"`                    // 提出者名
+	NameEnglish   string `json:"nameEnglish,omitempty"`   // 提出者名（英字）
+	NameKana      string `json:"nameKana,omitempty"`      // 提出者名（ヨミ）
+	FilerType     string `json:"filerType,omitempty"`     // 提出者種別（内国法人・組合、外国法人・組合等）
+	Industry      string `json:"industry,omitempty"`      // 提出者業種
+	FiscalYearEnd string `json:"fiscalYearEnd,omitempty"` // 決算日（例: 3月31日）
+	Listed        bool   `json:"listed"`                  // 上場区分が「上場」
+	Consolidated  bool   `json:"consolidated"`            // 連結の有無が「有」
+}
+
+// SecCode4 4桁の証券コード（証券コードがない場合は空文字列）
+func (c Company) SecCode4() string {
+	if len(c.SecCode) != 5 {
+		return c.SecCode
+	}
+	return c.SecCode[:4]
+}
+
+// Registry EDINETコードリストから作成した提出者の一覧
+type Registry struct {
+	AsOf       string    `json:"asOf,omitempty"` // コードリストの作成日（例: 2025年07月10日現在）
+	ImportedAt time.Time `json:"importedAt"`
+	Companies  []Company `json:"companies"`
+
+	byEdinetCode map[string]int
+	bySecCode    map[string]int
+	byJCN        map[string]int
+}
+
+// Load 保存した提出者の一覧を読み込む
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("提出者一覧の読み込みエラー: %v", err)
+	}
+	var r Registry
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("提出者一覧が不正です (%s): %v", path, err)
+	}
+	r.index()
+	return &r, nil
+}
+
+// Save 提出者の一覧を保存
+func (r *Registry) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("提出者一覧の作成エラー: %v", err)
+	}
+	if err := utils.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("提出者一覧の保存エラー: %v", err)
+	}
+	return nil
+}
+
+// Download EDINETコードリストのZIPをダウンロード
+func Download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエスト作成エラー: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("EDINETコードリストのダウンロードエラー: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("EDINETコードリストのダウンロードエラー: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("EDINETコードリストのダウンロードエラー: HTTP %d", resp.StatusCode)
+	}
+	return data, nil
+}
+
+// ParseZip EDINETコードリストのZIPからEdinetcodeDlInfo.csvを読み込む
+func ParseZip(data []byte) (*Registry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("ZIPファイルオープンエラー: %v", err)
+	}
+	for _, f := range zr.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".csv") {
+			continue
+		}
+		in, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
+		}
+		defer in.Close()
+		return ParseCSV(in)
+	}
+	return nil, fmt.Errorf("ZIPにEdinetcodeDlInfo.csvが見つかりません")
+}
+
+// ParseCSV EdinetcodeDlInfo.csv（Shift_JIS）を読み込む
+//
+// 1行目はダウンロード日・件数、2行目が列名で、3行目以降が提出者。
+func ParseCSV(in io.Reader) (*Registry, error) {
+	reader := csv.NewReader(transform.NewReader(in, japanese.ShiftJIS.NewDecoder()))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	info, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("EDINETコードリストの読み込みエラー: %v", err)
+	}
+	r := &Registry{ImportedAt: time.Now().UTC()}
+	if len(info) > 1 {
+		r.AsOf = strings.TrimSpace(info[1])
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("EDINETコードリストの列名の読み込みエラー: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{columnEdinetCode, columnName, columnSecCode, columnJCN} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("EDINETコードリストに「%s」列がありません", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("EDINETコードリストの読み込みエラー: %v", err)
+		}
+		c := Company{
+			EdinetCode:    field(record, columnEdinetCode),
+			SecCode:       field(record, columnSecCode),
+			JCN:           field(record, columnJCN),
+			Name:          field(record, columnName),
+			NameEnglish:   field(record, columnNameEnglish),
+			NameKana:      field(record, columnNameKana),
+			FilerType:     field(record, columnFilerType),
+			Industry:      field(record, columnIndustry),
+			FiscalYearEnd: field(record, columnFiscalYearEnd),
+			Listed:        field(record, columnListed) == "上場",
+			Consolidated:  field(record, columnConsolidated) == "有",
+		}
+		if c.EdinetCode == "" {
+			continue
+		}
+		r.Companies = append(r.Companies, c)
+	}
+
+	sort.Slice(r.Companies, func(i, j int) bool { return r.Companies[i].EdinetCode < r.Companies[j].EdinetCode })
+	r.index()
+	return r, nil
+}
+
+// index EDINETコード・証券コード・法人番号の索引を作成
+func (r *Registry) index() {
+	r.byEdinetCode = make(map[string]int)
+	r.bySecCode = make(map[string]int)
+	r.byJCN = make(map[string]int)
+	for i, c := range r.Companies {
+		r.byEdinetCode[c.EdinetCode] = i
+		if c.SecCode != "" {
+			r.bySecCode[c.SecCode] = i
+			// 4桁の証券コードは上場会社を優先する（同じ4桁で複数の提出者がある場合）
+			if prev, ok := r.bySecCode[c.SecCode4()]; !ok || (!r.Companies[prev].Listed && c.Listed) {
+				r.bySecCode[c.SecCode4()] = i
+			}
+		}
+		if c.JCN != "" {
+			r.byJCN[c.JCN] = i
+		}
+	}
+}
+
+// Lookup EDINETコード・証券コード（4桁・5桁）・法人番号・提出者名（日本語・英字・ヨミの完全一致）で提出者を探す
+func (r *Registry) Lookup(key string) (Company, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return Company{}, false
+	}
+	upper := strings.ToUpper(key)
+	for _, index := range []map[string]int{r.byEdinetCode, r.bySecCode, r.byJCN} {
+		if i, ok := index[upper]; ok {
+			return r.Companies[i], true
+		}
+	}
+	for _, c := range r.Companies {
+		if c.Name == key || c.NameKana == key || strings.EqualFold(c.NameEnglish, key) {
+			return c, true
+		}
+	}
+	return Company{}, false
+}
+
+// ByEdinetCode EDINETコードで提出者を探す
+func (r *Registry) ByEdinetCode(code string) (Company, bool) {
+	i, ok := r.byEdinetCode[code]
+	if !ok {
+		return Company{}, false
+	}
+	return r.Companies[i], true
+}
@@ -0,0 +1,152 @@
+package edinetcode
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// testCodeList EdinetcodeDlInfo.csvの例
+const testCodeList = `ダウンロード実行日,2025年07月10日現在,件数,3件
+ＥＤＩＮＥＴコード,提出者種別,上場区分,連結の有無,資本金,決算日,提出者名,提出者名（英字）,提出者名（ヨミ）,所在地,提出者業種,証券コード,提出者法人番号
+E02144,内国法人・組合,上場,有,635401,3月31日,トヨタ自動車株式会社,TOYOTA MOTOR CORPORATION,トヨタジドウシャカブシキガイシャ,愛知県豊田市トヨタ町１番地,輸送用機器,72030,1180301018771
+E01777,内国法人・組合,上場,有,881357,3月31日,ソニーグループ株式会社,Sony Group Corporation,ソニーグループカブシキガイシャ,東京都港区港南１丁目７番１号,電気機器,67580,5010401067252
+E12345,内国法人・組合,非上場,無,100,12月31日,テスト投資法人,Test Fund,テストトウシホウジン,東京都千代田区,その他,,
+`
+
+// encodeShiftJIS テスト用にShift_JISに変換
+func encodeShiftJIS(t *testing.T, s string) []byte {
+	t.Helper()
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("Shift_JIS変換エラー: %v", err)
+	}
+	return []byte(encoded)
+}
+
+// testCodeListZip EdinetcodeDlInfo.csvを含むZIP
+func testCodeListZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("EdinetcodeDlInfo.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(encodeShiftJIS(t, testCodeList))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseZip(t *testing.T) {
+	r, err := ParseZip(testCodeListZip(t))
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if len(r.Companies) != 3 {
+		t.Fatalf("提出者数不一致: 期待=3, 実際=%d", len(r.Companies))
+	}
+	if r.AsOf != "2025年07月10日現在" {
+		t.Errorf("AsOf不一致: %s", r.AsOf)
+	}
+
+	c, ok := r.ByEdinetCode("E02144")
+	if !ok {
+		t.Fatal("E02144が見つかりません")
+	}
+	if c.Name != "トヨタ自動車株式会社" || c.NameEnglish != "TOYOTA MOTOR CORPORATION" || c.Industry != "輸送用機器" {
+		t.Errorf("提出者の内容が不正です: %+v", c)
+	}
+	if c.SecCode != "72030" || c.SecCode4() != "7203" || c.JCN != "1180301018771" || c.FiscalYearEnd != "3月31日" {
+		t.Errorf("コードが不正です: %+v", c)
+	}
+	if !c.Listed || !c.Consolidated {
+		t.Errorf("上場区分・連結の有無が不正です: %+v", c)
+	}
+
+	fund, _ := r.ByEdinetCode("E12345")
+	if fund.Listed || fund.SecCode != "" {
+		t.Errorf("非上場の提出者が不正です: %+v", fund)
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r, err := ParseZip(testCodeListZip(t))
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+
+	for _, key := range []string{"E02144", "e02144", "72030", "7203", "1180301018771", "トヨタ自動車株式会社", "toyota motor corporation", "トヨタジドウシャカブシキガイシャ"} {
+		c, ok := r.Lookup(key)
+		if !ok || c.EdinetCode != "E02144" {
+			t.Errorf("%s: E02144が見つかるべきです: %+v", key, c)
+		}
+	}
+	for _, key := range []string{"", "9999", "E99999", "トヨタ"} {
+		if c, ok := r.Lookup(key); ok {
+			t.Errorf("%q: 見つからないべきです: %+v", key, c)
+		}
+	}
+}
+
+func TestRegistry_SaveLoad(t *testing.T) {
+	r, err := ParseZip(testCodeListZip(t))
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "edinet_codes.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("保存エラー: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if len(loaded.Companies) != 3 || loaded.AsOf != r.AsOf {
+		t.Errorf("保存した内容と一致しません: %d件, %s", len(loaded.Companies), loaded.AsOf)
+	}
+	if c, ok := loaded.Lookup("6758"); !ok || c.EdinetCode != "E01777" {
+		t.Errorf("読み込み後も索引で探せるべきです: %+v", c)
+	}
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	csv := "ダウンロード実行日,2025年07月10日現在\nＥＤＩＮＥＴコード,提出者名\nE02144,トヨタ自動車株式会社\n"
+	if _, err := ParseCSV(bytes.NewReader(encodeShiftJIS(t, csv))); err == nil {
+		t.Error("必要な列がない場合、エラーが発生すべきです")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	data := testCodeListZip(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/Edinetcode.zip") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	got, err := Download(context.Background(), server.Client(), server.URL+"/Edinetcode.zip")
+	if err != nil {
+		t.Fatalf("ダウンロードエラー: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("ダウンロードした内容が一致しません")
+	}
+
+	if _, err := Download(context.Background(), server.Client(), server.URL+"/missing.zip"); err == nil {
+		t.Error("HTTPエラーの場合、エラーが発生すべきです")
+	}
+}
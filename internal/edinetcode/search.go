@@ -0,0 +1,229 @@
+package edinetcode
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// 検索のスコア（提出者名のいずれかとの一致の度合い）
+const (
+	ScoreExact    = 1.0 // 正規化した提出者名が一致
+	ScorePrefix   = 0.9 // 提出者名の先頭が一致
+	ScoreContains = 0.8 // 提出者名の一部が一致
+	scoreSimilar  = 0.7 // 文字の並び（2文字ずつ）の類似度に掛ける係数
+	minSimilarity = 0.5 // 類似度がこれ未満の提出者は候補にしない
+)
+
+// Match 提出者名の検索結果
+type Match struct {
+	Company Company
+	Score   float64
+}
+
+// legalForms 比較の前に取り除く会社の種類の表記（正規化後）
+var legalForms = []string{
+	"株式会社", "(株)", "有限会社", "(有)", "合同会社", "投資法人",
+	"カブシキガイシャ", "カブシキカイシャ", "ユウゲンガイシャ", "ゴウドウガイシャ",
+}
+
+// englishLegalForms 英字の提出者名から取り除く単語
+var englishLegalForms = map[string]bool{
+	"co": true, "corp": true, "corporation": true, "company": true,
+	"inc": true, "incorporated": true, "ltd": true, "limited": true, "kk": true,
+}
+
+// Search 提出者名（日本語・英字・ヨミ）のあいまい検索
+//
+// 全角・半角、ひらがな・カタカナ、大文字・小文字、空白・記号、「株式会社」等の違いを無視して比べ、
+// スコアの高い順（同じ場合は上場会社、名前の短い順）に最大limit件を返す。limitが0以下の場合は全件。
+func (r *Registry) Search(query string, limit int) []Match {
+	q := normalizeName(query)
+	if q == "" {
+		return nil
+	}
+	qGrams := bigrams(q)
+
+	var matches []Match
+	for _, c := range r.Companies {
+		best := 0.0
+		for _, name := range []string{c.Name, c.NameEnglish, c.NameKana} {
+			if score := nameScore(q, qGrams, normalizeName(name)); score > best {
+				best = score
+			}
+		}
+		if best > 0 {
+			matches = append(matches, Match{Company: c, Score: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Company.Listed != b.Company.Listed {
+			return a.Company.Listed
+		}
+		if len(a.Company.Name) != len(b.Company.Name) {
+			return len(a.Company.Name) < len(b.Company.Name)
+		}
+		return a.Company.EdinetCode < b.Company.EdinetCode
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// nameScore 正規化した検索語と提出者名のスコア（候補にしない場合は0）
+func nameScore(q string, qGrams map[string]int, name string) float64 {
+	switch {
+	case name == "":
+		return 0
+	case name == q:
+		return ScoreExact
+	case strings.HasPrefix(name, q):
+		return ScorePrefix
+	case strings.Contains(name, q):
+		return ScoreContains
+	}
+	if sim := dice(qGrams, bigrams(name)); sim >= minSimilarity {
+		return scoreSimilar * sim
+	}
+	return 0
+}
+
+// normalizeName 提出者名を比較用に正規化
+func normalizeName(s string) string {
+	s = strings.ToLower(width.Fold.String(s))
+	for _, form := range legalForms {
+		s = strings.ReplaceAll(s, form, "")
+	}
+
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		if englishLegalForms[word] {
+			continue
+		}
+		for _, r := range word {
+			// ひらがなはカタカナとして比べる
+			if r >= 'ぁ' && r <= 'ゖ' {
+				r += 'ァ' - 'ぁ'
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// bigrams 2文字ずつの並びの出現数（1文字の場合はその文字）
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	grams := make(map[string]int)
+	if len(runes) == 1 {
+		grams[s]++
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	return grams
+}
+
+// dice 2文字ずつの並びのダイス係数
+func dice(a, b map[string]int) float64 {
+	var total, common int
+	for g, n := range a {
+		total += n
+		if m := b[g]; m > 0 {
+			common += min(n, m)
+		}
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(common) / float64(total)
+}
+
+// Resolve EDINETコード・証券コード・法人番号・提出者名から提出者を1社に決める
+//
+// Lookupで見つからない場合は提出者名をあいまい検索し、提出者名の全体または先頭が一致する候補が1社だけの場合、
+// または提出者名が一致するのが1社だけの場合に決める。一部の一致や類似度だけの候補は、1社でも確認のために候補として返す。
+// 決まらない場合はokがfalseで、候補をスコアの高い順に最大limit件返す。
+func (r *Registry) Resolve(key string, limit int) (c Company, candidates []Match, ok bool) {
+	if c, ok := r.Lookup(key); ok {
+		return c, nil, true
+	}
+	matches := r.Search(key, 0)
+	switch {
+	case len(matches) == 1 && matches[0].Score >= ScorePrefix:
+		return matches[0].Company, nil, true
+	case len(matches) > 1 && matches[0].Score == ScoreExact && matches[1].Score < ScoreExact:
+		return matches[0].Company, nil, true
+	}
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return Company{}, matches, false
+}
+
+// AmbiguousError 提出者名から提出者を1社に決められない（候補をスコアの高い順に持つ）
+type AmbiguousError struct {
+	Key        string
+	Candidates []Match
+}
+
+// Error エラーメッセージ
+func (e *AmbiguousError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, m := range e.Candidates {
+		names[i] = m.Company.EdinetCode + " " + m.Company.Name
+	}
+	return fmt.Sprintf("「%s」に該当する提出者を1社に決められません（候補: %s）。EDINETコード・証券コードで指定してください", e.Key, strings.Join(names, ", "))
+}
+
+// edinetCodePattern EDINETコードの形式（E + 5桁）
+var edinetCodePattern = regexp.MustCompile(`^[Ee]\d{5}$`)
+
+// ResolveKey -code・-company・ウォッチリストの指定から提出者を決める
+//
+// byNameがtrueの場合は提出者名もあいまい検索し、1社に決まらない場合は*AmbiguousErrorを返す（候補は最大limit件）。
+// 一覧にない場合はエラーにする。rがnil（一覧を取り込んでいない）場合のみ、コードの形式から
+// EDINETコードまたは証券コードだけを持つCompanyを返す（4桁の証券コードは末尾に0を付ける）。
+func (r *Registry) ResolveKey(key string, byName bool, limit int) (Company, error) {
+	key = strings.TrimSpace(key)
+	if r != nil {
+		var c Company
+		var candidates []Match
+		var ok bool
+		if byName {
+			c, candidates, ok = r.Resolve(key, limit)
+		} else {
+			c, ok = r.Lookup(key)
+		}
+		switch {
+		case ok:
+			return c, nil
+		case len(candidates) > 0:
+			return Company{}, &AmbiguousError{Key: key, Candidates: candidates}
+		}
+		return Company{}, fmt.Errorf("提出者の一覧に「%s」が見つかりません（一覧が古い場合は codelist update で取り込み直してください）", key)
+	}
+
+	switch {
+	case edinetCodePattern.MatchString(key):
+		return Company{EdinetCode: strings.ToUpper(key)}, nil
+	case len(key) == 4:
+		return Company{SecCode: key + "0"}, nil
+	case !byName || len(key) == 5:
+		return Company{SecCode: key}, nil
+	}
+	return Company{}, fmt.Errorf("提出者の一覧がないため「%s」を判断できません（提出者名・法人番号で指定するには codelist update で提出者の一覧を取り込んでください）", key)
+}
@@ -0,0 +1,142 @@
+package edinetcode
+
+import (
+	"errors"
+	"testing"
+)
+
+// testSearchRegistry あいまい検索用の提出者の一覧
+func testSearchRegistry() *Registry {
+	r := &Registry{Companies: []Company{
+		{EdinetCode: "E02144", SecCode: "72030", Name: "トヨタ自動車株式会社", NameEnglish: "TOYOTA MOTOR CORPORATION", NameKana: "トヨタジドウシャカブシキガイシャ", Listed: true},
+		{EdinetCode: "E02143", SecCode: "62010", Name: "株式会社豊田自動織機", NameEnglish: "TOYOTA INDUSTRIES CORPORATION", NameKana: "カブシキガイシャトヨタジドウショッキ", Listed: true},
+		{EdinetCode: "E01777", SecCode: "67580", Name: "ソニーグループ株式会社", NameEnglish: "Sony Group Corporation", NameKana: "ソニーグループカブシキガイシャ", Listed: true},
+		{EdinetCode: "E31234", Name: "ソニー生命保険株式会社", NameEnglish: "Sony Life Insurance Co., Ltd.", NameKana: "ソニーセイメイホケンカブシキガイシャ"},
+	}}
+	r.index()
+	return r
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"トヨタ自動車株式会社", "トヨタ自動車"},
+		{"（株）ＴＯＹＯＴＡ　自動車", "toyota自動車"},
+		{"とよた", "トヨタ"},
+		{"Sony Life Insurance Co., Ltd.", "sonylifeinsurance"},
+		{"TOYOTA MOTOR CORPORATION", "toyotamotor"},
+	}
+	for _, tt := range tests {
+		if got := normalizeName(tt.name); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, 期待=%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Search(t *testing.T) {
+	r := testSearchRegistry()
+
+	matches := r.Search("トヨタ", 0)
+	if len(matches) != 2 {
+		t.Fatalf("候補数不一致: 期待=2, 実際=%d: %v", len(matches), matches)
+	}
+	// ヨミの「カブシキガイシャ」は無視するため、どちらも先頭一致
+	for _, m := range matches {
+		if m.Score != ScorePrefix {
+			t.Errorf("先頭一致になるべきです: %+v", m)
+		}
+	}
+	if matches := r.Search("Motor", 0); len(matches) != 1 || matches[0].Company.EdinetCode != "E02144" || matches[0].Score != ScoreContains {
+		t.Errorf("提出者名の一部が一致すべきです: %v", matches)
+	}
+
+	if matches := r.Search("sony group", 0); len(matches) == 0 || matches[0].Company.EdinetCode != "E01777" || matches[0].Score != ScoreExact {
+		t.Errorf("英字の提出者名が一致すべきです: %v", matches)
+	}
+	if matches := r.Search("ソニー", 1); len(matches) != 1 || !matches[0].Company.Listed {
+		t.Errorf("同じスコアの場合は上場会社を優先し、件数を制限すべきです: %v", matches)
+	}
+	// 表記の揺れ（送り仮名の違い等）は類似度で候補にする
+	if matches := r.Search("トヨタ自動車工業", 0); len(matches) == 0 || matches[0].Company.EdinetCode != "E02144" {
+		t.Errorf("類似した提出者名が候補になるべきです: %v", matches)
+	}
+	if matches := r.Search("任天堂", 0); len(matches) != 0 {
+		t.Errorf("一致しない場合は候補なしになるべきです: %v", matches)
+	}
+	if matches := r.Search("株式会社", 0); matches != nil {
+		t.Errorf("正規化して空になる検索語は候補なしになるべきです: %v", matches)
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := testSearchRegistry()
+
+	for _, key := range []string{"E01777", "6758", "sony group", "ソニーグループ"} {
+		if c, _, ok := r.Resolve(key, 5); !ok || c.EdinetCode != "E01777" {
+			t.Errorf("%q: E01777に決まるべきです: %+v", key, c)
+		}
+	}
+	if c, _, ok := r.Resolve("豊田自動織機", 5); !ok || c.EdinetCode != "E02143" {
+		t.Errorf("候補が1社の場合は決まるべきです: %+v", c)
+	}
+
+	// 一部の一致・類似度だけの候補は1社でも決めずに確認する
+	for _, key := range []string{"Motor", "トヨタ自動車工業"} {
+		if _, candidates, ok := r.Resolve(key, 5); ok || len(candidates) != 1 || candidates[0].Company.EdinetCode != "E02144" {
+			t.Errorf("%q: 候補として返すべきです: %v, %t", key, candidates, ok)
+		}
+	}
+
+	_, candidates, ok := r.Resolve("ソニー", 5)
+	if ok {
+		t.Fatal("候補が複数の場合は決まらないべきです")
+	}
+	if len(candidates) != 2 || candidates[0].Company.EdinetCode != "E01777" {
+		t.Errorf("候補が不正です: %v", candidates)
+	}
+}
+
+func TestRegistry_ResolveKey(t *testing.T) {
+	r := testSearchRegistry()
+
+	if c, err := r.ResolveKey("6758", false, 5); err != nil || c.EdinetCode != "E01777" {
+		t.Errorf("証券コードで決まるべきです: %+v, %v", c, err)
+	}
+	if _, err := r.ResolveKey("ソニーグループ株式会社x", false, 5); err == nil {
+		t.Error("提出者名で検索しない場合、一致しない名前はエラーになるべきです")
+	}
+	// 一覧にないコードは形式から判断しない
+	if _, err := r.ResolveKey("9999", true, 5); err == nil {
+		t.Error("一覧にない証券コードはエラーになるべきです")
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := r.ResolveKey("ソニー", true, 5); !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Errorf("候補が複数の場合はAmbiguousErrorになるべきです: %v", err)
+	}
+
+	// 一覧がない場合のみコードの形式から判断する
+	var none *Registry
+	tests := []struct {
+		key        string
+		byName     bool
+		wantEdinet string
+		wantSec    string
+	}{
+		{"e02144", true, "E02144", ""},
+		{"7203", true, "", "72030"},
+		{"72030", true, "", "72030"},
+		{"123", false, "", "123"},
+	}
+	for _, tt := range tests {
+		c, err := none.ResolveKey(tt.key, tt.byName, 5)
+		if err != nil || c.EdinetCode != tt.wantEdinet || c.SecCode != tt.wantSec {
+			t.Errorf("%q: %+v, %v, 期待=%q %q", tt.key, c, err, tt.wantEdinet, tt.wantSec)
+		}
+	}
+	if _, err := none.ResolveKey("ソニー", true, 5); err == nil {
+		t.Error("一覧がない場合、提出者名はエラーになるべきです")
+	}
+}
@@ -0,0 +1,222 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"edinet-api-test/internal/models"
+)
+
+// 記録の種類
+const (
+	KindRun = "run" // 実行の開始（設定の確認用）
+	KindDay = "day" // ある日の文書一覧の取得結果
+	KindDoc = "doc" // ある書類の処理結果
+)
+
+// 処理結果
+const (
+	StatusDone    = "done"    // 取得・処理して出力した
+	StatusSkipped = "skipped" // 対象外と判定した（出力する行はない）
+	StatusFailed  = "failed"  // 失敗した（再開時に再試行する）
+)
+
+// Record ジャーナルの1行
+type Record struct {
+	Kind   string           `json:"kind"`
+	Key    string           `json:"key,omitempty"`    // run: 実行の設定（異なる設定で再開しないため）
+	Date   string           `json:"date,omitempty"`   // day・doc: 文書一覧の日付
+	DocID  string           `json:"docID,omitempty"`  // doc: 書類管理番号
+	Status string           `json:"status,omitempty"` // day・doc: 処理結果
+	Error  string           `json:"error,omitempty"`
+	Docs   []models.DocInfo `json:"docs,omitempty"`   // day: 絞り込んだ後の書類
+	Offset int64            `json:"offset,omitempty"` // doc: この書類の行を書き込んだ後の出力ファイルのサイズ
+	At     time.Time        `json:"at"`
+}
+
+// Journal 日・書類ごとの処理結果を記録するJSONLファイル
+//
+// 書類の記録には、その書類の行を出力ファイルに書き込んだ（フラッシュした）後のファイルサイズを
+// 含める。再開時は出力ファイルを最後に記録したサイズまで切り詰めるため、記録する前に
+// 中断した書類の行が残って重複することはない。
+type Journal struct {
+	file   *os.File
+	days   map[string]Record
+	docs   map[string]Record
+	offset int64
+	now    func() time.Time
+}
+
+// Open ジャーナルを開く
+//
+// resumeがfalseの場合は新しく作成する。trueの場合は既存の記録を読み込み、keyが前回と異なれば
+// エラーにする（ファイルがない場合は新しく作成する）。書きかけの最終行は切り捨てる。
+func Open(path, key string, resume bool) (*Journal, error) {
+	j := &Journal{
+		days: make(map[string]Record),
+		docs: make(map[string]Record),
+		now:  time.Now,
+	}
+
+	flags := os.O_CREATE | os.O_RDWR | os.O_TRUNC
+	if resume {
+		flags = os.O_CREATE | os.O_RDWR
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ジャーナルのオープンエラー: %v", err)
+	}
+	j.file = file
+
+	size, err := j.load(key)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("ジャーナルの切り詰めエラー: %v", err)
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("ジャーナルのシークエラー: %v", err)
+	}
+
+	if size == 0 {
+		if err := j.append(Record{Kind: KindRun, Key: key}); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// load 既存の記録を読み込み、完全な行の末尾の位置を返す
+func (j *Journal) load(key string) (int64, error) {
+	data, err := io.ReadAll(j.file)
+	if err != nil {
+		return 0, fmt.Errorf("ジャーナルの読み込みエラー: %v", err)
+	}
+
+	var size int64
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// 改行で終わらない最終行は書き込み中に中断したもの
+			break
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return 0, fmt.Errorf("ジャーナルが不正です (%d bytes目): %v", size, err)
+		}
+		size += int64(len(line))
+
+		switch r.Kind {
+		case KindRun:
+			if r.Key != key {
+				return 0, fmt.Errorf("ジャーナルの設定が今回と異なるため再開できません（前回: %s、今回: %s）", r.Key, key)
+			}
+		case KindDay:
+			j.days[r.Date] = r
+		case KindDoc:
+			j.docs[r.DocID] = r
+			if r.Status == StatusDone {
+				j.offset = r.Offset
+			}
+		}
+	}
+	return size, nil
+}
+
+// Day 文書一覧を取得済みの日の記録
+func (j *Journal) Day(date string) (Record, bool) {
+	r, ok := j.days[date]
+	if !ok || r.Status != StatusDone {
+		return Record{}, false
+	}
+	return r, true
+}
+
+// Completed 書類を処理済み（出力済みか対象外）かどうか。失敗した書類はfalse
+func (j *Journal) Completed(docID string) bool {
+	r, ok := j.docs[docID]
+	return ok && (r.Status == StatusDone || r.Status == StatusSkipped)
+}
+
+// Counts 処理済みの日数・書類数・失敗した書類数
+func (j *Journal) Counts() (days, done, failed int) {
+	for _, r := range j.days {
+		if r.Status == StatusDone {
+			days++
+		}
+	}
+	for _, r := range j.docs {
+		switch r.Status {
+		case StatusDone, StatusSkipped:
+			done++
+		case StatusFailed:
+			failed++
+		}
+	}
+	return days, done, failed
+}
+
+// OutputSize 最後に記録した出力ファイルのサイズ（書類を出力していない場合は0）
+func (j *Journal) OutputSize() int64 {
+	return j.offset
+}
+
+// RecordDay 文書一覧の取得結果を記録（docsは絞り込んだ後の書類）
+func (j *Journal) RecordDay(date string, docs []models.DocInfo, err error) error {
+	r := Record{Kind: KindDay, Date: date, Status: StatusDone, Docs: docs}
+	if err != nil {
+		r = Record{Kind: KindDay, Date: date, Status: StatusFailed, Error: err.Error()}
+	}
+	return j.append(r)
+}
+
+// RecordDoc 書類の処理結果を記録（offsetはStatusDoneの場合の出力ファイルのサイズ）
+func (j *Journal) RecordDoc(date, docID, status string, offset int64, err error) error {
+	r := Record{Kind: KindDoc, Date: date, DocID: docID, Status: status}
+	if status == StatusDone {
+		r.Offset = offset
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return j.append(r)
+}
+
+// append 1行を書き込む（1回のWriteで書き込むため、中断しても行の途中までしか残らない）
+func (j *Journal) append(r Record) error {
+	r.At = j.now().UTC()
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("ジャーナルの作成エラー: %v", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("ジャーナルの書き込みエラー: %v", err)
+	}
+
+	switch r.Kind {
+	case KindDay:
+		j.days[r.Date] = r
+	case KindDoc:
+		j.docs[r.DocID] = r
+		if r.Status == StatusDone {
+			j.offset = r.Offset
+		}
+	}
+	return nil
+}
+
+// Close ジャーナルを閉じる
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
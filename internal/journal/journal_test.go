@@ -0,0 +1,117 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"edinet-api-test/internal/models"
+)
+
+func TestJournal_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+
+	j, err := Open(path, "start=2025-07-10", false)
+	if err != nil {
+		t.Fatalf("オープンエラー: %v", err)
+	}
+	docs := []models.DocInfo{{DocID: "S100AAAA"}, {DocID: "S100BBBB"}, {DocID: "S100CCCC"}}
+	if err := j.RecordDay("2025-07-10", docs, nil); err != nil {
+		t.Fatalf("記録エラー: %v", err)
+	}
+	j.RecordDay("2025-07-11", nil, errors.New("503"))
+	j.RecordDoc("2025-07-10", "S100AAAA", StatusDone, 1200, nil)
+	j.RecordDoc("2025-07-10", "S100BBBB", StatusSkipped, 0, nil)
+	j.RecordDoc("2025-07-10", "S100CCCC", StatusFailed, 0, errors.New("ZIPが壊れています"))
+	j.Close()
+
+	j, err = Open(path, "start=2025-07-10", true)
+	if err != nil {
+		t.Fatalf("再オープンエラー: %v", err)
+	}
+	defer j.Close()
+
+	day, ok := j.Day("2025-07-10")
+	if !ok || len(day.Docs) != 3 {
+		t.Errorf("取得済みの日の記録が読み込まれていません: %v", day)
+	}
+	if _, ok := j.Day("2025-07-11"); ok {
+		t.Error("失敗した日は取得済みとして扱わないべきです")
+	}
+	if !j.Completed("S100AAAA") || !j.Completed("S100BBBB") {
+		t.Error("出力済み・対象外の書類は処理済みとして扱うべきです")
+	}
+	if j.Completed("S100CCCC") {
+		t.Error("失敗した書類は再試行するべきです")
+	}
+	if j.OutputSize() != 1200 {
+		t.Errorf("OutputSize不一致: 期待=1200, 実際=%d", j.OutputSize())
+	}
+	if days, done, failed := j.Counts(); days != 1 || done != 2 || failed != 1 {
+		t.Errorf("Counts不一致: 日=%d, 処理済み=%d, 失敗=%d", days, done, failed)
+	}
+
+	// 再試行に成功した記録が優先される
+	j.RecordDoc("2025-07-10", "S100CCCC", StatusDone, 1800, nil)
+	if !j.Completed("S100CCCC") || j.OutputSize() != 1800 {
+		t.Error("再試行の結果が反映されていません")
+	}
+}
+
+func TestJournal_NewRunTruncates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+
+	j, _ := Open(path, "a", false)
+	j.RecordDoc("2025-07-10", "S100AAAA", StatusDone, 100, nil)
+	j.Close()
+
+	j, err := Open(path, "b", false)
+	if err != nil {
+		t.Fatalf("オープンエラー: %v", err)
+	}
+	defer j.Close()
+	if j.Completed("S100AAAA") || j.OutputSize() != 0 {
+		t.Error("新しい実行では前回の記録を使わないべきです")
+	}
+}
+
+func TestJournal_KeyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+
+	j, _ := Open(path, "code=72030", false)
+	j.Close()
+
+	if _, err := Open(path, "code=67580", true); err == nil {
+		t.Error("設定が異なる場合、エラーが発生すべきです")
+	}
+}
+
+func TestJournal_TruncatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+
+	j, _ := Open(path, "a", false)
+	j.RecordDoc("2025-07-10", "S100AAAA", StatusDone, 100, nil)
+	j.Close()
+
+	// 書き込み中に中断した行
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"kind":"doc","date":"2025-07-10","docID":"S100BB`)
+	f.Close()
+
+	j, err := Open(path, "a", true)
+	if err != nil {
+		t.Fatalf("再オープンエラー: %v", err)
+	}
+	j.RecordDoc("2025-07-10", "S100BBBB", StatusDone, 200, nil)
+	j.Close()
+
+	j, err = Open(path, "a", true)
+	if err != nil {
+		t.Fatalf("書きかけの行を切り捨てていません: %v", err)
+	}
+	defer j.Close()
+	if !j.Completed("S100AAAA") || !j.Completed("S100BBBB") || j.OutputSize() != 200 {
+		t.Error("記録が読み込まれていません")
+	}
+}
@@ -0,0 +1,166 @@
+package listcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"edinet-api-test/internal/logging"
+	"edinet-api-test/internal/models"
+	"edinet-api-test/internal/utils"
+)
+
+// 既定の更新方針
+const (
+	DefaultRecentDays    = 7                   // 直近この日数の一覧は毎回取得し直す
+	DefaultCheckInterval = 30 * 24 * time.Hour // それより前の一覧は、この間隔でメタデータを確認する
+)
+
+// jst EDINETの日付の基準のタイムゾーン
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Lister 文書一覧を取得するAPI（api.EdinetAPI）
+type Lister interface {
+	GetDocuments(ctx context.Context, date time.Time) (*models.DocumentListResponse, error)
+	GetDocumentsMetadata(ctx context.Context, date time.Time) (*models.Metadata, error)
+}
+
+// entry キャッシュしたある日の文書一覧
+type entry struct {
+	Date      string                      `json:"date"`
+	FetchedAt time.Time                   `json:"fetchedAt"` // 一覧を取得した日時
+	CheckedAt time.Time                   `json:"checkedAt"` // メタデータで変更がないことを最後に確認した日時
+	Response  models.DocumentListResponse `json:"response"`
+}
+
+// Cache 日ごとの文書一覧をディレクトリに保存し、変更がない日はAPIを呼ばずに返す
+//
+// 直近RecentDays日の一覧は提出・訂正・取下げで変わるため毎回取得する。それより前の日は、
+// 前回の確認からCheckInterval以上経っていればメタデータ（type=1）だけを取得し、
+// 件数かprocessDateTimeが変わっていれば一覧を取得し直す。
+type Cache struct {
+	dir           string
+	lister        Lister
+	RecentDays    int
+	CheckInterval time.Duration
+	Force         bool // trueの場合はキャッシュを使わずに全て取得し直す
+	now           func() time.Time
+}
+
+// New 新しい文書一覧のキャッシュを作成
+func New(dir string, lister Lister) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("キャッシュディレクトリの作成エラー: %v", err)
+	}
+	return &Cache{
+		dir:           dir,
+		lister:        lister,
+		RecentDays:    DefaultRecentDays,
+		CheckInterval: DefaultCheckInterval,
+		now:           time.Now,
+	}, nil
+}
+
+// GetDocuments 指定日の文書一覧を取得（キャッシュが使える場合はAPIを呼ばない）
+func (c *Cache) GetDocuments(ctx context.Context, date time.Time) (*models.DocumentListResponse, error) {
+	dateStr := date.Format("2006-01-02")
+	logger := logging.FromContext(ctx).With(logging.KeyDay, dateStr)
+	cached, err := c.load(dateStr)
+	if err != nil {
+		logger.Warn("キャッシュを読み込めないため取得し直します", "error", err)
+	}
+
+	now := c.now()
+	switch {
+	case c.Force || cached == nil || c.isRecent(date, now):
+		return c.fetch(ctx, date)
+	case now.Sub(cached.CheckedAt) < c.CheckInterval:
+		logger.Debug("キャッシュから文書一覧を読み込み")
+		return &cached.Response, nil
+	}
+
+	// メタデータで変更がないか確認
+	metadata, err := c.lister.GetDocumentsMetadata(ctx, date)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.Warn("メタデータを確認できないためキャッシュを使います", "error", err)
+		return &cached.Response, nil
+	}
+	if metadata.ProcessDateTime != cached.Response.Metadata.ProcessDateTime ||
+		metadata.ResultSet.Count != cached.Response.Metadata.ResultSet.Count {
+		logger.Info("文書一覧が更新されています", "cached", cached.Response.Metadata.ProcessDateTime, "current", metadata.ProcessDateTime)
+		return c.fetch(ctx, date)
+	}
+
+	cached.CheckedAt = now
+	if err := c.save(cached); err != nil {
+		return nil, err
+	}
+	logger.Debug("キャッシュから文書一覧を読み込み（変更なし）")
+	return &cached.Response, nil
+}
+
+// isRecent 毎回取得し直す直近の日かどうか（日付は日本時間の暦日として比べる）
+func (c *Cache) isRecent(date, now time.Time) bool {
+	y, m, d := now.In(jst).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(today.AddDate(0, 0, -c.RecentDays))
+}
+
+// fetch 一覧を取得してキャッシュに保存
+func (c *Cache) fetch(ctx context.Context, date time.Time) (*models.DocumentListResponse, error) {
+	docList, err := c.lister.GetDocuments(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	e := &entry{
+		Date:      date.Format("2006-01-02"),
+		FetchedAt: now,
+		CheckedAt: now,
+		Response:  *docList,
+	}
+	if err := c.save(e); err != nil {
+		return nil, err
+	}
+	return docList, nil
+}
+
+// load キャッシュを読み込む（ない場合はnil）
+func (c *Cache) load(dateStr string) (*entry, error) {
+	data, err := os.ReadFile(c.path(dateStr))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// save キャッシュを保存
+func (c *Cache) save(e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("キャッシュの作成エラー: %v", err)
+	}
+	if err := utils.WriteFileAtomic(c.path(e.Date), data); err != nil {
+		return fmt.Errorf("キャッシュの保存エラー: %v", err)
+	}
+	return nil
+}
+
+// path 日付ごとのキャッシュファイルのパス
+func (c *Cache) path(dateStr string) string {
+	return filepath.Join(c.dir, dateStr+".json")
+}
@@ -0,0 +1,136 @@
+package listcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"edinet-api-test/internal/models"
+)
+
+// fakeLister 呼び出し回数を数えるテスト用のAPI
+type fakeLister struct {
+	processDateTime string
+	count           int
+	listCalls       int
+	metadataCalls   int
+	metadataErr     error
+}
+
+func (f *fakeLister) GetDocuments(ctx context.Context, date time.Time) (*models.DocumentListResponse, error) {
+	f.listCalls++
+	resp := &models.DocumentListResponse{
+		Metadata: models.Metadata{ProcessDateTime: f.processDateTime, ResultSet: models.ResultSet{Count: f.count}},
+	}
+	for i := 0; i < f.count; i++ {
+		resp.Results = append(resp.Results, models.DocInfo{DocID: "S100ABCD", SeqNumber: i + 1})
+	}
+	return resp, nil
+}
+
+func (f *fakeLister) GetDocumentsMetadata(ctx context.Context, date time.Time) (*models.Metadata, error) {
+	f.metadataCalls++
+	if f.metadataErr != nil {
+		return nil, f.metadataErr
+	}
+	return &models.Metadata{ProcessDateTime: f.processDateTime, ResultSet: models.ResultSet{Count: f.count}}, nil
+}
+
+func newTestCache(t *testing.T, lister Lister, now *time.Time) *Cache {
+	c, err := New(t.TempDir(), lister)
+	if err != nil {
+		t.Fatalf("キャッシュ作成エラー: %v", err)
+	}
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+var oldDay = time.Date(2020, 6, 25, 0, 0, 0, 0, time.UTC)
+
+func TestCache_UsesCacheForPastDays(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, jst)
+	lister := &fakeLister{processDateTime: "2020-06-25 18:00", count: 2}
+	c := newTestCache(t, lister, &now)
+
+	for i := 0; i < 3; i++ {
+		docList, err := c.GetDocuments(context.Background(), oldDay)
+		if err != nil {
+			t.Fatalf("取得エラー: %v", err)
+		}
+		if len(docList.Results) != 2 {
+			t.Errorf("文書数不一致: 期待=2, 実際=%d", len(docList.Results))
+		}
+	}
+	if lister.listCalls != 1 || lister.metadataCalls != 0 {
+		t.Errorf("呼び出し回数不一致: 一覧=%d, メタデータ=%d", lister.listCalls, lister.metadataCalls)
+	}
+}
+
+func TestCache_RecentDaysAlwaysFetched(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, jst)
+	lister := &fakeLister{count: 1}
+	c := newTestCache(t, lister, &now)
+
+	recent := time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC)
+	c.GetDocuments(context.Background(), recent)
+	c.GetDocuments(context.Background(), recent)
+	if lister.listCalls != 2 {
+		t.Errorf("直近の日は毎回取得するべきです: %d回", lister.listCalls)
+	}
+}
+
+func TestCache_ChangeDetection(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, jst)
+	lister := &fakeLister{processDateTime: "2020-06-25 18:00", count: 2}
+	c := newTestCache(t, lister, &now)
+	c.GetDocuments(context.Background(), oldDay)
+
+	// 確認間隔が過ぎたらメタデータを確認し、変更がなければ一覧は取得しない
+	now = now.Add(c.CheckInterval)
+	c.GetDocuments(context.Background(), oldDay)
+	if lister.listCalls != 1 || lister.metadataCalls != 1 {
+		t.Errorf("呼び出し回数不一致: 一覧=%d, メタデータ=%d", lister.listCalls, lister.metadataCalls)
+	}
+
+	// 確認した日時を保存するので、次の確認間隔までは確認しない
+	c.GetDocuments(context.Background(), oldDay)
+	if lister.metadataCalls != 1 {
+		t.Errorf("確認間隔内はメタデータを確認しないべきです: %d回", lister.metadataCalls)
+	}
+
+	// 取下げ等で更新された場合は取得し直す
+	now = now.Add(c.CheckInterval)
+	lister.processDateTime = "2025-08-01 09:00"
+	c.GetDocuments(context.Background(), oldDay)
+	if lister.listCalls != 2 {
+		t.Errorf("更新された一覧を取得し直すべきです: %d回", lister.listCalls)
+	}
+}
+
+func TestCache_MetadataErrorFallsBack(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, jst)
+	lister := &fakeLister{count: 1}
+	c := newTestCache(t, lister, &now)
+	c.GetDocuments(context.Background(), oldDay)
+
+	now = now.Add(c.CheckInterval)
+	lister.metadataErr = errors.New("503")
+	docList, err := c.GetDocuments(context.Background(), oldDay)
+	if err != nil || len(docList.Results) != 1 {
+		t.Errorf("メタデータを確認できない場合はキャッシュを使うべきです: %v", err)
+	}
+}
+
+func TestCache_Force(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, jst)
+	lister := &fakeLister{count: 1}
+	c := newTestCache(t, lister, &now)
+	c.Force = true
+
+	c.GetDocuments(context.Background(), oldDay)
+	c.GetDocuments(context.Background(), oldDay)
+	if lister.listCalls != 2 {
+		t.Errorf("Forceの場合は毎回取得するべきです: %d回", lister.listCalls)
+	}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// ログの形式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// 書類ごとのログの属性名
+const (
+	KeyDocID   = "docID"
+	KeySecCode = "secCode"
+	KeyDay     = "day"
+)
+
+// Level -v・-qの指定からログレベルを決める（-vはデバッグ、-qは警告以上のみ）
+func Level(verbose, quiet bool) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// New ログの出力先・レベル・形式（text / json）からロガーを作成
+func New(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("不明なログの形式です: %s（text / json）", format)
+}
+
+// loggerKey contextにロガーを保存するキー
+type loggerKey struct{}
+
+// With 属性を追加したロガーをctxに保存する（書類・日ごとの処理で、API・解析のログにも属性を付ける）
+func With(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).With(args...))
+}
+
+// FromContext ctxに保存したロガー（ない場合はslog.Default）
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
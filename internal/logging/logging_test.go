@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		verbose, quiet bool
+		want           slog.Level
+	}{
+		{false, false, slog.LevelInfo},
+		{true, false, slog.LevelDebug},
+		{false, true, slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		if got := Level(tt.verbose, tt.quiet); got != tt.want {
+			t.Errorf("Level(%t, %t) = %v, 期待=%v", tt.verbose, tt.quiet, got, tt.want)
+		}
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, slog.LevelInfo, FormatJSON)
+	if err != nil {
+		t.Fatalf("作成エラー: %v", err)
+	}
+	logger.Debug("表示しない")
+	logger.Info("文書一覧", KeyDay, "2025-06-20", "count", 3)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("レベル未満のログは出力しないべきです: %q", buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("JSONではありません: %v", err)
+	}
+	if record["msg"] != "文書一覧" || record[KeyDay] != "2025-06-20" || record["count"] != float64(3) {
+		t.Errorf("ログの内容が不正です: %v", record)
+	}
+
+	if _, err := New(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Error("不明な形式の場合、エラーが発生すべきです")
+	}
+}
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&buf, slog.LevelInfo, FormatText)
+
+	ctx := context.WithValue(context.Background(), loggerKey{}, logger)
+	ctx = With(ctx, KeyDay, "2025-06-20")
+	ctx = With(ctx, KeyDocID, "S100ABCD")
+	FromContext(ctx).Info("処理")
+
+	if out := buf.String(); !strings.Contains(out, "day=2025-06-20") || !strings.Contains(out, "docID=S100ABCD") {
+		t.Errorf("ctxの属性がログに付くべきです: %s", out)
+	}
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("ctxにロガーがない場合はslog.Defaultを返すべきです")
+	}
+}
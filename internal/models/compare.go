@@ -0,0 +1,78 @@
+package models
+
+import (
+	"math/big"
+	"sort"
+)
+
+// FactDiff 2つのインスタンス間で一致しない数値ファクト
+type FactDiff struct {
+	Name       string
+	ContextRef string
+	UnitRef    string
+	Left       string // 左側の値（存在しない場合は空文字列）
+	Right      string // 右側の値（存在しない場合は空文字列）
+}
+
+// CompareInstances 2つのインスタンスの数値ファクトを比較し、差異を返す
+//
+// 要素名・コンテキスト・単位が同じファクトを数値として比較する。テキストブロック等の
+// 非数値ファクトは表現（HTMLのエスケープや空白）が形式ごとに異なるため比較しない。
+// 結果は要素名・コンテキストID順に並べる。
+func CompareInstances(left, right *XBRLInstance) []FactDiff {
+	l := numericFacts(left)
+	r := numericFacts(right)
+
+	var diffs []FactDiff
+	for key, lf := range l {
+		rf, ok := r[key]
+		if !ok {
+			diffs = append(diffs, FactDiff{Name: lf.Name, ContextRef: lf.ContextRef, UnitRef: lf.UnitRef, Left: lf.Value})
+			continue
+		}
+		if !sameValue(lf, rf) {
+			diffs = append(diffs, FactDiff{Name: lf.Name, ContextRef: lf.ContextRef, UnitRef: lf.UnitRef, Left: lf.Value, Right: rf.Value})
+		}
+	}
+	for key, rf := range r {
+		if _, ok := l[key]; !ok {
+			diffs = append(diffs, FactDiff{Name: rf.Name, ContextRef: rf.ContextRef, UnitRef: rf.UnitRef, Right: rf.Value})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Name != diffs[j].Name {
+			return diffs[i].Name < diffs[j].Name
+		}
+		return diffs[i].ContextRef < diffs[j].ContextRef
+	})
+	return diffs
+}
+
+// numericFacts 数値ファクトを要素名・コンテキスト・単位のキーで返す（重複は先勝ち）
+func numericFacts(x *XBRLInstance) map[string]Fact {
+	facts := make(map[string]Fact)
+	for _, f := range x.Facts {
+		if !f.IsNumeric() {
+			continue
+		}
+		key := f.Name + "|" + f.ContextRef + "|" + f.UnitRef
+		if _, ok := facts[key]; !ok {
+			facts[key] = f
+		}
+	}
+	return facts
+}
+
+// sameValue 2つの数値ファクトが同じ値か（表記の違いは問わない）
+func sameValue(a, b Fact) bool {
+	if a.Nil || b.Nil {
+		return a.Nil == b.Nil
+	}
+	ra, okA := new(big.Rat).SetString(a.Value)
+	rb, okB := new(big.Rat).SetString(b.Value)
+	if !okA || !okB {
+		return a.Value == b.Value
+	}
+	return ra.Cmp(rb) == 0
+}
@@ -0,0 +1,47 @@
+package models
+
+import "testing"
+
+func TestCompareInstances(t *testing.T) {
+	left := NewXBRLInstance()
+	left.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1000000"})
+	left.AddFact(Fact{Name: "jppfs_cor:OperatingIncome", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "100"})
+	left.AddFact(Fact{Name: "jppfs_cor:OrdinaryIncome", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "90"})
+	left.AddFact(Fact{Name: "jpcrp_cor:BusinessRisksTextBlock", ContextRef: "FilingDateInstant", Value: "<p>リスク</p>"})
+
+	right := NewXBRLInstance()
+	right.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1000000.0"})
+	right.AddFact(Fact{Name: "jppfs_cor:OperatingIncome", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "110"})
+	right.AddFact(Fact{Name: "jppfs_cor:ProfitLoss", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "50"})
+	right.AddFact(Fact{Name: "jpcrp_cor:BusinessRisksTextBlock", ContextRef: "FilingDateInstant", Value: "リスク"})
+
+	diffs := CompareInstances(left, right)
+	expected := []FactDiff{
+		{Name: "jppfs_cor:OperatingIncome", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Left: "100", Right: "110"},
+		{Name: "jppfs_cor:OrdinaryIncome", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Left: "90"},
+		{Name: "jppfs_cor:ProfitLoss", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Right: "50"},
+	}
+
+	if len(diffs) != len(expected) {
+		t.Fatalf("差異の件数が不一致: 期待=%d, 実際=%d (%v)", len(expected), len(diffs), diffs)
+	}
+	for i, want := range expected {
+		if diffs[i] != want {
+			t.Errorf("差異[%d]不一致: 期待=%+v, 実際=%+v", i, want, diffs[i])
+		}
+	}
+}
+
+func TestCompareInstances_Identical(t *testing.T) {
+	left := NewXBRLInstance()
+	left.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "-500"})
+	left.AddFact(Fact{Name: "jppfs_cor:ProfitLoss", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Nil: true})
+
+	right := NewXBRLInstance()
+	right.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "-500"})
+	right.AddFact(Fact{Name: "jppfs_cor:ProfitLoss", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Nil: true})
+
+	if diffs := CompareInstances(left, right); len(diffs) != 0 {
+		t.Errorf("差異がないはずです: %v", diffs)
+	}
+}
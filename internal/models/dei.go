@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DEI 提出者・書類の基本情報（jpdei_cor）
+type DEI struct {
+	EDINETCode                 string
+	SecurityCode               string
+	FilerNameJa                string
+	FilerNameEn                string
+	DocumentType               string
+	AccountingStandards        string
+	Consolidated               string // WhetherConsolidatedFinancialStatementsArePreparedDEI（true / false）
+	TypeOfCurrentPeriod        string // FY / HY / Q1 / Q2 / Q3
+	CurrentFiscalYearStartDate string
+	CurrentFiscalYearEndDate   string
+	CurrentPeriodEndDate       string
+	AmendmentFlag              string
+}
+
+// DEI インスタンスのjpdei_corファクトからDEIを組み立てる
+func (x *XBRLInstance) DEI() DEI {
+	return DEI{
+		EDINETCode:                 x.deiValue("EDINETCodeDEI"),
+		SecurityCode:               x.deiValue("SecurityCodeDEI"),
+		FilerNameJa:                x.deiValue("FilerNameInJapaneseDEI"),
+		FilerNameEn:                x.deiValue("FilerNameInEnglishDEI"),
+		DocumentType:               x.deiValue("DocumentTypeDEI"),
+		AccountingStandards:        x.deiValue("AccountingStandardsDEI"),
+		Consolidated:               x.deiValue("WhetherConsolidatedFinancialStatementsArePreparedDEI"),
+		TypeOfCurrentPeriod:        x.deiValue("TypeOfCurrentPeriodDEI"),
+		CurrentFiscalYearStartDate: x.deiValue("CurrentFiscalYearStartDateDEI"),
+		CurrentFiscalYearEndDate:   x.deiValue("CurrentFiscalYearEndDateDEI"),
+		CurrentPeriodEndDate:       x.deiValue("CurrentPeriodEndDateDEI"),
+		AmendmentFlag:              x.deiValue("AmendmentFlagDEI"),
+	}
+}
+
+// deiValue DEI要素の最初の空でない値を返す
+func (x *XBRLInstance) deiValue(name string) string {
+	for _, f := range x.FactsByName("jpdei_cor:" + name) {
+		if f.Value != "" {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// IsConsolidated 連結財務諸表を作成しているか（記載がない場合はtrue）
+func (d DEI) IsConsolidated() bool {
+	return !strings.EqualFold(d.Consolidated, "false")
+}
+
+// FiscalYear 会計年度（事業年度の開始年）を返す（不明な場合は0）
+func (d DEI) FiscalYear() int {
+	start, err := time.Parse("2006-01-02", d.CurrentFiscalYearStartDate)
+	if err != nil {
+		return 0
+	}
+	return start.Year()
+}
+
+// FiscalPeriodLabel 会計期間の表示名（例: 2024年度、2024年度第1四半期、2024年度中間期）
+// 判定に必要なDEIがない場合は空文字列を返す
+func (d DEI) FiscalPeriodLabel() string {
+	year := d.FiscalYear()
+	if year == 0 {
+		return ""
+	}
+
+	switch d.TypeOfCurrentPeriod {
+	case "FY":
+		return fmt.Sprintf("%d年度", year)
+	case "HY":
+		return fmt.Sprintf("%d年度中間期", year)
+	case "Q1", "Q2", "Q3":
+		return fmt.Sprintf("%d年度第%s四半期", year, d.TypeOfCurrentPeriod[1:])
+	default:
+		return ""
+	}
+}
+
+// IsInterimPeriod 中間期（半期報告書、または四半期報告書の第2四半期）かどうか
+func (d DEI) IsInterimPeriod() bool {
+	return d.TypeOfCurrentPeriod == "HY" || d.TypeOfCurrentPeriod == "Q2"
+}
+
+// InterimPeriodLabel 中間期の表示名（例: 2025年度中間期）
+// 第2四半期の四半期報告書も中間期として表示し、中間期でない場合は空文字列を返す
+func (d DEI) InterimPeriodLabel() string {
+	year := d.FiscalYear()
+	if year == 0 || !d.IsInterimPeriod() {
+		return ""
+	}
+	return fmt.Sprintf("%d年度中間期", year)
+}
+
+// FiscalYearEndMonth 決算月（例: 3月）
+func (d DEI) FiscalYearEndMonth() string {
+	return monthLabel(d.CurrentFiscalYearEndDate)
+}
+
+// FiscalYearStartMonth 年度開始月（例: 4月）
+func (d DEI) FiscalYearStartMonth() string {
+	return monthLabel(d.CurrentFiscalYearStartDate)
+}
+
+// monthLabel 日付文字列から「N月」を返す
+func monthLabel(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d月", int(t.Month()))
+}
@@ -0,0 +1,99 @@
+package models
+
+import "testing"
+
+// newDEIInstance DEIのみを持つテスト用インスタンスを作成
+func newDEIInstance(values map[string]string) *XBRLInstance {
+	x := NewXBRLInstance()
+	x.AddContext(&Context{ID: "FilingDateInstant", Period: Period{Type: PeriodInstant, Instant: "2025-06-25"}})
+	for name, value := range values {
+		x.AddFact(Fact{Name: "jpdei_cor:" + name, ContextRef: "FilingDateInstant", Value: value})
+	}
+	return x
+}
+
+func TestXBRLInstance_DEI(t *testing.T) {
+	x := newDEIInstance(map[string]string{
+		"EDINETCodeDEI":          "E02144",
+		"SecurityCodeDEI":        "72030",
+		"AccountingStandardsDEI": "IFRS",
+		"WhetherConsolidatedFinancialStatementsArePreparedDEI": "true",
+		"TypeOfCurrentPeriodDEI":                               "FY",
+		"CurrentFiscalYearStartDateDEI":                        "2024-04-01",
+		"CurrentFiscalYearEndDateDEI":                          "2025-03-31",
+		"CurrentPeriodEndDateDEI":                              "2025-03-31",
+	})
+
+	dei := x.DEI()
+	if dei.EDINETCode != "E02144" || dei.SecurityCode != "72030" || dei.AccountingStandards != "IFRS" {
+		t.Errorf("DEI不一致: %+v", dei)
+	}
+	if !dei.IsConsolidated() {
+		t.Error("連結財務諸表ありと判定されるべきです")
+	}
+	if dei.FiscalPeriodLabel() != "2024年度" {
+		t.Errorf("会計期間不一致: 期待=2024年度, 実際=%s", dei.FiscalPeriodLabel())
+	}
+	if dei.FiscalYearEndMonth() != "3月" || dei.FiscalYearStartMonth() != "4月" {
+		t.Errorf("決算月・年度開始月不一致: %s / %s", dei.FiscalYearEndMonth(), dei.FiscalYearStartMonth())
+	}
+}
+
+func TestDEI_FiscalPeriodLabel(t *testing.T) {
+	testCases := []struct {
+		periodType string
+		start      string
+		expected   string
+	}{
+		// 12月決算の会社は暦年が会計年度になる
+		{"FY", "2024-01-01", "2024年度"},
+		{"Q1", "2024-04-01", "2024年度第1四半期"},
+		{"Q3", "2023-07-01", "2023年度第3四半期"},
+		{"HY", "2024-04-01", "2024年度中間期"},
+		// 判定できない場合は空文字列
+		{"", "2024-04-01", ""},
+		{"FY", "", ""},
+	}
+
+	for _, tc := range testCases {
+		dei := DEI{TypeOfCurrentPeriod: tc.periodType, CurrentFiscalYearStartDate: tc.start}
+		if result := dei.FiscalPeriodLabel(); result != tc.expected {
+			t.Errorf("%s/%s: 期待=%s, 実際=%s", tc.periodType, tc.start, tc.expected, result)
+		}
+	}
+}
+
+func TestDEI_InterimPeriodLabel(t *testing.T) {
+	testCases := []struct {
+		periodType string
+		start      string
+		expected   string
+	}{
+		{"HY", "2025-04-01", "2025年度中間期"},
+		// 2024年3月以前の第2四半期報告書も同じ中間期として扱う
+		{"Q2", "2023-04-01", "2023年度中間期"},
+		{"Q1", "2023-04-01", ""},
+		{"FY", "2023-04-01", ""},
+		{"HY", "", ""},
+	}
+
+	for _, tc := range testCases {
+		dei := DEI{TypeOfCurrentPeriod: tc.periodType, CurrentFiscalYearStartDate: tc.start}
+		if result := dei.InterimPeriodLabel(); result != tc.expected {
+			t.Errorf("%s/%s: 期待=%s, 実際=%s", tc.periodType, tc.start, tc.expected, result)
+		}
+	}
+}
+
+func TestXBRLInstance_HasConsolidatedStatements(t *testing.T) {
+	if !NewXBRLInstance().HasConsolidatedStatements() {
+		t.Error("DEIがない場合は連結として扱うべきです")
+	}
+
+	x := newDEIInstance(map[string]string{
+		"WhetherConsolidatedFinancialStatementsArePreparedDEI": "false",
+	})
+	if x.HasConsolidatedStatements() {
+		t.Error("DEIがfalseの場合は連結財務諸表なしと判定されるべきです")
+	}
+}
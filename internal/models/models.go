@@ -1,19 +1,145 @@
 package models
 
-// DocInfo EDINET APIの文書情報
+import "time"
+
+// DocInfo EDINET APIの文書情報（書類一覧APIの結果1件分）
+//
+// 値がnullの項目は空文字列になる。フラグ類は"1"が有、"0"が無を表す。
 type DocInfo struct {
-	DocID       string `json:"docID"`
-	FilerName   string `json:"filerName"`
-	DocTypeCode string `json:"docTypeCode"`
-	XbrlFlag    string `json:"xbrlFlag"`
-	SecCode     string `json:"secCode"`
+	SeqNumber            int    `json:"seqNumber"`
+	DocID                string `json:"docID"`
+	EdinetCode           string `json:"edinetCode"`
+	SecCode              string `json:"secCode"`
+	JCN                  string `json:"JCN"`
+	FilerName            string `json:"filerName"`
+	FundCode             string `json:"fundCode"`
+	OrdinanceCode        string `json:"ordinanceCode"`
+	FormCode             string `json:"formCode"`
+	DocTypeCode          string `json:"docTypeCode"`
+	PeriodStart          string `json:"periodStart"`
+	PeriodEnd            string `json:"periodEnd"`
+	SubmitDateTime       string `json:"submitDateTime"` // YYYY-MM-DD hh:mm
+	DocDescription       string `json:"docDescription"`
+	IssuerEdinetCode     string `json:"issuerEdinetCode"`
+	SubjectEdinetCode    string `json:"subjectEdinetCode"`
+	SubsidiaryEdinetCode string `json:"subsidiaryEdinetCode"`
+	CurrentReportReason  string `json:"currentReportReason"`
+	ParentDocID          string `json:"parentDocID"`
+	OpeDateTime          string `json:"opeDateTime"`
+	WithdrawalStatus     string `json:"withdrawalStatus"`
+	DocInfoEditStatus    string `json:"docInfoEditStatus"`
+	DisclosureStatus     string `json:"disclosureStatus"`
+	XbrlFlag             string `json:"xbrlFlag"`
+	PdfFlag              string `json:"pdfFlag"`
+	AttachDocFlag        string `json:"attachDocFlag"`
+	EnglishDocFlag       string `json:"englishDocFlag"`
+	CsvFlag              string `json:"csvFlag"`
+	LegalStatus          string `json:"legalStatus"`
+}
+
+// 取下区分（withdrawalStatus）
+const (
+	WithdrawalNone      = "0" // それ以外
+	WithdrawalNotice    = "1" // 取下書
+	WithdrawalWithdrawn = "2" // 取り下げられた書類
+)
+
+// IsWithdrawn 取下書、または取り下げられた書類かどうか
+func (d DocInfo) IsWithdrawn() bool {
+	return d.WithdrawalStatus == WithdrawalNotice || d.WithdrawalStatus == WithdrawalWithdrawn
+}
+
+// IsAmendment 訂正書類（親書類管理番号を持つ）かどうか
+func (d DocInfo) IsAmendment() bool {
+	return d.ParentDocID != ""
+}
+
+// HasXBRL XBRLがあるか
+func (d DocInfo) HasXBRL() bool { return d.XbrlFlag == "1" }
+
+// HasPDF PDFがあるか
+func (d DocInfo) HasPDF() bool { return d.PdfFlag == "1" }
+
+// HasAttachment 代替書面・添付文書があるか
+func (d DocInfo) HasAttachment() bool { return d.AttachDocFlag == "1" }
+
+// HasEnglish 英文ファイルがあるか
+func (d DocInfo) HasEnglish() bool { return d.EnglishDocFlag == "1" }
+
+// HasCSV XBRLから変換したCSVがあるか
+func (d DocInfo) HasCSV() bool { return d.CsvFlag == "1" }
+
+// SubmitDate 提出日（YYYY-MM-DD、提出日時がない場合は空文字列）
+func (d DocInfo) SubmitDate() string {
+	if len(d.SubmitDateTime) < len("2006-01-02") {
+		return ""
+	}
+	return d.SubmitDateTime[:len("2006-01-02")]
+}
+
+// QuarterFromPeriod 四半期報告書の期間（periodStart・periodEnd）から第何四半期かを推定（推定できない場合は0）
+//
+// 期首からの累計（6か月・9か月）の場合は期間の長さから、3か月の場合は決算月（fiscalYearEndMonth、不明な場合は0）から判断する。
+func (d DocInfo) QuarterFromPeriod(fiscalYearEndMonth int) int {
+	start, err1 := time.Parse("2006-01-02", d.PeriodStart)
+	end, err2 := time.Parse("2006-01-02", d.PeriodEnd)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	next := end.AddDate(0, 0, 1)
+	months := (next.Year()-start.Year())*12 + int(next.Month()-start.Month())
+	if !start.AddDate(0, months, 0).Equal(next) {
+		return 0
+	}
+
+	switch months {
+	case 6:
+		return 2
+	case 9:
+		return 3
+	case 3:
+		if fiscalYearEndMonth < 1 || fiscalYearEndMonth > 12 {
+			return 0
+		}
+		elapsed := (int(end.Month()) - fiscalYearEndMonth + 12) % 12
+		if elapsed%3 != 0 {
+			return 0
+		}
+		return elapsed / 3
+	}
+	return 0
 }
 
 // DocumentListResponse EDINET APIの文書一覧レスポンス
 type DocumentListResponse struct {
-	Results []DocInfo `json:"results"`
+	Metadata Metadata  `json:"metadata"`
+	Results  []DocInfo `json:"results"`
+}
+
+// Metadata 書類一覧APIのメタデータ
+type Metadata struct {
+	Title           string            `json:"title"`
+	Parameter       MetadataParameter `json:"parameter"`
+	ResultSet       ResultSet         `json:"resultset"`
+	ProcessDateTime string            `json:"processDateTime"` // YYYY-MM-DD hh:mm
+	Status          string            `json:"status"`
+	Message         string            `json:"message"`
 }
 
+// MetadataParameter 書類一覧APIのリクエストパラメータ
+type MetadataParameter struct {
+	Date string `json:"date"`
+	Type string `json:"type"`
+}
+
+// ResultSet 書類一覧APIの件数
+type ResultSet struct {
+	Count int `json:"count"`
+}
+
+// MetadataStatusOK 正常終了を表すステータス
+const MetadataStatusOK = "200"
+
 // FinancialData 財務データ
 type FinancialData struct {
 	Date         string
@@ -82,6 +82,83 @@ func TestDocumentListResponse_JSONMarshal(t *testing.T) {
 	}
 }
 
+func TestDocumentListResponse_UnmarshalAPIResponse(t *testing.T) {
+	// EDINET API v2 書類一覧API（type=2）のレスポンス例
+	body := `{
+  "metadata": {
+    "title": "提出された書類を把握するためのAPI",
+    "parameter": {"date": "2025-06-25", "type": "2"},
+    "resultset": {"count": 2},
+    "processDateTime": "2025-06-26 00:00",
+    "status": "200",
+    "message": "OK"
+  },
+  "results": [
+    {
+      "seqNumber": 1, "docID": "S100ABCD", "edinetCode": "E00001", "secCode": "12340",
+      "JCN": "1234567890123", "filerName": "テスト株式会社", "fundCode": null,
+      "ordinanceCode": "010", "formCode": "030000", "docTypeCode": "120",
+      "periodStart": "2024-04-01", "periodEnd": "2025-03-31",
+      "submitDateTime": "2025-06-25 15:00", "docDescription": "有価証券報告書－第10期(2024/04/01－2025/03/31)",
+      "issuerEdinetCode": null, "subjectEdinetCode": null, "subsidiaryEdinetCode": null,
+      "currentReportReason": null, "parentDocID": null, "opeDateTime": null,
+      "withdrawalStatus": "0", "docInfoEditStatus": "0", "disclosureStatus": "0",
+      "xbrlFlag": "1", "pdfFlag": "1", "attachDocFlag": "1", "englishDocFlag": "0",
+      "csvFlag": "1", "legalStatus": "1"
+    },
+    {
+      "seqNumber": 2, "docID": "S100EFGH", "edinetCode": "E00001", "secCode": "12340",
+      "filerName": "テスト株式会社", "docTypeCode": "130", "parentDocID": "S100ABCD",
+      "submitDateTime": "2025-06-25 16:00", "withdrawalStatus": "2",
+      "xbrlFlag": "1", "pdfFlag": "0", "csvFlag": "0"
+    }
+  ]
+}`
+
+	var response DocumentListResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("JSONアンマーシャルエラー: %v", err)
+	}
+
+	if response.Metadata.Status != MetadataStatusOK || response.Metadata.ResultSet.Count != 2 {
+		t.Errorf("メタデータ不一致: %+v", response.Metadata)
+	}
+	if response.Metadata.Parameter.Date != "2025-06-25" || response.Metadata.ProcessDateTime != "2025-06-26 00:00" {
+		t.Errorf("メタデータのパラメータ不一致: %+v", response.Metadata)
+	}
+	if len(response.Results) != 2 {
+		t.Fatalf("Results数不一致: 期待=2, 実際=%d", len(response.Results))
+	}
+
+	doc := response.Results[0]
+	if doc.SeqNumber != 1 || doc.EdinetCode != "E00001" || doc.JCN != "1234567890123" {
+		t.Errorf("提出者情報不一致: %+v", doc)
+	}
+	if doc.OrdinanceCode != "010" || doc.FormCode != "030000" {
+		t.Errorf("府令・様式コード不一致: %s/%s", doc.OrdinanceCode, doc.FormCode)
+	}
+	if doc.PeriodStart != "2024-04-01" || doc.PeriodEnd != "2025-03-31" {
+		t.Errorf("期間不一致: %s〜%s", doc.PeriodStart, doc.PeriodEnd)
+	}
+	if doc.SubmitDate() != "2025-06-25" {
+		t.Errorf("提出日不一致: 期待=2025-06-25, 実際=%s", doc.SubmitDate())
+	}
+	if doc.FundCode != "" {
+		t.Errorf("nullの項目は空文字列であるべきです: %q", doc.FundCode)
+	}
+	if !doc.HasXBRL() || !doc.HasPDF() || !doc.HasAttachment() || doc.HasEnglish() || !doc.HasCSV() {
+		t.Errorf("フラグの判定が不正です: %+v", doc)
+	}
+	if doc.IsWithdrawn() || doc.IsAmendment() {
+		t.Error("取下げ・訂正でない書類の判定が不正です")
+	}
+
+	amended := response.Results[1]
+	if !amended.IsWithdrawn() || !amended.IsAmendment() {
+		t.Errorf("取り下げられた訂正書類の判定が不正です: %+v", amended)
+	}
+}
+
 func TestFinancialData_Structure(t *testing.T) {
 	data := FinancialData{
 		Date:         "2025-01-01",
@@ -141,4 +218,31 @@ func TestFinancialData_Structure(t *testing.T) {
 	if data.TotalAssets == "" {
 		t.Error("TotalAssetsが空です")
 	}
-} 
\ No newline at end of file
+} 
+
+func TestDocInfo_QuarterFromPeriod(t *testing.T) {
+	tests := []struct {
+		start, end string
+		fyeMonth   int
+		want       int
+	}{
+		{"2024-04-01", "2024-09-30", 0, 2},
+		{"2024-04-01", "2024-12-31", 0, 3},
+		{"2024-04-01", "2024-06-30", 3, 1},
+		{"2024-07-01", "2024-09-30", 3, 2},
+		{"2024-10-01", "2024-12-31", 3, 3},
+		{"2024-01-01", "2024-03-31", 12, 1},
+		{"2024-03-21", "2024-06-20", 3, 1},
+		// 3か月の期間で決算月が不明、期間がない、期間の長さが合わない場合は推定しない
+		{"2024-04-01", "2024-06-30", 0, 0},
+		{"", "", 3, 0},
+		{"2024-04-01", "2024-06-15", 3, 0},
+		{"2024-04-01", "2025-03-31", 3, 0},
+	}
+	for _, tt := range tests {
+		doc := DocInfo{PeriodStart: tt.start, PeriodEnd: tt.end}
+		if got := doc.QuarterFromPeriod(tt.fyeMonth); got != tt.want {
+			t.Errorf("%s〜%s（決算月=%d）: 期待=%d, 実際=%d", tt.start, tt.end, tt.fyeMonth, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import "strings"
+
+// ReportKind コンテキスト選択に用いる報告書の種類
+type ReportKind int
+
+const (
+	// ReportAnnual 有価証券報告書（通期）
+	ReportAnnual ReportKind = iota
+	// ReportQuarterly 四半期報告書
+	ReportQuarterly
+	// ReportSemiAnnual 半期報告書
+	ReportSemiAnnual
+)
+
+// ContextPolicy 報告書の種類ごとのコンテキストID選択方針
+//
+// IDは優先順に並べる。期間・時点の両方を含め、要素ごとに最初に見つかったものを採用する。
+type ContextPolicy struct {
+	Current []string
+	Prior   []string
+}
+
+// contextPolicies EDINETの標準コンテキストIDによる選択方針
+var contextPolicies = map[ReportKind]ContextPolicy{
+	ReportAnnual: {
+		Current: []string{"CurrentYearDuration", "CurrentYearInstant"},
+		Prior:   []string{"Prior1YearDuration", "Prior1YearInstant"},
+	},
+	ReportQuarterly: {
+		// 四半期報告書の損益・CFは累計期間を優先し、3か月間の会計期間は補助的に使う
+		Current: []string{"CurrentYTDDuration", "CurrentQuarterDuration", "CurrentQuarterInstant"},
+		Prior:   []string{"Prior1YTDDuration", "Prior1QuarterDuration", "Prior1YearInstant"},
+	},
+	ReportSemiAnnual: {
+		// 半期報告書は中間会計期間（期首からの累計）を使い、様式による命名の違いはYTDで補う
+		Current: []string{"InterimDuration", "CurrentYTDDuration", "InterimInstant", "CurrentQuarterInstant"},
+		Prior:   []string{"Prior1InterimDuration", "Prior1YTDDuration", "Prior1YearInstant"},
+	},
+}
+
+// PolicyFor 報告書の種類に応じた選択方針を返す
+func PolicyFor(kind ReportKind) ContextPolicy {
+	if policy, ok := contextPolicies[kind]; ok {
+		return policy
+	}
+	return contextPolicies[ReportAnnual]
+}
+
+// NonConsolidatedSuffix 単体コンテキストIDの接尾辞
+const NonConsolidatedSuffix = "_" + NonConsolidatedMember
+
+// Selection 選択されたファクトと採用したコンテキストID
+type Selection struct {
+	Fact      Fact
+	ContextID string
+	Fallback  bool // 標準コンテキストIDで見つからず、期間の比較で選んだ場合
+}
+
+// Select 指定したコンテキストIDの優先順でファクトを選ぶ
+// 要素名の候補が複数ある場合は、候補の順に各コンテキストIDを試す
+func (x *XBRLInstance) Select(names []string, contextIDs []string, scope Scope) (Selection, bool) {
+	for _, name := range names {
+		facts := x.FactsByName(name)
+		for _, id := range contextIDs {
+			if scope == ScopeNonConsolidated {
+				id += NonConsolidatedSuffix
+			}
+			for _, f := range facts {
+				if f.ContextRef == id {
+					return Selection{Fact: f, ContextID: id}, true
+				}
+			}
+		}
+	}
+
+	return Selection{}, false
+}
+
+// SelectCurrent 当期のファクトを選ぶ
+//
+// 全ての候補が標準コンテキストIDで見つからない場合は、提出者独自のコンテキストIDを
+// 想定して期間の比較で選んだ結果を返す。前期以前（Prior*）のコンテキストは対象外とする。
+func (x *XBRLInstance) SelectCurrent(names []string, policy ContextPolicy, scope Scope) (Selection, bool) {
+	if sel, ok := x.Select(names, policy.Current, scope); ok {
+		return sel, true
+	}
+	isPrior := func(contextID string) bool { return strings.HasPrefix(contextID, "Prior") }
+	for _, name := range names {
+		if f, ok := x.find(name, FactQuery{Scope: scope}, isPrior); ok {
+			return Selection{Fact: f, ContextID: f.ContextRef, Fallback: true}, true
+		}
+	}
+	return Selection{}, false
+}
+
+// SelectPrior 前期のファクトを選ぶ
+func (x *XBRLInstance) SelectPrior(names []string, policy ContextPolicy, scope Scope) (Selection, bool) {
+	return x.Select(names, policy.Prior, scope)
+}
@@ -0,0 +1,136 @@
+package models
+
+import "testing"
+
+// newQuarterlyInstance 四半期報告書を想定したテスト用インスタンスを作成
+func newQuarterlyInstance() *XBRLInstance {
+	x := NewXBRLInstance()
+	nonCons := []DimensionMember{
+		{Dimension: "jppfs_cor:ConsolidatedOrNonConsolidatedAxis", Member: "jppfs_cor:NonConsolidatedMember"},
+	}
+	x.AddContext(&Context{ID: "CurrentQuarterDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-10-01", EndDate: "2024-12-31"}})
+	x.AddContext(&Context{ID: "CurrentYTDDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-12-31"}})
+	x.AddContext(&Context{ID: "Prior1YTDDuration", Period: Period{Type: PeriodDuration, StartDate: "2023-04-01", EndDate: "2023-12-31"}})
+	x.AddContext(&Context{ID: "CurrentQuarterInstant", Period: Period{Type: PeriodInstant, Instant: "2024-12-31"}})
+	x.AddContext(&Context{ID: "Prior1YearInstant", Period: Period{Type: PeriodInstant, Instant: "2024-03-31"}})
+	x.AddContext(&Context{ID: "CurrentYTDDuration_NonConsolidatedMember", Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-12-31"}, Scenario: nonCons})
+	x.AddContext(&Context{ID: "FilerSpecificDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-12-31"}})
+
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentQuarterDuration", Value: "300"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "Prior1YTDDuration", Value: "800"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYTDDuration_NonConsolidatedMember", Value: "600"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYTDDuration", Value: "900"})
+	x.AddFact(Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "Prior1YearInstant", Value: "4000"})
+	x.AddFact(Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "CurrentQuarterInstant", Value: "5000"})
+	x.AddFact(Fact{Name: "jppfs_cor:Depreciation", ContextRef: "FilerSpecificDuration", Value: "50"})
+	x.AddFact(Fact{Name: "jppfs_cor:Inventories", ContextRef: "Prior1YearInstant", Value: "70"})
+	return x
+}
+
+func TestXBRLInstance_SelectCurrent_Quarterly(t *testing.T) {
+	x := newQuarterlyInstance()
+	policy := PolicyFor(ReportQuarterly)
+
+	testCases := []struct {
+		name      string
+		scope     Scope
+		value     string
+		contextID string
+		fallback  bool
+	}{
+		{"jppfs_cor:NetSales", ScopeConsolidated, "900", "CurrentYTDDuration", false},
+		{"jppfs_cor:NetSales", ScopeNonConsolidated, "600", "CurrentYTDDuration_NonConsolidatedMember", false},
+		{"jppfs_cor:TotalAssets", ScopeConsolidated, "5000", "CurrentQuarterInstant", false},
+		{"jppfs_cor:Depreciation", ScopeConsolidated, "50", "FilerSpecificDuration", true},
+	}
+
+	for _, tc := range testCases {
+		sel, ok := x.SelectCurrent([]string{tc.name}, policy, tc.scope)
+		if !ok {
+			t.Errorf("%s: ファクトが見つかりません", tc.name)
+			continue
+		}
+		if sel.Fact.Value != tc.value || sel.ContextID != tc.contextID || sel.Fallback != tc.fallback {
+			t.Errorf("%s: 期待=%s/%s/%t, 実際=%s/%s/%t", tc.name,
+				tc.value, tc.contextID, tc.fallback, sel.Fact.Value, sel.ContextID, sel.Fallback)
+		}
+	}
+
+	// 前期の値しかない要素は当期として選ばれない
+	if sel, ok := x.SelectCurrent([]string{"jppfs_cor:Inventories"}, policy, ScopeConsolidated); ok {
+		t.Errorf("前期の値が当期として選ばれました: %+v", sel)
+	}
+}
+
+func TestXBRLInstance_SelectPrior(t *testing.T) {
+	x := newQuarterlyInstance()
+	policy := PolicyFor(ReportQuarterly)
+
+	sel, ok := x.SelectPrior([]string{"jppfs_cor:NetSales"}, policy, ScopeConsolidated)
+	if !ok || sel.Fact.Value != "800" || sel.ContextID != "Prior1YTDDuration" {
+		t.Errorf("前期NetSales不一致: %+v", sel)
+	}
+
+	sel, ok = x.SelectPrior([]string{"jppfs_cor:TotalAssets"}, policy, ScopeConsolidated)
+	if !ok || sel.Fact.Value != "4000" || sel.ContextID != "Prior1YearInstant" {
+		t.Errorf("前期末TotalAssets不一致: %+v", sel)
+	}
+}
+
+func TestXBRLInstance_SelectCurrent_Candidates(t *testing.T) {
+	x := newQuarterlyInstance()
+	x.AddFact(Fact{Name: "jpigp_cor:RevenueIFRS", ContextRef: "CurrentYTDDuration", Value: "950"})
+
+	// 先の候補が優先される
+	sel, ok := x.SelectCurrent([]string{"jpigp_cor:RevenueIFRS", "jppfs_cor:NetSales"}, PolicyFor(ReportQuarterly), ScopeConsolidated)
+	if !ok || sel.Fact.Name != "jpigp_cor:RevenueIFRS" || sel.Fact.Value != "950" {
+		t.Errorf("候補の優先順が不正です: %+v", sel)
+	}
+
+	// 先の候補がなければ次の候補を使う
+	sel, ok = x.SelectCurrent([]string{"jpigp_cor:NetSalesIFRS", "jppfs_cor:NetSales"}, PolicyFor(ReportQuarterly), ScopeConsolidated)
+	if !ok || sel.Fact.Name != "jppfs_cor:NetSales" || sel.Fact.Value != "900" {
+		t.Errorf("次の候補が使われていません: %+v", sel)
+	}
+}
+
+func TestPolicyFor_Annual(t *testing.T) {
+	policy := PolicyFor(ReportAnnual)
+	if policy.Current[0] != "CurrentYearDuration" || policy.Prior[0] != "Prior1YearDuration" {
+		t.Errorf("通期の選択方針が不正です: %+v", policy)
+	}
+}
+
+func TestXBRLInstance_SelectCurrent_SemiAnnual(t *testing.T) {
+	x := NewXBRLInstance()
+	x.AddContext(&Context{ID: "InterimDuration", Period: Period{Type: PeriodDuration, StartDate: "2025-04-01", EndDate: "2025-09-30"}})
+	x.AddContext(&Context{ID: "Prior1InterimDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-09-30"}})
+	x.AddContext(&Context{ID: "InterimInstant", Period: Period{Type: PeriodInstant, Instant: "2025-09-30"}})
+	x.AddContext(&Context{ID: "Prior1YearInstant", Period: Period{Type: PeriodInstant, Instant: "2025-03-31"}})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "Prior1InterimDuration", UnitRef: "JPY", Value: "400"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "InterimDuration", UnitRef: "JPY", Value: "500"})
+	x.AddFact(Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "Prior1YearInstant", UnitRef: "JPY", Value: "900"})
+	x.AddFact(Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "InterimInstant", UnitRef: "JPY", Value: "1000"})
+
+	policy := PolicyFor(ReportSemiAnnual)
+	testCases := []struct {
+		name      string
+		current   string
+		prior     string
+		contextID string
+	}{
+		{"jppfs_cor:NetSales", "500", "400", "InterimDuration"},
+		{"jppfs_cor:TotalAssets", "1000", "900", "InterimInstant"},
+	}
+
+	for _, tc := range testCases {
+		sel, ok := x.SelectCurrent([]string{tc.name}, policy, ScopeConsolidated)
+		if !ok || sel.Fact.Value != tc.current || sel.ContextID != tc.contextID || sel.Fallback {
+			t.Errorf("%s の当期の選択が不正です: %+v", tc.name, sel)
+		}
+		prior, ok := x.SelectPrior([]string{tc.name}, policy, ScopeConsolidated)
+		if !ok || prior.Fact.Value != tc.prior {
+			t.Errorf("%s の前期の選択が不正です: %+v", tc.name, prior)
+		}
+	}
+}
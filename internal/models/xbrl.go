@@ -0,0 +1,276 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PeriodType コンテキストの期間種別
+type PeriodType int
+
+const (
+	// PeriodAny 期間種別を問わない（検索条件用）
+	PeriodAny PeriodType = iota
+	// PeriodInstant 時点
+	PeriodInstant
+	// PeriodDuration 期間
+	PeriodDuration
+	// PeriodForever 無期限
+	PeriodForever
+)
+
+// Period XBRLコンテキストの期間
+type Period struct {
+	Type      PeriodType
+	Instant   string
+	StartDate string
+	EndDate   string
+}
+
+// End 期間の終了日を返す（時点の場合は時点日）
+func (p Period) End() string {
+	if p.Type == PeriodInstant {
+		return p.Instant
+	}
+	return p.EndDate
+}
+
+// Days 期間の日数を返す（時点・不正な日付の場合は0）
+func (p Period) Days() int {
+	if p.Type != PeriodDuration {
+		return 0
+	}
+	const layout = "2006-01-02"
+	start, err := time.Parse(layout, p.StartDate)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(layout, p.EndDate)
+	if err != nil {
+		return 0
+	}
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// DimensionMember シナリオ（またはセグメント）のディメンションとメンバー
+type DimensionMember struct {
+	Dimension string
+	Member    string
+	Typed     bool
+}
+
+// ConsolidationAxis 連結・個別を表すディメンション
+const ConsolidationAxis = "ConsolidatedOrNonConsolidatedAxis"
+
+// NonConsolidatedMember 個別（単体）を表すメンバー
+const NonConsolidatedMember = "NonConsolidatedMember"
+
+// Context XBRLコンテキスト
+type Context struct {
+	ID       string
+	Entity   string
+	Scheme   string
+	Period   Period
+	Scenario []DimensionMember
+}
+
+// IsNonConsolidated 単体（個別）のコンテキストかどうか
+func (c *Context) IsNonConsolidated() bool {
+	member := c.Member(ConsolidationAxis)
+	return member != "" && localName(member) == NonConsolidatedMember
+}
+
+// Member 指定ディメンションのメンバーを返す（ディメンションはローカル名でも可）
+func (c *Context) Member(dimension string) string {
+	for _, dm := range c.Scenario {
+		if dm.Dimension == dimension || localName(dm.Dimension) == dimension {
+			return dm.Member
+		}
+	}
+	return ""
+}
+
+// Scope 連結・単体の区別
+type Scope int
+
+const (
+	// ScopeConsolidated 連結
+	ScopeConsolidated Scope = iota
+	// ScopeNonConsolidated 単体（個別）
+	ScopeNonConsolidated
+)
+
+// Label 連結・単体の表示名
+func (s Scope) Label() string {
+	if s == ScopeNonConsolidated {
+		return "単体"
+	}
+	return "連結"
+}
+
+// Matches コンテキストが連結・単体の区別に一致し、他のディメンションを持たないか
+//
+// ScopeConsolidatedはディメンションなしのコンテキストに一致する。連結財務諸表を
+// 作成しない提出者は単体の値をディメンションなしで報告する点に注意。
+func (s Scope) Matches(c *Context) bool {
+	switch s {
+	case ScopeNonConsolidated:
+		return len(c.Scenario) == 1 && c.IsNonConsolidated()
+	default:
+		return len(c.Scenario) == 0
+	}
+}
+
+// Unit XBRLの単位
+type Unit struct {
+	ID          string
+	Measures    []string
+	Denominator []string
+}
+
+// Fact XBRLのファクト
+type Fact struct {
+	Name       string // 接頭辞付きの要素名（例: jppfs_cor:NetSales）
+	Namespace  string
+	ContextRef string
+	UnitRef    string
+	Decimals   string
+	Nil        bool
+	Value      string
+}
+
+// LocalName 接頭辞を除いた要素名
+func (f Fact) LocalName() string {
+	return localName(f.Name)
+}
+
+// IsNumeric 数値ファクトかどうか
+func (f Fact) IsNumeric() bool {
+	return f.UnitRef != ""
+}
+
+// FactQuery ファクト検索条件
+type FactQuery struct {
+	PeriodType PeriodType
+	Scope      Scope
+}
+
+// XBRLInstance 解析済みのXBRLインスタンス
+type XBRLInstance struct {
+	Contexts   map[string]*Context
+	Units      map[string]*Unit
+	Facts      []Fact
+	Namespaces map[string]string // 接頭辞 → 名前空間URI
+	byName     map[string][]int
+}
+
+// NewXBRLInstance 空のXBRLインスタンスを作成
+func NewXBRLInstance() *XBRLInstance {
+	return &XBRLInstance{
+		Contexts:   make(map[string]*Context),
+		Units:      make(map[string]*Unit),
+		Namespaces: make(map[string]string),
+		byName:     make(map[string][]int),
+	}
+}
+
+// AddContext コンテキストを追加
+func (x *XBRLInstance) AddContext(c *Context) {
+	x.Contexts[c.ID] = c
+}
+
+// AddUnit 単位を追加
+func (x *XBRLInstance) AddUnit(u *Unit) {
+	x.Units[u.ID] = u
+}
+
+// AddFact ファクトを追加（文書内の出現順を保持）
+func (x *XBRLInstance) AddFact(f Fact) {
+	x.byName[f.Name] = append(x.byName[f.Name], len(x.Facts))
+	x.Facts = append(x.Facts, f)
+}
+
+// FactsByName 要素名に一致するファクトを出現順に返す
+func (x *XBRLInstance) FactsByName(name string) []Fact {
+	idx := x.byName[name]
+	facts := make([]Fact, 0, len(idx))
+	for _, i := range idx {
+		facts = append(facts, x.Facts[i])
+	}
+	return facts
+}
+
+// Context ファクトのコンテキストを返す（未定義の場合はnil）
+func (x *XBRLInstance) Context(f Fact) *Context {
+	return x.Contexts[f.ContextRef]
+}
+
+// Find 条件に一致するファクトのうち当期のものを返す
+//
+// 当期は終了日（時点日）が最も新しいものとし、同じ終了日の場合は
+// 期間の長いもの、それも同じ場合は文書内で先に出現したものを選ぶ。
+func (x *XBRLInstance) Find(name string, q FactQuery) (Fact, bool) {
+	return x.find(name, q, nil)
+}
+
+// find Findの本体（skipがtrueを返すコンテキストIDは候補から除外）
+func (x *XBRLInstance) find(name string, q FactQuery, skip func(contextID string) bool) (Fact, bool) {
+	var best Fact
+	var bestCtx *Context
+	found := false
+
+	for _, f := range x.FactsByName(name) {
+		if skip != nil && skip(f.ContextRef) {
+			continue
+		}
+		ctx := x.Context(f)
+		if ctx == nil || !q.Scope.Matches(ctx) {
+			continue
+		}
+		if q.PeriodType != PeriodAny && ctx.Period.Type != q.PeriodType {
+			continue
+		}
+		if found && !isLaterPeriod(ctx.Period, bestCtx.Period) {
+			continue
+		}
+		best, bestCtx, found = f, ctx, true
+	}
+
+	return best, found
+}
+
+// HasConsolidatedStatements 連結財務諸表を作成しているかどうか
+// DEIがない場合は従来通りディメンションなしの値を連結として扱う
+func (x *XBRLInstance) HasConsolidatedStatements() bool {
+	return x.DEI().IsConsolidated()
+}
+
+// TaxonomyVersion jppfs_cor名前空間からタクソノミーの版（日付）を返す
+func (x *XBRLInstance) TaxonomyVersion() string {
+	uri := x.Namespaces["jppfs_cor"]
+	parts := strings.Split(uri, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	version := parts[len(parts)-2]
+	if _, err := time.Parse("2006-01-02", version); err != nil {
+		return ""
+	}
+	return version
+}
+
+// isLaterPeriod aがbより当期らしい期間かどうか
+func isLaterPeriod(a, b Period) bool {
+	if a.End() != b.End() {
+		return a.End() > b.End()
+	}
+	return a.Days() > b.Days()
+}
+
+// localName 接頭辞を除いた名前を返す
+func localName(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
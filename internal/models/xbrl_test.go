@@ -0,0 +1,84 @@
+package models
+
+import "testing"
+
+func TestPeriod_EndAndDays(t *testing.T) {
+	duration := Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2025-03-31"}
+	if duration.End() != "2025-03-31" {
+		t.Errorf("終了日不一致: 期待=2025-03-31, 実際=%s", duration.End())
+	}
+	if duration.Days() != 365 {
+		t.Errorf("日数不一致: 期待=365, 実際=%d", duration.Days())
+	}
+
+	instant := Period{Type: PeriodInstant, Instant: "2025-03-31"}
+	if instant.End() != "2025-03-31" {
+		t.Errorf("時点不一致: 期待=2025-03-31, 実際=%s", instant.End())
+	}
+	if instant.Days() != 0 {
+		t.Errorf("時点の日数不一致: 期待=0, 実際=%d", instant.Days())
+	}
+}
+
+func TestXBRLInstance_Find(t *testing.T) {
+	x := NewXBRLInstance()
+	x.AddContext(&Context{ID: "CurrentQuarterDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-10-01", EndDate: "2024-12-31"}})
+	x.AddContext(&Context{ID: "CurrentYTDDuration", Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-12-31"}})
+	x.AddContext(&Context{ID: "Prior1YTDDuration", Period: Period{Type: PeriodDuration, StartDate: "2023-04-01", EndDate: "2023-12-31"}})
+	x.AddContext(&Context{ID: "CurrentQuarterInstant", Period: Period{Type: PeriodInstant, Instant: "2024-12-31"}})
+	x.AddContext(&Context{
+		ID:     "CurrentYTDDuration_ReportableSegmentsMember",
+		Period: Period{Type: PeriodDuration, StartDate: "2024-04-01", EndDate: "2024-12-31"},
+		Scenario: []DimensionMember{
+			{Dimension: "jpcrp_cor:OperatingSegmentsAxis", Member: "jpcrp_cor:ReportableSegmentsMember"},
+		},
+	})
+
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "Prior1YTDDuration", Value: "700"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentQuarterDuration", Value: "300"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYTDDuration_ReportableSegmentsMember", Value: "500"})
+	x.AddFact(Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYTDDuration", Value: "900"})
+	x.AddFact(Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "CurrentQuarterInstant", Value: "5000"})
+
+	// 同じ終了日なら期間の長い累計期間を選び、セグメント別の値は除外する
+	fact, ok := x.Find("jppfs_cor:NetSales", FactQuery{})
+	if !ok || fact.Value != "900" {
+		t.Errorf("NetSales不一致: 期待=900, 実際=%+v", fact)
+	}
+
+	// 期間種別で絞り込み
+	if _, ok := x.Find("jppfs_cor:TotalAssets", FactQuery{PeriodType: PeriodDuration}); ok {
+		t.Error("時点のファクトが期間の条件で見つかるべきではありません")
+	}
+	fact, ok = x.Find("jppfs_cor:TotalAssets", FactQuery{PeriodType: PeriodInstant})
+	if !ok || fact.Value != "5000" {
+		t.Errorf("TotalAssets不一致: 期待=5000, 実際=%+v", fact)
+	}
+
+	// 単体の値は存在しない
+	if _, ok := x.Find("jppfs_cor:NetSales", FactQuery{Scope: ScopeNonConsolidated}); ok {
+		t.Error("単体のファクトは存在しないはずです")
+	}
+
+	// 存在しない要素
+	if _, ok := x.Find("jppfs_cor:OrdinaryIncome", FactQuery{}); ok {
+		t.Error("存在しない要素が見つかりました")
+	}
+}
+
+func TestContext_IsNonConsolidated(t *testing.T) {
+	ctx := &Context{Scenario: []DimensionMember{
+		{Dimension: "jppfs_cor:ConsolidatedOrNonConsolidatedAxis", Member: "jppfs_cor:NonConsolidatedMember"},
+	}}
+	if !ctx.IsNonConsolidated() {
+		t.Error("単体コンテキストとして認識されるべきです")
+	}
+	if !ScopeNonConsolidated.Matches(ctx) || ScopeConsolidated.Matches(ctx) {
+		t.Error("Scopeの判定が不正です")
+	}
+
+	plain := &Context{}
+	if plain.IsNonConsolidated() || !ScopeConsolidated.Matches(plain) {
+		t.Error("ディメンションなしのコンテキストは連結として扱われるべきです")
+	}
+}
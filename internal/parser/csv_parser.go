@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
+	"edinet-api-test/internal/logging"
+	"edinet-api-test/internal/models"
+)
+
+// XBRLをCSVに変換したファイル（書類取得APIのtype=5）の列名
+const (
+	csvColumnElementID = "要素ID"
+	csvColumnContextID = "コンテキストID"
+	csvColumnScope     = "連結・個別"
+	csvColumnPeriod    = "期間・時点"
+	csvColumnUnitID    = "ユニットID"
+	csvColumnValue     = "値"
+)
+
+// csvNone CSVで値・単位がないことを表す表記（値の場合はnil）
+const csvNone = "－"
+
+// ParseZipCSV XBRLをCSVに変換したZIP（type=5）のXBRL_TO_CSVにあるCSVを解析
+//
+// 監査報告書（jpaud）を除く全てのCSVを1つのインスタンスにまとめる。CSVには期間の日付や
+// 名前空間がないため、コンテキストは期間・時点の区別とコンテキストIDのディメンションのみを持つ。
+func (x *XBRLParser) ParseZipCSV(ctx context.Context, zipFile string) (*models.XBRLInstance, error) {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("ZIPファイルオープンエラー: %v", err)
+	}
+	defer r.Close()
+
+	var files []*zip.File
+	for _, f := range r.File {
+		base := path.Base(f.Name)
+		if !strings.Contains(f.Name, "XBRL_TO_CSV") || !strings.HasSuffix(base, ".csv") || strings.HasPrefix(base, "jpaud") {
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("XBRL_TO_CSVのCSVファイルが見つかりません")
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	logging.FromContext(ctx).Debug("XBRLから変換したCSVを解析", "files", len(files))
+
+	instance := models.NewXBRLInstance()
+	for _, f := range files {
+		in, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
+		}
+		err = readCSVFacts(contextReader{ctx, in}, instance)
+		in.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path.Base(f.Name), err)
+		}
+	}
+	return instance, nil
+}
+
+// ParseCSV XBRLをCSVに変換したファイル（UTF-16のタブ区切り）を1つ解析
+func (x *XBRLParser) ParseCSV(ctx context.Context, r io.Reader) (*models.XBRLInstance, error) {
+	instance := models.NewXBRLInstance()
+	if err := readCSVFacts(contextReader{ctx, r}, instance); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return instance, nil
+}
+
+// readCSVFacts CSVの各行をファクトとしてインスタンスに追加
+func readCSVFacts(r io.Reader, instance *models.XBRLInstance) error {
+	// BOMに従ってUTF-16LE/BEを判定する（BOMがない場合はLE）
+	decoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+	reader := csv.NewReader(transform.NewReader(r, decoder))
+	reader.Comma = '\t'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("CSVヘッダー読み込みエラー: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{csvColumnElementID, csvColumnContextID, csvColumnUnitID, csvColumnValue} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("CSVに「%s」列がありません", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("CSV読み込みエラー: %v", err)
+		}
+
+		name := field(record, csvColumnElementID)
+		contextID := field(record, csvColumnContextID)
+		if name == "" || contextID == "" {
+			continue
+		}
+		if _, ok := instance.Contexts[contextID]; !ok {
+			instance.AddContext(csvContext(contextID, field(record, csvColumnPeriod), field(record, csvColumnScope)))
+		}
+
+		fact := models.Fact{
+			Name:       name,
+			ContextRef: contextID,
+			UnitRef:    field(record, csvColumnUnitID),
+			Value:      field(record, csvColumnValue),
+		}
+		if fact.UnitRef == csvNone {
+			fact.UnitRef = ""
+		}
+		if fact.IsNumeric() {
+			if _, ok := instance.Units[fact.UnitRef]; !ok {
+				instance.AddUnit(&models.Unit{ID: fact.UnitRef})
+			}
+			if fact.Value == csvNone || fact.Value == "" {
+				fact.Nil, fact.Value = true, ""
+			}
+		}
+		instance.AddFact(fact)
+	}
+}
+
+// csvContext CSVの列とコンテキストIDからコンテキストを組み立てる
+//
+// コンテキストIDの「_」以降はメンバー（例: CurrentYearInstant_NonConsolidatedMember）。
+// 連結・個別の区別は「連結・個別」列を優先し、それ以外のメンバーはディメンション不明として持つ。
+func csvContext(id, period, scope string) *models.Context {
+	c := &models.Context{ID: id, Period: models.Period{Type: models.PeriodDuration}}
+	switch period {
+	case "時点":
+		c.Period.Type = models.PeriodInstant
+	case "期間":
+	default:
+		if strings.Contains(id, "Instant") {
+			c.Period.Type = models.PeriodInstant
+		}
+	}
+
+	_, members, _ := strings.Cut(id, "_")
+	nonConsolidated := scope == "個別"
+	// メンバー名は「接頭辞_ローカル名」の形で「_」を含むため、Memberで終わるまでを1つにまとめる
+	var member string
+	for _, part := range strings.Split(members, "_") {
+		if part == "" {
+			continue
+		}
+		if member != "" {
+			member += "_"
+		}
+		member += part
+		if !strings.HasSuffix(part, "Member") {
+			continue
+		}
+		if member == models.NonConsolidatedMember {
+			nonConsolidated = true
+		} else {
+			c.Scenario = append(c.Scenario, models.DimensionMember{Member: member})
+		}
+		member = ""
+	}
+	if nonConsolidated {
+		c.Scenario = append([]models.DimensionMember{{
+			Dimension: "jppfs_cor:" + models.ConsolidationAxis,
+			Member:    "jppfs_cor:" + models.NonConsolidatedMember,
+		}}, c.Scenario...)
+	}
+	return c
+}
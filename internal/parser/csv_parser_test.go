@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+
+	"edinet-api-test/internal/models"
+)
+
+// testCSVRows XBRLをCSVに変換したファイルの例（タブ区切り）
+var testCSVRows = []string{
+	"\"要素ID\"\t\"項目名\"\t\"コンテキストID\"\t\"相対年度\"\t\"連結・個別\"\t\"期間・時点\"\t\"ユニットID\"\t\"単位\"\t\"値\"",
+	"\"jpdei_cor:SecurityCodeDEI\"\t\"証券コード、DEI\"\t\"FilingDateInstant\"\t\"提出日時点\"\t\"その他\"\t\"時点\"\t\"－\"\t\"－\"\t\"72030\"",
+	"\"jppfs_cor:NetSales\"\t\"売上高\"\t\"CurrentYearDuration\"\t\"当期\"\t\"連結\"\t\"期間\"\t\"JPY\"\t\"円\"\t\"1000000\"",
+	"\"jppfs_cor:NetSales\"\t\"売上高\"\t\"CurrentYearDuration_NonConsolidatedMember\"\t\"当期\"\t\"個別\"\t\"期間\"\t\"JPY\"\t\"円\"\t\"600000\"",
+	"\"jppfs_cor:Assets\"\t\"資産\"\t\"CurrentYearInstant\"\t\"当期末\"\t\"連結\"\t\"時点\"\t\"JPY\"\t\"円\"\t\"5000000\"",
+	"\"jppfs_cor:NetSales\"\t\"売上高\"\t\"CurrentYearDuration_jpcrp030000-asr_E00001-000AutomotiveReportableSegmentMember\"\t\"当期\"\t\"連結\"\t\"期間\"\t\"JPY\"\t\"円\"\t\"400000\"",
+	"\"jppfs_cor:GoodwillAmortization\"\t\"のれん償却額\"\t\"CurrentYearDuration\"\t\"当期\"\t\"連結\"\t\"期間\"\t\"JPY\"\t\"円\"\t\"－\"",
+}
+
+// encodeUTF16 テスト用にBOM付きのUTF-16LEに変換
+func encodeUTF16(t *testing.T, s string) string {
+	t.Helper()
+	encoded, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("UTF-16変換エラー: %v", err)
+	}
+	return encoded
+}
+
+func TestXBRLParser_ParseCSV(t *testing.T) {
+	parser := NewXBRLParser()
+	content := encodeUTF16(t, strings.Join(testCSVRows, "\r\n")+"\r\n")
+
+	instance, err := parser.ParseCSV(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("CSV解析エラー: %v", err)
+	}
+
+	if dei := instance.DEI(); dei.SecurityCode != "72030" {
+		t.Errorf("DEIの証券コード不一致: 期待=72030, 実際=%s", dei.SecurityCode)
+	}
+
+	q := models.FactQuery{PeriodType: models.PeriodDuration, Scope: models.ScopeConsolidated}
+	if f, ok := instance.Find("jppfs_cor:NetSales", q); !ok || f.Value != "1000000" {
+		t.Errorf("連結の売上高不一致: %v", f)
+	}
+	q.Scope = models.ScopeNonConsolidated
+	if f, ok := instance.Find("jppfs_cor:NetSales", q); !ok || f.Value != "600000" {
+		t.Errorf("単体の売上高不一致: %v", f)
+	}
+
+	// セグメントのメンバーは連結・単体のどちらにも一致しない
+	segment := instance.Contexts["CurrentYearDuration_jpcrp030000-asr_E00001-000AutomotiveReportableSegmentMember"]
+	if segment == nil || len(segment.Scenario) != 1 || segment.Scenario[0].Member != "jpcrp030000-asr_E00001-000AutomotiveReportableSegmentMember" {
+		t.Errorf("セグメントのコンテキスト不一致: %+v", segment)
+	}
+	if c := instance.Contexts["CurrentYearInstant"]; c == nil || c.Period.Type != models.PeriodInstant {
+		t.Errorf("時点のコンテキストになっていません: %+v", c)
+	}
+
+	facts := instance.FactsByName("jppfs_cor:GoodwillAmortization")
+	if len(facts) != 1 || !facts[0].Nil {
+		t.Errorf("「－」はnilとして扱うべきです: %v", facts)
+	}
+	if facts := instance.FactsByName("jpdei_cor:SecurityCodeDEI"); len(facts) != 1 || facts[0].IsNumeric() {
+		t.Errorf("単位のないファクトは非数値として扱うべきです: %v", facts)
+	}
+}
+
+func TestXBRLParser_ParseCSV_MissingColumn(t *testing.T) {
+	parser := NewXBRLParser()
+	content := encodeUTF16(t, "\"要素ID\"\t\"値\"\r\n\"jppfs_cor:NetSales\"\t\"1\"\r\n")
+
+	if _, err := parser.ParseCSV(context.Background(), strings.NewReader(content)); err == nil {
+		t.Error("必要な列がない場合、エラーが発生すべきです")
+	}
+}
+
+func TestXBRLParser_ParseZipCSV(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_csv.zip"
+	defer os.Remove(zipFile)
+
+	audit := []string{testCSVRows[0], "\"jpaud_cor:Dummy\"\t\"\"\t\"FilingDateInstant\"\t\"\"\t\"\"\t\"時点\"\t\"\"\t\"\"\t\"x\""}
+	writeTestZip(t, zipFile, map[string]string{
+		"XBRL_TO_CSV/jpcrp030000-asr-001_E00001-000_2025-03-31_01_2025-06-20.csv":  encodeUTF16(t, strings.Join(testCSVRows, "\r\n")),
+		"XBRL_TO_CSV/jpaud-aar-cn-001_E00001-000_2025-03-31_01_2025-06-20.csv":     encodeUTF16(t, strings.Join(audit, "\r\n")),
+		"XBRL_TO_CSV/jpcrp030000-asr-001_E00001-000_2025-03-31_01_2025-06-20.xbrl": "not csv",
+	})
+
+	instance, err := parser.ParseZipCSV(context.Background(), zipFile)
+	if err != nil {
+		t.Fatalf("ZIP解析エラー: %v", err)
+	}
+	if len(instance.Facts) != len(testCSVRows)-1 {
+		t.Errorf("ファクト数不一致: 期待=%d, 実際=%d", len(testCSVRows)-1, len(instance.Facts))
+	}
+	if len(instance.FactsByName("jpaud_cor:Dummy")) != 0 {
+		t.Error("監査報告書のCSVは読み込まないべきです")
+	}
+}
+
+func TestXBRLParser_ParseZipCSV_NotFound(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_csv_empty.zip"
+	defer os.Remove(zipFile)
+
+	writeTestZip(t, zipFile, map[string]string{"XBRL/PublicDoc/dummy.xbrl": "<xbrl/>"})
+	if _, err := parser.ParseZipCSV(context.Background(), zipFile); err == nil {
+		t.Error("CSVがない場合、エラーが発生すべきです")
+	}
+}
+
+func TestXBRLParser_ParseCSV_Canceled(t *testing.T) {
+	parser := NewXBRLParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	content := encodeUTF16(t, strings.Join(testCSVRows, "\r\n"))
+	if _, err := parser.ParseCSV(ctx, strings.NewReader(content)); err != context.Canceled {
+		t.Errorf("context.Canceledが返されるべきです: %v", err)
+	}
+}
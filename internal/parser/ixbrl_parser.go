@@ -0,0 +1,409 @@
+package parser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"edinet-api-test/internal/logging"
+	"edinet-api-test/internal/models"
+)
+
+// Manifest PublicDocのマニフェスト（manifest_PublicDoc.xml）
+type Manifest struct {
+	Instances []ManifestInstance `xml:"list>instance"`
+}
+
+// ManifestInstance マニフェストに記載されたインスタンスと構成するiXBRLファイル
+type ManifestInstance struct {
+	PreferredFilename string   `xml:"preferredFilename,attr"`
+	IXBRL             []string `xml:"ixbrl"`
+}
+
+// ParseManifest マニフェストを解析
+func (x *XBRLParser) ParseManifest(r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+	if err := xml.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("マニフェスト解析エラー: %v", err)
+	}
+	return &manifest, nil
+}
+
+// ixFact 解析中のiXBRLファクト
+type ixFact struct {
+	fact        models.Fact
+	numeric     bool
+	format      string
+	scale       string
+	sign        string
+	continuedAt string
+	depth       int
+	text        strings.Builder
+}
+
+// ixContinuation 解析中のix:continuation要素
+type ixContinuation struct {
+	id          string
+	continuedAt string
+	depth       int
+	text        strings.Builder
+}
+
+// IXBRLReader 複数のiXBRLファイルから1つのインスタンスを組み立てる
+//
+// EDINETのiXBRLは1つのインスタンスが複数のファイル（表紙・本文ごと）に分かれ、
+// ix:continuationがファイルをまたぐことがあるため、全ファイルを読んでからFinishで確定する。
+type IXBRLReader struct {
+	instance      *models.XBRLInstance
+	facts         []*ixFact
+	continuations map[string]*ixContinuation
+}
+
+// NewIXBRLReader 新しいiXBRLリーダーを作成
+func NewIXBRLReader() *IXBRLReader {
+	return &IXBRLReader{
+		instance:      models.NewXBRLInstance(),
+		continuations: make(map[string]*ixContinuation),
+	}
+}
+
+// Read iXBRLファイルを1つ読み込む
+func (r *IXBRLReader) Read(in io.Reader) error {
+	decoder := xml.NewDecoder(in)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var open []*ixFact // 入れ子になった解析中のファクト
+	var openCont []*ixContinuation
+	depth := 0
+	excludeDepth := 0
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iXBRLデコードエラー: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					r.instance.Namespaces[attr.Name.Local] = attr.Value
+				}
+			}
+
+			switch t.Name.Local {
+			case "context":
+				var xc xmlContext
+				if err := decoder.DecodeElement(&xc, &t); err != nil {
+					return fmt.Errorf("コンテキスト解析エラー: %v", err)
+				}
+				depth--
+				r.instance.AddContext(xc.toContext())
+			case "unit":
+				var xu xmlUnit
+				if err := decoder.DecodeElement(&xu, &t); err != nil {
+					return fmt.Errorf("単位解析エラー: %v", err)
+				}
+				depth--
+				r.instance.AddUnit(xu.toUnit())
+			case "nonFraction", "nonNumeric":
+				f := &ixFact{
+					fact: models.Fact{
+						Name:       attrValue(t, "name"),
+						ContextRef: attrValue(t, "contextRef"),
+						UnitRef:    attrValue(t, "unitRef"),
+						Decimals:   attrValue(t, "decimals"),
+						Nil:        attrValue(t, "nil") == "true",
+					},
+					numeric:     t.Name.Local == "nonFraction",
+					format:      attrValue(t, "format"),
+					scale:       attrValue(t, "scale"),
+					sign:        attrValue(t, "sign"),
+					continuedAt: attrValue(t, "continuedAt"),
+					depth:       depth,
+				}
+				if prefix, _, ok := strings.Cut(f.fact.Name, ":"); ok {
+					f.fact.Namespace = r.instance.Namespaces[prefix]
+				}
+				open = append(open, f)
+				r.facts = append(r.facts, f)
+			case "continuation":
+				c := &ixContinuation{
+					id:          attrValue(t, "id"),
+					continuedAt: attrValue(t, "continuedAt"),
+					depth:       depth,
+				}
+				r.continuations[c.id] = c
+				openCont = append(openCont, c)
+			case "exclude":
+				if excludeDepth == 0 {
+					excludeDepth = depth
+				}
+			}
+
+		case xml.CharData:
+			if excludeDepth > 0 {
+				continue
+			}
+			for _, f := range open {
+				f.text.Write(t)
+			}
+			for _, c := range openCont {
+				c.text.Write(t)
+			}
+
+		case xml.EndElement:
+			if n := len(open); n > 0 && open[n-1].depth == depth {
+				open = open[:n-1]
+			}
+			if n := len(openCont); n > 0 && openCont[n-1].depth == depth {
+				openCont = openCont[:n-1]
+			}
+			if excludeDepth == depth {
+				excludeDepth = 0
+			}
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// Finish 継続要素を連結し、変換済みの値でインスタンスを確定する
+// 値を変換できないファクトはログに出して除外し、書類の他のファクトは使う。
+func (r *IXBRLReader) Finish(ctx context.Context) (*models.XBRLInstance, error) {
+	for _, f := range r.facts {
+		text := f.text.String()
+
+		// ix:continuationを順にたどって連結（循環参照は打ち切る）
+		seen := make(map[string]bool)
+		for next := f.continuedAt; next != "" && !seen[next]; {
+			seen[next] = true
+			cont, ok := r.continuations[next]
+			if !ok {
+				return nil, fmt.Errorf("継続要素が見つかりません: %s", next)
+			}
+			text += cont.text.String()
+			next = cont.continuedAt
+		}
+
+		if f.fact.Nil {
+			r.instance.AddFact(f.fact)
+			continue
+		}
+
+		value, err := transformValue(text, f.format, f.numeric)
+		if err != nil {
+			logging.FromContext(ctx).Warn("値を変換できないファクトを除外します", "name", f.fact.Name, "context", f.fact.ContextRef, "format", f.format, "error", err)
+			continue
+		}
+		if f.numeric {
+			value = applyScale(value, f.scale)
+			if f.sign == "-" && value != "0" {
+				value = "-" + value
+			}
+		}
+		f.fact.Value = value
+		r.instance.AddFact(f.fact)
+	}
+
+	return r.instance, nil
+}
+
+// fullWidthReplacer 全角の数字・記号を半角に変換
+var fullWidthReplacer = strings.NewReplacer(
+	"０", "0", "１", "1", "２", "2", "３", "3", "４", "4",
+	"５", "5", "６", "6", "７", "7", "８", "8", "９", "9",
+	"，", ",", "．", ".", "　", " ",
+)
+
+// eraDatePattern 和暦の日付（例: 令和6年3月31日）
+var eraDatePattern = regexp.MustCompile(`(明治|大正|昭和|平成|令和)\s*(元|\d+)\s*年\s*(\d+)\s*月\s*(\d+)\s*日`)
+
+// eraYearMonthPattern 和暦の年月（例: 令和6年3月）
+var eraYearMonthPattern = regexp.MustCompile(`(明治|大正|昭和|平成|令和)\s*(元|\d+)\s*年\s*(\d+)\s*月`)
+
+// cjkDatePattern 西暦の日付（例: 2024年3月31日）
+var cjkDatePattern = regexp.MustCompile(`(\d{4})\s*年\s*(\d+)\s*月\s*(\d+)\s*日`)
+
+// cjkYearMonthPattern 西暦の年月（例: 2024年3月）
+var cjkYearMonthPattern = regexp.MustCompile(`(\d{4})\s*年\s*(\d+)\s*月`)
+
+// cjkMonthDayPattern 月日（例: 3月31日）
+var cjkMonthDayPattern = regexp.MustCompile(`(\d+)\s*月\s*(\d+)\s*日`)
+
+// unitNumberPattern 単位を挟んだ数値（例: 12円34銭）の整数部と小数部
+var unitNumberPattern = regexp.MustCompile(`^([\d,]+)\s*[^\d\s,]+\s*(?:(\d+)\s*[^\d\s]*)?$`)
+
+// eraBaseYears 元号ごとの元年の前年
+var eraBaseYears = map[string]int{
+	"明治": 1867, "大正": 1911, "昭和": 1925, "平成": 1988, "令和": 2018,
+}
+
+// transformValue ix:formatに従って表示値をXBRLの値に変換
+func transformValue(text, format string, numeric bool) (string, error) {
+	text = strings.TrimSpace(fullWidthReplacer.Replace(text))
+
+	// 接頭辞（ixt / ixt-jpn等）は版によって異なるためローカル名で判定する
+	_, name, ok := strings.Cut(format, ":")
+	if !ok {
+		name = format
+	}
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "")
+
+	switch name {
+	case "":
+		if numeric {
+			return normalizeNumber(strings.ReplaceAll(text, ",", ""))
+		}
+		return text, nil
+	case "zerodash", "fixedzero":
+		return "0", nil
+	case "nocontent", "fixedempty":
+		return "", nil
+	case "booleantrue", "fixedtrue":
+		return "true", nil
+	case "booleanfalse", "fixedfalse":
+		return "false", nil
+	case "numdotdecimal", "numcommadot":
+		return normalizeNumber(strings.NewReplacer(",", "", " ", "").Replace(text))
+	case "numcommadecimal", "numdotcomma":
+		return normalizeNumber(strings.NewReplacer(".", "", " ", "", ",", ".").Replace(text))
+	case "dateyearmonthdaycjk", "datejpnyearmonthday":
+		m := cjkDatePattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("日付の形式が不正です: %s", text)
+		}
+		return formatDate(m[1], m[2], m[3])
+	case "dateerayearmonthdayjp", "dateerayearmonthdayjpn":
+		m := eraDatePattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("和暦の日付の形式が不正です: %s", text)
+		}
+		return formatDate(eraYear(m[1], m[2]), m[3], m[4])
+	case "dateyearmonthcjk", "datejpnyearmonth":
+		m := cjkYearMonthPattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("年月の形式が不正です: %s", text)
+		}
+		return formatYearMonth(m[1], m[2])
+	case "dateerayearmonthjp", "dateerayearmonthjpn":
+		m := eraYearMonthPattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("和暦の年月の形式が不正です: %s", text)
+		}
+		return formatYearMonth(eraYear(m[1], m[2]), m[3])
+	case "datemonthdayjp", "datemonthdayjpn", "datemonthdaycjk", "datejpnmonthday":
+		m := cjkMonthDayPattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("月日の形式が不正です: %s", text)
+		}
+		return formatMonthDay(m[1], m[2])
+	case "numunitdecimal", "numunitdecimaljp", "numunitdecimaljpn":
+		m := unitNumberPattern.FindStringSubmatch(text)
+		if m == nil {
+			return "", fmt.Errorf("単位付きの数値の形式が不正です: %s", text)
+		}
+		value := strings.ReplaceAll(m[1], ",", "")
+		if m[2] != "" {
+			value += "." + m[2]
+		}
+		return normalizeNumber(value)
+	default:
+		if numeric {
+			return normalizeNumber(strings.ReplaceAll(text, ",", ""))
+		}
+		return text, nil
+	}
+}
+
+// numberPattern 符号なしの10進数
+var numberPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// normalizeNumber 数値として妥当か確認する
+func normalizeNumber(s string) (string, error) {
+	if !numberPattern.MatchString(s) {
+		return "", fmt.Errorf("数値の形式が不正です: %q", s)
+	}
+	return s, nil
+}
+
+// eraYear 和暦の元号と年（「元」を含む）を西暦の年にする
+func eraYear(era, year string) string {
+	n := 1
+	if year != "元" {
+		n, _ = strconv.Atoi(year)
+	}
+	return strconv.Itoa(eraBaseYears[era] + n)
+}
+
+// formatYearMonth 年月をYYYY-MM形式（xs:gYearMonth）にする
+func formatYearMonth(year, month string) (string, error) {
+	y, err1 := strconv.Atoi(year)
+	m, err2 := strconv.Atoi(month)
+	if err1 != nil || err2 != nil || m < 1 || m > 12 {
+		return "", fmt.Errorf("年月の形式が不正です: %s-%s", year, month)
+	}
+	return fmt.Sprintf("%04d-%02d", y, m), nil
+}
+
+// formatMonthDay 月日を--MM-DD形式（xs:gMonthDay）にする
+func formatMonthDay(month, day string) (string, error) {
+	m, err1 := strconv.Atoi(month)
+	d, err2 := strconv.Atoi(day)
+	if err1 != nil || err2 != nil || m < 1 || m > 12 || d < 1 || d > 31 {
+		return "", fmt.Errorf("月日の形式が不正です: %s-%s", month, day)
+	}
+	return fmt.Sprintf("--%02d-%02d", m, d), nil
+}
+
+// formatDate 年月日をYYYY-MM-DD形式にする
+func formatDate(year, month, day string) (string, error) {
+	y, err1 := strconv.Atoi(year)
+	m, err2 := strconv.Atoi(month)
+	d, err3 := strconv.Atoi(day)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return "", fmt.Errorf("日付の形式が不正です: %s-%s-%s", year, month, day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", y, m, d), nil
+}
+
+// applyScale 10のscale乗を掛けた値を返す（小数点の移動で計算し誤差を出さない）
+func applyScale(value, scale string) string {
+	n, err := strconv.Atoi(scale)
+	if err != nil || n == 0 || value == "" {
+		return value
+	}
+
+	intPart, fracPart, _ := strings.Cut(value, ".")
+	digits := intPart + fracPart
+	point := len(intPart) + n
+
+	switch {
+	case point <= 0:
+		digits = strings.Repeat("0", 1-point) + digits
+		point = 1
+	case point > len(digits):
+		digits += strings.Repeat("0", point-len(digits))
+	}
+
+	intPart = strings.TrimLeft(digits[:point], "0")
+	fracPart = strings.TrimRight(digits[point:], "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if fracPart == "" {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
@@ -0,0 +1,358 @@
+package parser
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testIXBRLHeader = `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"
+  xmlns:ix="http://www.xbrl.org/2013/inlineXBRL"
+  xmlns:ixt="http://www.xbrl.org/inlineXBRL/transformation/2011-07-31"
+  xmlns:ixt-jpn="http://www.xbrl.org/inlineXBRL/transformation/jpn/2015-03-31"
+  xmlns:xbrli="http://www.xbrl.org/2003/instance"
+  xmlns:xbrldi="http://xbrl.org/2006/xbrldi"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xmlns:iso4217="http://www.xbrl.org/2003/iso4217"
+  xmlns:jpdei_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jpdei/2013-08-31/jpdei_cor"
+  xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor"
+  xmlns:jpcrp_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jpcrp/2024-11-01/jpcrp_cor">
+<head><title>表紙</title></head>
+<body>
+<div style="display:none"><ix:header><ix:hidden>
+  <ix:nonNumeric name="jpdei_cor:TypeOfCurrentPeriodDEI" contextRef="FilingDateInstant">FY</ix:nonNumeric>
+  <ix:nonNumeric name="jpdei_cor:CurrentFiscalYearStartDateDEI" contextRef="FilingDateInstant" format="ixt:dateyearmonthdaycjk">2024年4月1日</ix:nonNumeric>
+</ix:hidden>
+<ix:resources>
+  <xbrli:context id="FilingDateInstant">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00000-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:instant>2025-06-25</xbrli:instant></xbrli:period>
+  </xbrli:context>
+  <xbrli:context id="CurrentYearDuration">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00000-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:startDate>2024-04-01</xbrli:startDate><xbrli:endDate>2025-03-31</xbrli:endDate></xbrli:period>
+  </xbrli:context>
+  <xbrli:context id="CurrentYearDuration_NonConsolidatedMember">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00000-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:startDate>2024-04-01</xbrli:startDate><xbrli:endDate>2025-03-31</xbrli:endDate></xbrli:period>
+    <xbrli:scenario><xbrldi:explicitMember dimension="jppfs_cor:ConsolidatedOrNonConsolidatedAxis">jppfs_cor:NonConsolidatedMember</xbrldi:explicitMember></xbrli:scenario>
+  </xbrli:context>
+  <xbrli:unit id="JPY"><xbrli:measure>iso4217:JPY</xbrli:measure></xbrli:unit>
+</ix:resources></ix:header></div>
+<p>表紙&nbsp;<br>本文</p>
+</body></html>`
+
+const testIXBRLBody = `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"
+  xmlns:ix="http://www.xbrl.org/2013/inlineXBRL"
+  xmlns:ixt="http://www.xbrl.org/inlineXBRL/transformation/2011-07-31"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor"
+  xmlns:jpcrp_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jpcrp/2024-11-01/jpcrp_cor">
+<body>
+<table>
+  <tr><td>売上高</td><td><ix:nonFraction name="jppfs_cor:NetSales" contextRef="CurrentYearDuration" unitRef="JPY" decimals="-6" scale="6" format="ixt:numdotdecimal">1,234,567</ix:nonFraction></td></tr>
+  <tr><td>営業損失</td><td>△<ix:nonFraction name="jppfs_cor:OperatingIncome" contextRef="CurrentYearDuration" unitRef="JPY" decimals="-6" scale="6" sign="-" format="ixt:numdotdecimal">12,345</ix:nonFraction></td></tr>
+  <tr><td>特別利益</td><td><ix:nonFraction name="jppfs_cor:ExtraordinaryIncome" contextRef="CurrentYearDuration" unitRef="JPY" decimals="-6" scale="6" format="ixt:zerodash">―</ix:nonFraction></td></tr>
+  <tr><td>1株当たり当期純利益</td><td><ix:nonFraction name="jpcrp_cor:BasicEarningsLossPerShareSummaryOfBusinessResults" contextRef="CurrentYearDuration" unitRef="JPYPerShares" decimals="2" format="ixt:numcommadecimal">１２３，４５</ix:nonFraction></td></tr>
+  <tr><td>売上高（単体）</td><td><ix:nonFraction name="jppfs_cor:NetSales" contextRef="CurrentYearDuration_NonConsolidatedMember" unitRef="JPY" decimals="-3" scale="3" format="ixt:numdotdecimal"><span>987,654</span></ix:nonFraction></td></tr>
+  <tr><td>当期純利益</td><td><ix:nonFraction name="jppfs_cor:ProfitLoss" contextRef="CurrentYearDuration" unitRef="JPY" xsi:nil="true"></ix:nonFraction></td></tr>
+</table>
+<ix:nonNumeric name="jpcrp_cor:BusinessRisksTextBlock" contextRef="FilingDateInstant" continuedAt="c1"><p>事業等のリスク<ix:exclude>（注記）</ix:exclude>その1。</p></ix:nonNumeric>
+<ix:continuation id="c1" continuedAt="c2"><p>その2。</p></ix:continuation>
+</body></html>`
+
+const testIXBRLContinuation = `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:ix="http://www.xbrl.org/2013/inlineXBRL">
+<body><ix:continuation id="c2"><p>その3。</p></ix:continuation></body></html>`
+
+func readTestIXBRL(t *testing.T) *IXBRLReader {
+	t.Helper()
+	reader := NewIXBRLReader()
+	for _, doc := range []string{testIXBRLHeader, testIXBRLBody, testIXBRLContinuation} {
+		if err := reader.Read(strings.NewReader(doc)); err != nil {
+			t.Fatalf("iXBRL読み込みエラー: %v", err)
+		}
+	}
+	return reader
+}
+
+func TestIXBRLReader(t *testing.T) {
+	instance, err := readTestIXBRL(t).Finish(context.Background())
+	if err != nil {
+		t.Fatalf("iXBRL確定エラー: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		contextRef string
+		expected   string
+	}{
+		{"jppfs_cor:NetSales", "CurrentYearDuration", "1234567000000"},
+		{"jppfs_cor:OperatingIncome", "CurrentYearDuration", "-12345000000"},
+		{"jppfs_cor:ExtraordinaryIncome", "CurrentYearDuration", "0"},
+		{"jpcrp_cor:BasicEarningsLossPerShareSummaryOfBusinessResults", "CurrentYearDuration", "123.45"},
+		{"jppfs_cor:NetSales", "CurrentYearDuration_NonConsolidatedMember", "987654000"},
+		{"jpdei_cor:TypeOfCurrentPeriodDEI", "FilingDateInstant", "FY"},
+		{"jpdei_cor:CurrentFiscalYearStartDateDEI", "FilingDateInstant", "2024-04-01"},
+		{"jpcrp_cor:BusinessRisksTextBlock", "FilingDateInstant", "事業等のリスクその1。その2。その3。"},
+	}
+
+	for _, tc := range testCases {
+		found := false
+		for _, f := range instance.FactsByName(tc.name) {
+			if f.ContextRef != tc.contextRef {
+				continue
+			}
+			found = true
+			if f.Value != tc.expected {
+				t.Errorf("%s（%s）の値不一致: 期待=%s, 実際=%s", tc.name, tc.contextRef, tc.expected, f.Value)
+			}
+		}
+		if !found {
+			t.Errorf("%s（%s）が見つかりません", tc.name, tc.contextRef)
+		}
+	}
+
+	profit := instance.FactsByName("jppfs_cor:ProfitLoss")
+	if len(profit) != 1 || !profit[0].Nil {
+		t.Errorf("xsi:nilのファクトがNilになっていません: %+v", profit)
+	}
+
+	if len(instance.Contexts) != 3 {
+		t.Errorf("コンテキスト数不一致: 期待=3, 実際=%d", len(instance.Contexts))
+	}
+	if ctx := instance.Contexts["CurrentYearDuration_NonConsolidatedMember"]; ctx == nil || !ctx.IsNonConsolidated() {
+		t.Error("単体のコンテキストが正しく解析されていません")
+	}
+	if _, ok := instance.Units["JPY"]; !ok {
+		t.Error("単位JPYが見つかりません")
+	}
+	if v := instance.TaxonomyVersion(); v != "2024-11-01" {
+		t.Errorf("タクソノミーバージョン不一致: 期待=2024-11-01, 実際=%s", v)
+	}
+	if label := instance.DEI().FiscalPeriodLabel(); label != "2024年度" {
+		t.Errorf("会計期間不一致: 期待=2024年度, 実際=%s", label)
+	}
+}
+
+func TestIXBRLReader_MissingContinuation(t *testing.T) {
+	reader := NewIXBRLReader()
+	if err := reader.Read(strings.NewReader(testIXBRLBody)); err != nil {
+		t.Fatalf("iXBRL読み込みエラー: %v", err)
+	}
+	if _, err := reader.Finish(context.Background()); err == nil {
+		t.Error("継続要素が見つからない場合、エラーが発生すべきです")
+	}
+}
+
+func TestIXBRLReader_SkipsInvalidValue(t *testing.T) {
+	body := strings.Replace(testIXBRLBody, `format="ixt:numdotdecimal">12,345<`, `format="ixt:numdotdecimal">不明<`, 1)
+	reader := NewIXBRLReader()
+	for _, doc := range []string{testIXBRLHeader, body, testIXBRLContinuation} {
+		if err := reader.Read(strings.NewReader(doc)); err != nil {
+			t.Fatalf("iXBRL読み込みエラー: %v", err)
+		}
+	}
+	instance, err := reader.Finish(context.Background())
+	if err != nil {
+		t.Fatalf("値を変換できないファクトがあっても、書類は解析すべきです: %v", err)
+	}
+	if facts := instance.FactsByName("jppfs_cor:OperatingIncome"); len(facts) != 0 {
+		t.Errorf("値を変換できないファクトは除外すべきです: %+v", facts)
+	}
+	if facts := instance.FactsByName("jppfs_cor:NetSales"); len(facts) != 2 {
+		t.Errorf("他のファクトは残すべきです: %+v", facts)
+	}
+}
+
+func TestTransformValue(t *testing.T) {
+	testCases := []struct {
+		text     string
+		format   string
+		numeric  bool
+		expected string
+	}{
+		{"1,234", "ixt:numdotdecimal", true, "1234"},
+		{"1,234.5", "ixt:num-dot-decimal", true, "1234.5"},
+		{"1.234,5", "ixt:numcommadecimal", true, "1234.5"},
+		{"－", "ixt:fixed-zero", true, "0"},
+		{"―", "ixt:zerodash", true, "0"},
+		{"５００", "", true, "500"},
+		{"該当", "ixt:booleantrue", false, "true"},
+		{"2024年3月31日", "ixt:dateyearmonthdaycjk", false, "2024-03-31"},
+		{"令和6年3月31日", "ixt-jpn:dateerayearmonthdayjp", false, "2024-03-31"},
+		{"平成元年4月1日", "ixt-jpn:dateerayearmonthdayjp", false, "1989-04-01"},
+		{"2024年3月", "ixt:dateyearmonthcjk", false, "2024-03"},
+		{"２０２４年１２月", "ixt:dateyearmonthcjk", false, "2024-12"},
+		{"令和6年3月", "ixt-jpn:dateerayearmonthjp", false, "2024-03"},
+		{"令和元年5月", "ixt-jpn:dateerayearmonthjpn", false, "2019-05"},
+		{"3月31日", "ixt-jpn:datemonthdayjp", false, "--03-31"},
+		{"12月1日", "ixt:datemonthdaycjk", false, "--12-01"},
+		{"12円34銭", "ixt-jpn:numunitdecimaljp", true, "12.34"},
+		{"1,234円", "ixt:numunitdecimal", true, "1234"},
+		{"5株", "ixt:numunitdecimal", true, "5"},
+		{" テキスト ", "", false, "テキスト"},
+	}
+
+	for _, tc := range testCases {
+		result, err := transformValue(tc.text, tc.format, tc.numeric)
+		if err != nil {
+			t.Errorf("%q（%s）の変換エラー: %v", tc.text, tc.format, err)
+			continue
+		}
+		if result != tc.expected {
+			t.Errorf("%q（%s）の変換結果不一致: 期待=%s, 実際=%s", tc.text, tc.format, tc.expected, result)
+		}
+	}
+
+	errorCases := []struct {
+		text   string
+		format string
+	}{
+		{"abc", "ixt:numdotdecimal"},
+		{"2024年13月", "ixt:dateyearmonthcjk"},
+		{"3月", "ixt-jpn:datemonthdayjp"},
+		{"円", "ixt:numunitdecimal"},
+	}
+	for _, tc := range errorCases {
+		if _, err := transformValue(tc.text, tc.format, true); err == nil {
+			t.Errorf("%q（%s）は変換できないため、エラーが発生すべきです", tc.text, tc.format)
+		}
+	}
+}
+
+func TestApplyScale(t *testing.T) {
+	testCases := []struct {
+		value    string
+		scale    string
+		expected string
+	}{
+		{"1234", "", "1234"},
+		{"1234", "3", "1234000"},
+		{"12.5", "6", "12500000"},
+		{"1234", "-2", "12.34"},
+		{"5", "-3", "0.005"},
+		{"0", "6", "0"},
+	}
+
+	for _, tc := range testCases {
+		if result := applyScale(tc.value, tc.scale); result != tc.expected {
+			t.Errorf("applyScale(%s, %s)不一致: 期待=%s, 実際=%s", tc.value, tc.scale, tc.expected, result)
+		}
+	}
+}
+
+// writeTestZip テスト用のZIPファイルを作成
+func writeTestZip(t *testing.T, zipFile string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(zipFile)
+	if err != nil {
+		t.Fatalf("ZIPファイル作成エラー: %v", err)
+	}
+	defer out.Close()
+
+	zipWriter := zip.NewWriter(out)
+	for name, content := range files {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("ZIP内ファイル作成エラー: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("ZIP内ファイル書き込みエラー: %v", err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("ZIPファイルクローズエラー: %v", err)
+	}
+}
+
+func TestXBRLParser_ParseZipIXBRL(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_ixbrl.zip"
+	defer os.Remove(zipFile)
+
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<manifest xmlns="http://disclosure.edinet-fsa.go.jp/2013/manifest">
+  <list>
+    <instance id="ID_1" type="jpcrp" preferredFilename="jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25.xbrl">
+      <ixbrl>0000000_header_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm</ixbrl>
+      <ixbrl>0101010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm</ixbrl>
+      <ixbrl>0102010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm</ixbrl>
+    </instance>
+  </list>
+</manifest>`
+
+	writeTestZip(t, zipFile, map[string]string{
+		"XBRL/PublicDoc/manifest_PublicDoc.xml":                                                           manifest,
+		"XBRL/PublicDoc/0000000_header_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm": testIXBRLHeader,
+		"XBRL/PublicDoc/0101010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm": testIXBRLBody,
+		"XBRL/PublicDoc/0102010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm": testIXBRLContinuation,
+		"XBRL/AuditDoc/jpaud-aar-cn-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm":                    testIXBRLContinuation,
+	})
+
+	// .xbrlがないのでParseZipもiXBRLにフォールバックする
+	instance, err := parser.ParseZip(context.Background(), zipFile)
+	if err != nil {
+		t.Fatalf("iXBRL解析エラー: %v", err)
+	}
+
+	sales := instance.FactsByName("jppfs_cor:NetSales")
+	if len(sales) != 2 || sales[0].Value != "1234567000000" {
+		t.Errorf("売上高の解析結果が不正です: %+v", sales)
+	}
+}
+
+func TestXBRLParser_ParseZip_PrefersXBRL(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_xbrl_and_ixbrl.zip"
+	defer os.Remove(zipFile)
+
+	xbrl := `<?xml version="1.0" encoding="UTF-8"?>
+<xbrli:xbrl xmlns:xbrli="http://www.xbrl.org/2003/instance" xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor">
+  <jppfs_cor:NetSales contextRef="CurrentYearDuration" unitRef="JPY" decimals="-6">1234567000000</jppfs_cor:NetSales>
+</xbrli:xbrl>`
+
+	writeTestZip(t, zipFile, map[string]string{
+		"XBRL/PublicDoc/jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25.xbrl":                     xbrl,
+		"XBRL/PublicDoc/0101010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm": testIXBRLBody,
+	})
+
+	instance, err := parser.ParseZip(context.Background(), zipFile)
+	if err != nil {
+		t.Fatalf("XBRL解析エラー: %v", err)
+	}
+	if len(instance.Facts) != 1 {
+		t.Errorf(".xbrlが優先されていません: ファクト数=%d", len(instance.Facts))
+	}
+}
+
+func TestXBRLParser_ParseZipIXBRL_NotFound(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_empty_publicdoc.zip"
+	defer os.Remove(zipFile)
+
+	writeTestZip(t, zipFile, map[string]string{"XBRL/AuditDoc/audit.xbrl": "<xbrl/>"})
+
+	if _, err := parser.ParseZip(context.Background(), zipFile); err == nil {
+		t.Error("PublicDocにファイルがない場合、エラーが発生すべきです")
+	}
+}
+
+func TestXBRLParser_ParseZip_Canceled(t *testing.T) {
+	parser := NewXBRLParser()
+	zipFile := "test_canceled.zip"
+	defer os.Remove(zipFile)
+
+	writeTestZip(t, zipFile, map[string]string{
+		"XBRL/PublicDoc/0101010_honbun_jpcrp030000-asr-001_E00000-000_2025-03-31_01_2025-06-25_ixbrl.htm": testIXBRLBody,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := parser.ParseZip(ctx, zipFile); err != context.Canceled {
+		t.Errorf("context.Canceledが返されるべきです: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"edinet-api-test/internal/models"
+)
+
+// xmlMember xbrldi:explicitMember / xbrldi:typedMember
+type xmlMember struct {
+	Dimension string `xml:"dimension,attr"`
+	Value     string `xml:",chardata"`
+	Inner     string `xml:",innerxml"`
+}
+
+// xmlContext xbrli:context要素
+type xmlContext struct {
+	ID         string `xml:"id,attr"`
+	Identifier struct {
+		Scheme string `xml:"scheme,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"entity>identifier"`
+	StartDate       string      `xml:"period>startDate"`
+	EndDate         string      `xml:"period>endDate"`
+	Instant         string      `xml:"period>instant"`
+	Forever         *struct{}   `xml:"period>forever"`
+	ScenarioMembers []xmlMember `xml:"scenario>explicitMember"`
+	ScenarioTyped   []xmlMember `xml:"scenario>typedMember"`
+	SegmentMembers  []xmlMember `xml:"entity>segment>explicitMember"`
+	SegmentTyped    []xmlMember `xml:"entity>segment>typedMember"`
+}
+
+// toContext モデルのコンテキストに変換
+func (xc xmlContext) toContext() *models.Context {
+	ctx := &models.Context{
+		ID:     xc.ID,
+		Entity: strings.TrimSpace(xc.Identifier.Value),
+		Scheme: xc.Identifier.Scheme,
+	}
+
+	switch {
+	case strings.TrimSpace(xc.Instant) != "":
+		ctx.Period = models.Period{Type: models.PeriodInstant, Instant: strings.TrimSpace(xc.Instant)}
+	case xc.Forever != nil:
+		ctx.Period = models.Period{Type: models.PeriodForever}
+	default:
+		ctx.Period = models.Period{
+			Type:      models.PeriodDuration,
+			StartDate: strings.TrimSpace(xc.StartDate),
+			EndDate:   strings.TrimSpace(xc.EndDate),
+		}
+	}
+
+	for _, members := range [][]xmlMember{xc.ScenarioMembers, xc.SegmentMembers} {
+		for _, m := range members {
+			ctx.Scenario = append(ctx.Scenario, models.DimensionMember{
+				Dimension: m.Dimension,
+				Member:    strings.TrimSpace(m.Value),
+			})
+		}
+	}
+	for _, members := range [][]xmlMember{xc.ScenarioTyped, xc.SegmentTyped} {
+		for _, m := range members {
+			ctx.Scenario = append(ctx.Scenario, models.DimensionMember{
+				Dimension: m.Dimension,
+				Member:    strings.TrimSpace(m.Inner),
+				Typed:     true,
+			})
+		}
+	}
+
+	return ctx
+}
+
+// xmlUnit xbrli:unit要素
+type xmlUnit struct {
+	ID          string   `xml:"id,attr"`
+	Measures    []string `xml:"measure"`
+	Numerator   []string `xml:"divide>unitNumerator>measure"`
+	Denominator []string `xml:"divide>unitDenominator>measure"`
+}
+
+// toUnit モデルの単位に変換
+func (xu xmlUnit) toUnit() *models.Unit {
+	unit := &models.Unit{ID: xu.ID}
+	for _, m := range append(xu.Measures, xu.Numerator...) {
+		unit.Measures = append(unit.Measures, strings.TrimSpace(m))
+	}
+	for _, m := range xu.Denominator {
+		unit.Denominator = append(unit.Denominator, strings.TrimSpace(m))
+	}
+	return unit
+}
+
+// attrValue 要素の属性値をローカル名で取得
+func attrValue(se xml.StartElement, local string) string {
+	for _, attr := range se.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// qualifiedName 名前空間URIを接頭辞に置き換えた要素名を返す
+func qualifiedName(name xml.Name, prefixes map[string]string) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	if prefix, ok := prefixes[name.Space]; ok {
+		if prefix == "" {
+			return name.Local
+		}
+		return prefix + ":" + name.Local
+	}
+	// 宣言されていない接頭辞はそのまま使う
+	return name.Space + ":" + name.Local
+}
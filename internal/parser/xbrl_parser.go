@@ -2,13 +2,20 @@ package parser
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"edinet-api-test/internal/doctype"
+	"edinet-api-test/internal/logging"
+	"edinet-api-test/internal/models"
 )
 
 // XBRLParser XBRLファイル解析器
@@ -19,103 +26,215 @@ func NewXBRLParser() *XBRLParser {
 	return &XBRLParser{}
 }
 
-// ExtractPublicDocXBRL ZIPからPublicDocのXBRLファイルを抽出
-func (x *XBRLParser) ExtractPublicDocXBRL(zipFile string) (string, error) {
+// ParseZip ZIPのPublicDocからインスタンスを解析（.xbrlを優先し、なければiXBRLを使う）
+func (x *XBRLParser) ParseZip(ctx context.Context, zipFile string) (*models.XBRLInstance, error) {
+	instance, err := x.ParseZipXBRL(ctx, zipFile)
+	if err == nil {
+		return instance, nil
+	}
+	if err != errXBRLNotFound {
+		return nil, err
+	}
+	logging.FromContext(ctx).Debug(".xbrlがないためiXBRLを解析します", "zip", filepath.Base(zipFile))
+	return x.ParseZipIXBRL(ctx, zipFile)
+}
+
+// errXBRLNotFound PublicDocに.xbrlファイルがない
+var errXBRLNotFound = fmt.Errorf("PublicDocのxbrlファイルが見つかりません")
+
+// ParseZipXBRL ZIPのPublicDocにある.xbrlファイルを解析
+func (x *XBRLParser) ParseZipXBRL(ctx context.Context, zipFile string) (*models.XBRLInstance, error) {
 	r, err := zip.OpenReader(zipFile)
 	if err != nil {
-		return "", fmt.Errorf("ZIPファイルオープンエラー: %v", err)
+		return nil, fmt.Errorf("ZIPファイルオープンエラー: %v", err)
 	}
 	defer r.Close()
-	
+
 	for _, f := range r.File {
-		if strings.Contains(f.Name, "PublicDoc") && strings.HasSuffix(f.Name, ".xbrl") {
-			outPath := filepath.Base(f.Name)
-			out, err := os.Create(outPath)
-			if err != nil {
-				return "", fmt.Errorf("ファイル作成エラー: %v", err)
-			}
-			
-			in, err := f.Open()
-			if err != nil {
-				out.Close()
-				return "", fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
-			}
-			
-			_, err = io.Copy(out, in)
-			in.Close()
-			out.Close()
-			
-			if err != nil {
-				return "", fmt.Errorf("ファイルコピーエラー: %v", err)
-			}
-			
-			return outPath, nil
+		if !isPublicDoc(f.Name) || !strings.HasSuffix(f.Name, ".xbrl") {
+			continue
 		}
+		in, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
+		}
+		defer in.Close()
+		logging.FromContext(ctx).Debug("XBRLを解析", "file", path.Base(f.Name))
+		return x.ParseXBRL(ctx, in)
 	}
-	
-	return "", fmt.Errorf("PublicDocのxbrlファイルが見つかりません")
+
+	return nil, errXBRLNotFound
 }
 
-// ParseAllXBRL XBRLファイルから全ての値を抽出
-func (x *XBRLParser) ParseAllXBRL(xbrlPath string) (map[string]string, error) {
+// ParseZipIXBRL ZIPのPublicDocにあるiXBRL（*_ixbrl.htm）を解析
+//
+// manifest_PublicDoc.xmlがあれば記載順に、なければファイル名順に読み込む。
+func (x *XBRLParser) ParseZipIXBRL(ctx context.Context, zipFile string) (*models.XBRLInstance, error) {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("ZIPファイルオープンエラー: %v", err)
+	}
+	defer r.Close()
+
+	// PublicDoc内のファイルをディレクトリからの相対パスで引けるようにする
+	files := make(map[string]*zip.File)
+	var ixbrlNames []string
+	var manifestFile *zip.File
+	for _, f := range r.File {
+		if !isPublicDoc(f.Name) {
+			continue
+		}
+		base := path.Base(f.Name)
+		files[base] = f
+		switch {
+		case base == "manifest_PublicDoc.xml":
+			manifestFile = f
+		case strings.HasSuffix(base, "_ixbrl.htm"):
+			ixbrlNames = append(ixbrlNames, base)
+		}
+	}
+	sort.Strings(ixbrlNames)
+
+	if manifestFile != nil {
+		in, err := manifestFile.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
+		}
+		manifest, err := x.ParseManifest(in)
+		in.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(manifest.Instances) > 0 && len(manifest.Instances[0].IXBRL) > 0 {
+			ixbrlNames = manifest.Instances[0].IXBRL
+		}
+	}
+
+	if len(ixbrlNames) == 0 {
+		return nil, fmt.Errorf("PublicDocのiXBRLファイルが見つかりません")
+	}
+
+	logging.FromContext(ctx).Debug("iXBRLを解析", "files", len(ixbrlNames), "manifest", manifestFile != nil)
+	reader := NewIXBRLReader()
+	for _, name := range ixbrlNames {
+		f, ok := files[path.Base(name)]
+		if !ok {
+			return nil, fmt.Errorf("マニフェストに記載されたiXBRLファイルが見つかりません: %s", name)
+		}
+		in, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("ZIP内ファイルオープンエラー: %v", err)
+		}
+		err = reader.Read(contextReader{ctx, in})
+		in.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return reader.Finish(ctx)
+}
+
+// contextReader ctxが終了したら読み込みを止めるReader
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// isPublicDoc ZIP内のパスがPublicDoc配下かどうか
+func isPublicDoc(name string) bool {
+	return strings.Contains(name, "PublicDoc")
+}
+
+// ParseAllXBRL XBRLファイルからコンテキスト・単位・ファクトを抽出
+func (x *XBRLParser) ParseAllXBRL(ctx context.Context, xbrlPath string) (*models.XBRLInstance, error) {
 	file, err := os.Open(xbrlPath)
 	if err != nil {
 		return nil, fmt.Errorf("XBRLファイルオープンエラー: %v", err)
 	}
 	defer file.Close()
 
-	decoder := xml.NewDecoder(file)
-	values := make(map[string]string)
-	var currentKey string
-	
+	return x.ParseXBRL(ctx, file)
+}
+
+// ParseXBRL XBRLインスタンス文書を読み込んで解析（ctxが終了した場合はそのエラーを返す）
+func (x *XBRLParser) ParseXBRL(ctx context.Context, r io.Reader) (*models.XBRLInstance, error) {
+	decoder := xml.NewDecoder(contextReader{ctx, r})
+	instance := models.NewXBRLInstance()
+	prefixes := make(map[string]string) // 名前空間URI → 接頭辞
+
 	for {
 		tok, err := decoder.Token()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			return nil, fmt.Errorf("XMLデコードエラー: %v", err)
 		}
-		
-		switch se := tok.(type) {
-		case xml.StartElement:
-			tagName := se.Name.Local
-			if se.Name.Space != "" {
-				tagName = se.Name.Space + ":" + se.Name.Local
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range se.Attr {
+			switch {
+			case attr.Name.Space == "xmlns":
+				prefixes[attr.Value] = attr.Name.Local
+				instance.Namespaces[attr.Name.Local] = attr.Value
+			case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+				prefixes[attr.Value] = ""
 			}
-			
-			contextRef := ""
-			unitRef := ""
-			for _, attr := range se.Attr {
-				if attr.Name.Local == "contextRef" {
-					contextRef = attr.Value
-				}
-				if attr.Name.Local == "unitRef" {
-					unitRef = attr.Value
-				}
+		}
+
+		switch {
+		case se.Name.Local == "context":
+			var xc xmlContext
+			if err := decoder.DecodeElement(&xc, &se); err != nil {
+				return nil, fmt.Errorf("コンテキスト解析エラー: %v", err)
 			}
-			
-			key := tagName
-			if contextRef != "" {
-				key += "|contextRef=" + contextRef
+			instance.AddContext(xc.toContext())
+
+		case se.Name.Local == "unit":
+			var xu xmlUnit
+			if err := decoder.DecodeElement(&xu, &se); err != nil {
+				return nil, fmt.Errorf("単位解析エラー: %v", err)
 			}
-			if unitRef != "" {
-				key += "|unitRef=" + unitRef
+			instance.AddUnit(xu.toUnit())
+
+		case attrValue(se, "contextRef") != "":
+			var content struct {
+				Value string `xml:",chardata"`
 			}
-			currentKey = key
-			
-		case xml.CharData:
-			val := strings.TrimSpace(string(se))
-			if val != "" && currentKey != "" {
-				values[currentKey] = val
+			if err := decoder.DecodeElement(&content, &se); err != nil {
+				return nil, fmt.Errorf("ファクト解析エラー: %v", err)
 			}
-			
-		case xml.EndElement:
-			currentKey = ""
+			instance.AddFact(models.Fact{
+				Name:       qualifiedName(se.Name, prefixes),
+				Namespace:  se.Name.Space,
+				ContextRef: attrValue(se, "contextRef"),
+				UnitRef:    attrValue(se, "unitRef"),
+				Decimals:   attrValue(se, "decimals"),
+				Nil:        attrValue(se, "nil") == "true",
+				Value:      strings.TrimSpace(content.Value),
+			})
 		}
 	}
-	
-	return values, nil
+
+	return instance, nil
 }
 
 // ExtractAccountingPeriod XBRLファイルから会計期間の情報を抽出
@@ -219,7 +338,8 @@ func (x *XBRLParser) GetQuarterInfo(startDate, endDate string) string {
 	}
 }
 
-// GetCorrectFiscalPeriod 提出日と文書タイプから正しい会計期間を計算
+// GetCorrectFiscalPeriod 提出日と文書タイプから会計期間を推定
+// DEI（models.DEI.FiscalPeriodLabel）から会計期間を取得できない場合の代替として使う
 func (x *XBRLParser) GetCorrectFiscalPeriod(submitDate string, docTypeCode string) string {
 	layout := "2006-01-02"
 	submit, err := time.Parse(layout, submitDate)
@@ -227,15 +347,13 @@ func (x *XBRLParser) GetCorrectFiscalPeriod(submitDate string, docTypeCode strin
 		return "不明"
 	}
 	
-	docTypeName := x.GetDocTypeName(docTypeCode)
-	
-	switch docTypeName {
-	case "有価証券報告書":
+	switch doctype.CategoryOf(docTypeCode) {
+	case doctype.CategoryAnnual:
 		// 有価証券報告書の場合：提出年の前年度が会計期間
 		fiscalYear := submit.Year() - 1
 		return fmt.Sprintf("%d年度", fiscalYear)
 		
-	case "四半期報告書":
+	case doctype.CategoryQuarterly:
 		// 四半期報告書の場合：提出月から四半期を推定
 		fiscalYear := submit.Year() - 1
 		month := submit.Month()
@@ -256,36 +374,47 @@ func (x *XBRLParser) GetCorrectFiscalPeriod(submitDate string, docTypeCode strin
 		
 		return fmt.Sprintf("%d年度第%d四半期", fiscalYear, quarter)
 		
+	case doctype.CategorySemiAnnual:
+		// 半期報告書の場合：中間期末から3か月以内に提出されるため、1-3月提出は前年度
+		fiscalYear := submit.Year()
+		if submit.Month() <= 3 {
+			fiscalYear--
+		}
+		return fmt.Sprintf("%d年度中間期", fiscalYear)
+
 	default:
 		// その他の文書タイプの場合は従来の方法を使用
 		return "不明"
 	}
 }
 
-// GetDocTypeName 文書タイプコードを日本語名に変換
-func (x *XBRLParser) GetDocTypeName(docTypeCode string) string {
-	switch docTypeCode {
-	case "120":
-		return "有価証券報告書"
-	case "130":
-		return "四半期報告書"
-	case "140":
-		return "有価証券届出書"
-	case "150":
-		return "発行登録書"
-	case "160":
-		return "発行登録追補書類"
-	case "170":
-		return "訂正有価証券報告書"
-	case "180":
-		return "訂正四半期報告書"
-	case "190":
-		return "訂正有価証券届出書"
-	case "200":
-		return "有価証券報告書（外国会社）"
-	case "210":
-		return "四半期報告書（外国会社）"
+// GetFiscalPeriodFromDoc 書類一覧の期間から会計期間を推定（DEIがない場合の代替）
+// 有価証券報告書は期間（自）の年を会計年度とし、それ以外は提出日から推定する
+func (x *XBRLParser) GetFiscalPeriodFromDoc(doc models.DocInfo, submitDate string) string {
+	if doctype.CategoryOf(doc.DocTypeCode) == doctype.CategoryAnnual {
+		if start, err := time.Parse("2006-01-02", doc.PeriodStart); err == nil {
+			return fmt.Sprintf("%d年度", start.Year())
+		}
+	}
+	if date := doc.SubmitDate(); date != "" {
+		submitDate = date
+	}
+	return x.GetCorrectFiscalPeriod(submitDate, doc.DocTypeCode)
+}
+
+// GetReportKind 文書タイプコードからコンテキスト選択用の報告書の種類を判定
+func (x *XBRLParser) GetReportKind(docTypeCode string) models.ReportKind {
+	switch doctype.CategoryOf(docTypeCode) {
+	case doctype.CategoryQuarterly:
+		return models.ReportQuarterly
+	case doctype.CategorySemiAnnual:
+		return models.ReportSemiAnnual
 	default:
-		return "その他"
+		return models.ReportAnnual
 	}
+}
+
+// GetDocTypeName 文書タイプコードを日本語名に変換
+func (x *XBRLParser) GetDocTypeName(docTypeCode string) string {
+	return doctype.Name(docTypeCode)
 } 
\ No newline at end of file
@@ -1,10 +1,12 @@
 package parser
 
 import (
-	"archive/zip"
+	"context"
 	"os"
 	"strings"
 	"testing"
+
+	"edinet-api-test/internal/models"
 )
 
 func TestNewXBRLParser(t *testing.T) {
@@ -21,16 +23,16 @@ func TestXBRLParser_GetDocTypeName(t *testing.T) {
 		docTypeCode string
 		expected    string
 	}{
+		{"030", "有価証券届出書"},
+		{"080", "発行登録書"},
+		{"100", "発行登録追補書類"},
 		{"120", "有価証券報告書"},
-		{"130", "四半期報告書"},
-		{"140", "有価証券届出書"},
-		{"150", "発行登録書"},
-		{"160", "発行登録追補書類"},
-		{"170", "訂正有価証券報告書"},
-		{"180", "訂正四半期報告書"},
-		{"190", "訂正有価証券届出書"},
-		{"200", "有価証券報告書（外国会社）"},
-		{"210", "四半期報告書（外国会社）"},
+		{"130", "訂正有価証券報告書"},
+		{"140", "四半期報告書"},
+		{"150", "訂正四半期報告書"},
+		{"160", "半期報告書"},
+		{"170", "訂正半期報告書"},
+		{"180", "臨時報告書"},
 		{"999", "その他"},
 		{"", "その他"},
 	}
@@ -43,6 +45,62 @@ func TestXBRLParser_GetDocTypeName(t *testing.T) {
 	}
 }
 
+func TestXBRLParser_GetReportKind(t *testing.T) {
+	parser := NewXBRLParser()
+
+	if parser.GetReportKind("120") != models.ReportAnnual {
+		t.Error("有価証券報告書は通期として扱われるべきです")
+	}
+	if parser.GetReportKind("130") != models.ReportAnnual {
+		t.Error("訂正有価証券報告書は通期として扱われるべきです")
+	}
+	if parser.GetReportKind("140") != models.ReportQuarterly {
+		t.Error("四半期報告書は四半期として扱われるべきです")
+	}
+	if parser.GetReportKind("160") != models.ReportSemiAnnual {
+		t.Error("半期報告書は半期として扱われるべきです")
+	}
+}
+
+func TestXBRLParser_GetCorrectFiscalPeriod(t *testing.T) {
+	parser := NewXBRLParser()
+
+	testCases := []struct {
+		submitDate  string
+		docTypeCode string
+		expected    string
+	}{
+		{"2025-06-25", "120", "2024年度"},
+		{"2023-08-10", "140", "2023年度第1四半期"},
+		{"2025-11-12", "160", "2025年度中間期"},
+		{"2026-02-13", "160", "2025年度中間期"},
+		{"2025-06-25", "180", "不明"},
+		{"invalid", "120", "不明"},
+	}
+
+	for _, tc := range testCases {
+		if result := parser.GetCorrectFiscalPeriod(tc.submitDate, tc.docTypeCode); result != tc.expected {
+			t.Errorf("%s/%s: 期待=%s, 実際=%s", tc.submitDate, tc.docTypeCode, tc.expected, result)
+		}
+	}
+}
+
+func TestXBRLParser_GetFiscalPeriodFromDoc(t *testing.T) {
+	parser := NewXBRLParser()
+
+	// 提出が遅れた有価証券報告書も期間から会計年度を判定する（提出日からの推定では2024年度になる）
+	annual := models.DocInfo{DocTypeCode: "120", PeriodStart: "2023-04-01", PeriodEnd: "2024-03-31", SubmitDateTime: "2025-02-10 15:00"}
+	if result := parser.GetFiscalPeriodFromDoc(annual, "2025-02-10"); result != "2023年度" {
+		t.Errorf("期待=2023年度, 実際=%s", result)
+	}
+
+	// 期間がない場合は提出日から推定
+	semi := models.DocInfo{DocTypeCode: "160", SubmitDateTime: "2025-11-12 15:00"}
+	if result := parser.GetFiscalPeriodFromDoc(semi, "2025-11-13"); result != "2025年度中間期" {
+		t.Errorf("期待=2025年度中間期, 実際=%s", result)
+	}
+}
+
 func TestXBRLParser_GetQuarterInfo(t *testing.T) {
 	parser := NewXBRLParser()
 
@@ -161,30 +219,25 @@ func TestXBRLParser_ParseAllXBRL(t *testing.T) {
 	tmpFile.Close()
 
 	// テスト実行
-	values, err := parser.ParseAllXBRL(tmpFile.Name())
+	values, err := parser.ParseAllXBRL(context.Background(), tmpFile.Name())
 	if err != nil {
 		t.Fatalf("XBRL解析エラー: %v", err)
 	}
 
-	// 結果を検証（実際に抽出されたキーを使用）
-	foundKeys := 0
-	for k, v := range values {
-		if strings.Contains(k, "NetSales") && v == "1000000000" {
-			foundKeys++
-		}
-		if strings.Contains(k, "ProfitLoss") && v == "100000000" {
-			foundKeys++
-		}
-		if strings.Contains(k, "TotalAssets") && v == "2000000000" {
-			foundKeys++
-		}
+	// 結果を検証（接頭辞付きの要素名で取得）
+	expected := map[string]string{
+		"jppfs_cor:NetSales":    "1000000000",
+		"jppfs_cor:ProfitLoss":  "100000000",
+		"jppfs_cor:TotalAssets": "2000000000",
 	}
-
-	if foundKeys < 3 {
-		t.Errorf("期待される値が見つかりません。見つかったキー数: %d", foundKeys)
-		t.Logf("実際に抽出されたキー:")
-		for k, v := range values {
-			t.Logf("  %s = %s", k, v)
+	for name, want := range expected {
+		facts := values.FactsByName(name)
+		if len(facts) != 1 {
+			t.Errorf("%s: ファクト数不一致: 期待=1, 実際=%d", name, len(facts))
+			continue
+		}
+		if facts[0].Value != want || facts[0].ContextRef != "CurrentYearDuration" || facts[0].UnitRef != "JPY" {
+			t.Errorf("%s: 期待=%s, 実際=%+v", name, want, facts[0])
 		}
 	}
 }
@@ -192,77 +245,12 @@ func TestXBRLParser_ParseAllXBRL(t *testing.T) {
 func TestXBRLParser_ParseAllXBRL_FileNotFound(t *testing.T) {
 	parser := NewXBRLParser()
 
-	_, err := parser.ParseAllXBRL("nonexistent_file.xbrl")
+	_, err := parser.ParseAllXBRL(context.Background(), "nonexistent_file.xbrl")
 	if err == nil {
 		t.Error("ファイルが存在しない場合、エラーが発生すべきです")
 	}
 }
 
-func TestXBRLParser_ExtractPublicDocXBRL(t *testing.T) {
-	parser := NewXBRLParser()
-
-	// テスト用のZIPファイルを作成
-	zipFile := "test_publicdoc.zip"
-	defer os.Remove(zipFile)
-
-	// 実際のZIPファイルを作成するのは複雑なので、
-	// 存在しないファイルでテスト
-	_, err := parser.ExtractPublicDocXBRL("nonexistent.zip")
-	if err == nil {
-		t.Error("存在しないZIPファイルの場合、エラーが発生すべきです")
-	}
-}
-
-func TestXBRLParser_ExtractPublicDocXBRL_WithRealZip(t *testing.T) {
-	parser := NewXBRLParser()
-
-	// テスト用のZIPファイルを作成
-	zipFile := "test_publicdoc.zip"
-	defer os.Remove(zipFile)
-
-	// 実際のZIPファイルを作成
-	zipFileWriter, err := os.Create(zipFile)
-	if err != nil {
-		t.Fatalf("ZIPファイル作成エラー: %v", err)
-	}
-
-	zipWriter := zip.NewWriter(zipFileWriter)
-
-	// PublicDocのXBRLファイルを追加
-	xbrlWriter, err := zipWriter.Create("PublicDoc_Test.xbrl")
-	if err != nil {
-		t.Fatalf("XBRLファイル作成エラー: %v", err)
-	}
-
-	testXBRL := `<?xml version="1.0" encoding="UTF-8"?>
-<xbrli:xbrl xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor">
-  <jppfs_cor:NetSales contextRef="CurrentYearDuration" unitRef="JPY">1000000000</jppfs_cor:NetSales>
-</xbrli:xbrl>`
-
-	_, err = xbrlWriter.Write([]byte(testXBRL))
-	if err != nil {
-		t.Fatalf("XBRLデータ書き込みエラー: %v", err)
-	}
-
-	// ZIPファイルを閉じる
-	err = zipWriter.Close()
-	if err != nil {
-		t.Fatalf("ZIPファイルクローズエラー: %v", err)
-	}
-	zipFileWriter.Close()
-
-	// テスト実行
-	xbrlPath, err := parser.ExtractPublicDocXBRL(zipFile)
-	if err != nil {
-		t.Fatalf("XBRL抽出エラー: %v", err)
-	}
-	defer os.Remove(xbrlPath)
-
-	if !strings.Contains(xbrlPath, "PublicDoc_Test.xbrl") {
-		t.Errorf("XBRLファイル名不一致: 期待=PublicDoc_Test.xbrlを含む, 実際=%s", xbrlPath)
-	}
-}
-
 func TestXBRLParser_ParseAllXBRL_Complex(t *testing.T) {
 	parser := NewXBRLParser()
 
@@ -301,43 +289,107 @@ func TestXBRLParser_ParseAllXBRL_Complex(t *testing.T) {
 	tmpFile.Close()
 
 	// テスト実行
-	values, err := parser.ParseAllXBRL(tmpFile.Name())
+	values, err := parser.ParseAllXBRL(context.Background(), tmpFile.Name())
 	if err != nil {
 		t.Fatalf("XBRL解析エラー: %v", err)
 	}
 
 	// 結果を検証
 	expectedCount := 6 // NetSales, GrossProfit, OperatingIncome, ProfitLoss, TotalAssets, NetSalesTextBlock
-	if len(values) < expectedCount {
-		t.Errorf("抽出された値の数が少なすぎます: 期待>=%d, 実際=%d", expectedCount, len(values))
+	if len(values.Facts) < expectedCount {
+		t.Errorf("抽出された値の数が少なすぎます: 期待>=%d, 実際=%d", expectedCount, len(values.Facts))
 	}
 
-	// 特定の値が含まれているか確認
-	foundValues := 0
-	for k, v := range values {
-		if strings.Contains(k, "NetSales") && !strings.Contains(k, "TextBlock") && v == "1000000000" {
-			foundValues++
-		}
-		if strings.Contains(k, "GrossProfit") && v == "500000000" {
-			foundValues++
-		}
-		if strings.Contains(k, "OperatingIncome") && v == "200000000" {
-			foundValues++
-		}
-		if strings.Contains(k, "ProfitLoss") && v == "100000000" {
-			foundValues++
-		}
-		if strings.Contains(k, "TotalAssets") && v == "2000000000" {
-			foundValues++
-		}
+	// コンテキストの期間が解決されているか確認
+	ctx := values.Contexts["CurrentYearDuration"]
+	if ctx == nil {
+		t.Fatal("CurrentYearDurationコンテキストが見つかりません")
+	}
+	if ctx.Period.Type != models.PeriodDuration || ctx.Period.StartDate != "2024-04-01" || ctx.Period.EndDate != "2025-03-31" {
+		t.Errorf("期間不一致: %+v", ctx.Period)
+	}
+	if ctx.Entity != "E00763-000" {
+		t.Errorf("エンティティ不一致: 期待=E00763-000, 実際=%s", ctx.Entity)
 	}
 
-	if foundValues < 4 {
-		t.Errorf("期待される値が見つかりません。見つかった値数: %d", foundValues)
-		t.Logf("実際に抽出されたキー:")
-		for k, v := range values {
-			t.Logf("  %s = %s", k, v)
-		}
+	// TextBlockは別要素として扱われ、NetSalesと混ざらないことを確認
+	fact, ok := values.Find("jppfs_cor:NetSales", models.FactQuery{})
+	if !ok || fact.Value != "1000000000" {
+		t.Errorf("NetSales不一致: 期待=1000000000, 実際=%+v", fact)
+	}
+	if len(values.FactsByName("jppfs_cor:NetSalesTextBlock")) != 1 {
+		t.Error("NetSalesTextBlockが見つかりません")
+	}
+}
+
+func TestXBRLParser_ParseAllXBRL_ContextsAndUnits(t *testing.T) {
+	parser := NewXBRLParser()
+
+	testXBRL := `<?xml version="1.0" encoding="UTF-8"?>
+<xbrli:xbrl xmlns:xbrli="http://www.xbrl.org/2003/instance"
+            xmlns:xbrldi="http://xbrl.org/2006/xbrldi"
+            xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+            xmlns:iso4217="http://www.xbrl.org/2003/iso4217"
+            xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor"
+            xmlns:jpcrp_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jpcrp/2024-11-01/jpcrp_cor">
+  <xbrli:context id="CurrentYearInstant">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00763-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:instant>2025-03-31</xbrli:instant></xbrli:period>
+  </xbrli:context>
+  <xbrli:context id="CurrentYearInstant_NonConsolidatedMember">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00763-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:instant>2025-03-31</xbrli:instant></xbrli:period>
+    <xbrli:scenario>
+      <xbrldi:explicitMember dimension="jppfs_cor:ConsolidatedOrNonConsolidatedAxis">jppfs_cor:NonConsolidatedMember</xbrldi:explicitMember>
+    </xbrli:scenario>
+  </xbrli:context>
+  <xbrli:unit id="JPY"><xbrli:measure>iso4217:JPY</xbrli:measure></xbrli:unit>
+  <xbrli:unit id="JPYPerShares">
+    <xbrli:divide>
+      <xbrli:unitNumerator><xbrli:measure>iso4217:JPY</xbrli:measure></xbrli:unitNumerator>
+      <xbrli:unitDenominator><xbrli:measure>xbrli:shares</xbrli:measure></xbrli:unitDenominator>
+    </xbrli:divide>
+  </xbrli:unit>
+  <jppfs_cor:TotalAssets contextRef="CurrentYearInstant_NonConsolidatedMember" unitRef="JPY" decimals="-6">800000000</jppfs_cor:TotalAssets>
+  <jppfs_cor:TotalAssets contextRef="CurrentYearInstant" unitRef="JPY" decimals="-6">2000000000</jppfs_cor:TotalAssets>
+  <jpcrp_cor:NumberOfEmployees contextRef="CurrentYearInstant" unitRef="pure" xsi:nil="true"/>
+</xbrli:xbrl>`
+
+	values, err := parser.ParseXBRL(context.Background(), strings.NewReader(testXBRL))
+	if err != nil {
+		t.Fatalf("XBRL解析エラー: %v", err)
+	}
+
+	nonCons := values.Contexts["CurrentYearInstant_NonConsolidatedMember"]
+	if nonCons == nil || !nonCons.IsNonConsolidated() {
+		t.Fatalf("単体コンテキストが認識されません: %+v", nonCons)
+	}
+	if nonCons.Period.Type != models.PeriodInstant || nonCons.Period.Instant != "2025-03-31" {
+		t.Errorf("時点不一致: %+v", nonCons.Period)
+	}
+
+	unit := values.Units["JPYPerShares"]
+	if unit == nil || len(unit.Measures) != 1 || unit.Measures[0] != "iso4217:JPY" ||
+		len(unit.Denominator) != 1 || unit.Denominator[0] != "xbrli:shares" {
+		t.Errorf("単位不一致: %+v", unit)
+	}
+
+	cons, ok := values.Find("jppfs_cor:TotalAssets", models.FactQuery{Scope: models.ScopeConsolidated})
+	if !ok || cons.Value != "2000000000" || cons.Decimals != "-6" {
+		t.Errorf("連結総資産不一致: %+v", cons)
+	}
+	single, ok := values.Find("jppfs_cor:TotalAssets", models.FactQuery{Scope: models.ScopeNonConsolidated})
+	if !ok || single.Value != "800000000" {
+		t.Errorf("単体総資産不一致: %+v", single)
+	}
+
+	employees := values.FactsByName("jpcrp_cor:NumberOfEmployees")
+	if len(employees) != 1 || !employees[0].Nil || employees[0].Value != "" {
+		t.Errorf("nilファクト不一致: %+v", employees)
+	}
+
+	if values.TaxonomyVersion() != "2024-11-01" {
+		t.Errorf("タクソノミーバージョン不一致: 期待=2024-11-01, 実際=%s", values.TaxonomyVersion())
 	}
 }
 
@@ -390,4 +442,14 @@ func TestXBRLParser_ExtractAccountingPeriod_Complex(t *testing.T) {
 	if endDate != "2025-03-31" {
 		t.Errorf("終了日不一致: 期待=2025-03-31, 実際=%s", endDate)
 	}
-} 
\ No newline at end of file
+} 
+func TestXBRLParser_ParseXBRL_Canceled(t *testing.T) {
+	parser := NewXBRLParser()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := parser.ParseXBRL(ctx, strings.NewReader(`<xbrli:xbrl xmlns:xbrli="http://www.xbrl.org/2003/instance"></xbrli:xbrl>`))
+	if err != context.Canceled {
+		t.Errorf("context.Canceledが返されるべきです: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Ordered jobsをworkers個のゴルーチンで並行に処理し、結果を入力順にemitへ渡す
+//
+// emitは呼び出し元のゴルーチンから1つずつ呼ばれるため、CSVへの書き込みなど順序が必要な処理に使える。
+// 完了順が入れ替わっても待っている結果がたまり過ぎないよう、未出力の件数はworkersの2倍までに抑える。
+// ctxが終了した場合は新しいjobを開始せず、処理中のjobの結果を出力してから戻る
+// （中断されたjobの結果を捨てるかどうかはemitで判断する）。
+func Ordered[J, R any](ctx context.Context, workers int, jobs []J, process func(context.Context, J) R, emit func(J, R)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		index int
+		value R
+	}
+	indexes := make(chan int)
+	results := make(chan result)
+	window := make(chan struct{}, 2*workers)
+
+	// 未出力の件数が上限に達したら、先頭の結果が出力されるまで新しいjobを渡さない
+	go func() {
+		defer close(indexes)
+		for i := range jobs {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results <- result{index: i, value: process(ctx, jobs[i])}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]R)
+	next := 0
+	for r := range results {
+		pending[r.index] = r.value
+		for {
+			value, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			emit(jobs[next], value)
+			next++
+			<-window
+		}
+	}
+}
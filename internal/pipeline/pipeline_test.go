@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrdered_PreservesOrder(t *testing.T) {
+	jobs := make([]int, 50)
+	for i := range jobs {
+		jobs[i] = i
+	}
+
+	var got []int
+	Ordered(context.Background(), 8, jobs, func(ctx context.Context, job int) int {
+		// 後のjobほど早く終わるようにして完了順を入れ替える
+		time.Sleep(time.Duration(len(jobs)-job) * 100 * time.Microsecond)
+		return job * 10
+	}, func(job int, value int) {
+		if value != job*10 {
+			t.Errorf("job %d の結果不一致: %d", job, value)
+		}
+		got = append(got, job)
+	})
+
+	if len(got) != len(jobs) {
+		t.Fatalf("出力件数不一致: 期待=%d, 実際=%d", len(jobs), len(got))
+	}
+	for i, job := range got {
+		if job != i {
+			t.Fatalf("出力順が入力順と一致しません: %v", got)
+		}
+	}
+}
+
+func TestOrdered_BoundedConcurrency(t *testing.T) {
+	const workers = 3
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	jobs := make([]int, 30)
+	Ordered(context.Background(), workers, jobs, func(ctx context.Context, job int) int {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return job
+	}, func(int, int) {})
+
+	if maxRunning > workers {
+		t.Errorf("同時実行数が上限を超えています: 上限=%d, 実際=%d", workers, maxRunning)
+	}
+	if maxRunning < 2 {
+		t.Errorf("並行に処理されていません: 同時実行数=%d", maxRunning)
+	}
+}
+
+func TestOrdered_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs := make([]int, 100)
+	for i := range jobs {
+		jobs[i] = i
+	}
+
+	processed := 0
+	var mu sync.Mutex
+	var emitted []int
+	Ordered(ctx, 2, jobs, func(ctx context.Context, job int) int {
+		mu.Lock()
+		processed++
+		mu.Unlock()
+		if job == 5 {
+			cancel()
+		}
+		return job
+	}, func(job int, value int) {
+		emitted = append(emitted, job)
+	})
+
+	// 中断後は新しいjobを開始しない
+	if processed >= len(jobs) {
+		t.Errorf("中断後もjobが開始されています: %d件", processed)
+	}
+	// 出力は先頭から連続している
+	for i, job := range emitted {
+		if job != i {
+			t.Fatalf("出力順が入力順と一致しません: %v", emitted)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"edinet-api-test/internal/models"
+)
+
+// Revision 訂正履歴の1件
+type Revision struct {
+	DocID          string
+	SubmitDateTime string
+	Status         string // 提出 / 訂正 / 取下げ
+}
+
+// 訂正履歴の状態
+const (
+	StatusOriginal  = "提出"
+	StatusAmendment = "訂正"
+	StatusWithdrawn = "取下げ"
+)
+
+// String 履歴の表示形式（例: S100ABCD(2025-06-25 15:00 提出)）
+func (r Revision) String() string {
+	return fmt.Sprintf("%s(%s %s)", r.DocID, r.SubmitDateTime, r.Status)
+}
+
+// Filing 会社・期間ごとに突き合わせた結果
+type Filing struct {
+	Doc       models.DocInfo // 採用する書類（取り下げられていない最新の訂正、なければ原本）
+	Date      string         // 採用する書類が掲載された書類一覧の日付
+	History   []Revision     // 原本から提出順
+	Withdrawn bool           // すべての書類が取り下げられている
+}
+
+// HistoryString 訂正履歴をセミコロン区切りで返す
+func (f Filing) HistoryString() string {
+	parts := make([]string, len(f.History))
+	for i, r := range f.History {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ";")
+}
+
+// entry 書類一覧から集めた書類
+type entry struct {
+	doc  models.DocInfo
+	date string
+}
+
+// Reconciler 訂正報告書を原本に結び付け、取下げを反映して会社・期間ごとに1件にまとめる
+//
+// EDINETの訂正報告書・取下書はparentDocIDで原本を参照するため、原本の書類管理番号
+// （原本が期間外の場合は参照先の番号）を単位にまとめる。
+type Reconciler struct {
+	entries map[string]*entry
+	order   []string
+}
+
+// NewReconciler 新しい突き合わせ器を作成
+func NewReconciler() *Reconciler {
+	return &Reconciler{entries: make(map[string]*entry)}
+}
+
+// Add 書類一覧の日付と書類を追加（同じ書類管理番号は後から追加したもので置き換える）
+func (r *Reconciler) Add(date string, docs ...models.DocInfo) {
+	for _, doc := range docs {
+		if _, ok := r.entries[doc.DocID]; !ok {
+			r.order = append(r.order, doc.DocID)
+		}
+		r.entries[doc.DocID] = &entry{doc: doc, date: date}
+	}
+}
+
+// Resolve 会社・期間ごとの採用書類を原本の提出順に返す
+func (r *Reconciler) Resolve() []Filing {
+	groups := make(map[string][]*entry)
+	var keys []string
+	for _, id := range r.order {
+		e := r.entries[id]
+		key := r.rootID(e.doc)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	filings := make([]Filing, 0, len(keys))
+	for _, key := range keys {
+		filings = append(filings, resolveGroup(groups[key]))
+	}
+	return filings
+}
+
+// rootID 書類の原本の書類管理番号
+func (r *Reconciler) rootID(doc models.DocInfo) string {
+	id := doc.DocID
+	seen := map[string]bool{id: true}
+	for parent := doc.ParentDocID; parent != "" && !seen[parent]; {
+		seen[parent] = true
+		id = parent
+		e, ok := r.entries[parent]
+		if !ok {
+			break
+		}
+		parent = e.doc.ParentDocID
+	}
+	return id
+}
+
+// resolveGroup 同じ原本に属する書類から採用する書類と訂正履歴を決める
+func resolveGroup(entries []*entry) Filing {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].doc.SubmitDateTime < entries[j].doc.SubmitDateTime
+	})
+
+	// 取下書が参照する書類は取り下げられたものとして扱う
+	withdrawn := make(map[string]bool)
+	for _, e := range entries {
+		if e.doc.WithdrawalStatus == models.WithdrawalWithdrawn {
+			withdrawn[e.doc.DocID] = true
+		}
+		if e.doc.WithdrawalStatus == models.WithdrawalNotice && e.doc.ParentDocID != "" {
+			withdrawn[e.doc.ParentDocID] = true
+		}
+	}
+
+	var filing Filing
+	found := false
+	allWithdrawn := true
+	for _, e := range entries {
+		status := StatusOriginal
+		switch {
+		case withdrawn[e.doc.DocID] || e.doc.IsWithdrawn():
+			status = StatusWithdrawn
+		case e.doc.IsAmendment():
+			status = StatusAmendment
+		}
+		filing.History = append(filing.History, Revision{DocID: e.doc.DocID, SubmitDateTime: e.doc.SubmitDateTime, Status: status})
+		if status != StatusWithdrawn {
+			allWithdrawn = false
+		}
+
+		if status != StatusWithdrawn && e.doc.HasXBRL() {
+			filing.Doc, filing.Date, found = e.doc, e.date, true
+		}
+	}
+
+	if !found {
+		last := entries[len(entries)-1]
+		filing.Doc, filing.Date, filing.Withdrawn = last.doc, last.date, allWithdrawn
+	}
+	return filing
+}
@@ -0,0 +1,95 @@
+package reconcile
+
+import (
+	"testing"
+
+	"edinet-api-test/internal/models"
+)
+
+func TestReconciler_AmendmentReplacesOriginal(t *testing.T) {
+	r := NewReconciler()
+	r.Add("2025-06-25",
+		models.DocInfo{DocID: "S100AAAA", EdinetCode: "E00001", DocTypeCode: "120", SubmitDateTime: "2025-06-25 15:00", XbrlFlag: "1"},
+		models.DocInfo{DocID: "S100BBBB", EdinetCode: "E00002", DocTypeCode: "120", SubmitDateTime: "2025-06-25 15:30", XbrlFlag: "1"},
+	)
+	r.Add("2025-07-10",
+		models.DocInfo{DocID: "S100CCCC", EdinetCode: "E00001", DocTypeCode: "130", ParentDocID: "S100AAAA", SubmitDateTime: "2025-07-10 09:00", XbrlFlag: "1"},
+	)
+	r.Add("2025-08-01",
+		models.DocInfo{DocID: "S100DDDD", EdinetCode: "E00001", DocTypeCode: "130", ParentDocID: "S100AAAA", SubmitDateTime: "2025-08-01 09:00", XbrlFlag: "1"},
+	)
+
+	filings := r.Resolve()
+	if len(filings) != 2 {
+		t.Fatalf("会社・期間ごとに1件であるべきです: 期待=2, 実際=%d", len(filings))
+	}
+
+	first := filings[0]
+	if first.Doc.DocID != "S100DDDD" || first.Date != "2025-08-01" {
+		t.Errorf("最新の訂正報告書が採用されるべきです: %s (%s)", first.Doc.DocID, first.Date)
+	}
+	expected := "S100AAAA(2025-06-25 15:00 提出);S100CCCC(2025-07-10 09:00 訂正);S100DDDD(2025-08-01 09:00 訂正)"
+	if history := first.HistoryString(); history != expected {
+		t.Errorf("訂正履歴不一致: 期待=%s, 実際=%s", expected, history)
+	}
+
+	if filings[1].Doc.DocID != "S100BBBB" || len(filings[1].History) != 1 {
+		t.Errorf("訂正のない書類はそのまま採用されるべきです: %+v", filings[1])
+	}
+}
+
+func TestReconciler_AmendmentWithoutOriginal(t *testing.T) {
+	r := NewReconciler()
+	r.Add("2025-07-10",
+		models.DocInfo{DocID: "S100CCCC", DocTypeCode: "130", ParentDocID: "S100AAAA", SubmitDateTime: "2025-07-10 09:00", XbrlFlag: "1"},
+	)
+
+	filings := r.Resolve()
+	if len(filings) != 1 || filings[0].Doc.DocID != "S100CCCC" {
+		t.Fatalf("原本が期間外の訂正報告書も採用されるべきです: %+v", filings)
+	}
+	if filings[0].History[0].Status != StatusAmendment {
+		t.Errorf("状態不一致: 期待=%s, 実際=%s", StatusAmendment, filings[0].History[0].Status)
+	}
+}
+
+func TestReconciler_Withdrawn(t *testing.T) {
+	r := NewReconciler()
+	r.Add("2025-06-25",
+		models.DocInfo{DocID: "S100AAAA", DocTypeCode: "120", SubmitDateTime: "2025-06-25 15:00", XbrlFlag: "1"},
+		models.DocInfo{DocID: "S100BBBB", DocTypeCode: "120", SubmitDateTime: "2025-06-25 16:00", XbrlFlag: "1", WithdrawalStatus: models.WithdrawalWithdrawn},
+	)
+	r.Add("2025-06-30",
+		// 取下書は取り下げる書類をparentDocIDで参照する
+		models.DocInfo{DocID: "S100CCCC", DocTypeCode: "130", ParentDocID: "S100AAAA", SubmitDateTime: "2025-06-28 10:00", XbrlFlag: "1"},
+		models.DocInfo{DocID: "S100DDDD", DocTypeCode: "120", ParentDocID: "S100AAAA", SubmitDateTime: "2025-06-30 10:00", WithdrawalStatus: models.WithdrawalNotice},
+	)
+
+	filings := r.Resolve()
+	if len(filings) != 2 {
+		t.Fatalf("件数不一致: 期待=2, 実際=%d", len(filings))
+	}
+
+	// 原本が取り下げられても、取り下げられていない訂正報告書を採用する
+	if filings[0].Withdrawn || filings[0].Doc.DocID != "S100CCCC" {
+		t.Errorf("取り下げられていない訂正報告書が採用されるべきです: %+v", filings[0])
+	}
+	expected := "S100AAAA(2025-06-25 15:00 取下げ);S100CCCC(2025-06-28 10:00 訂正);S100DDDD(2025-06-30 10:00 取下げ)"
+	if history := filings[0].HistoryString(); history != expected {
+		t.Errorf("訂正履歴不一致: 期待=%s, 実際=%s", expected, history)
+	}
+
+	if !filings[1].Withdrawn {
+		t.Errorf("取り下げられた書類は取下げ済みとして扱われるべきです: %+v", filings[1])
+	}
+}
+
+func TestReconciler_DuplicateDocID(t *testing.T) {
+	r := NewReconciler()
+	r.Add("2025-06-25", models.DocInfo{DocID: "S100AAAA", DocTypeCode: "120", SubmitDateTime: "2025-06-25 15:00", XbrlFlag: "1"})
+	r.Add("2025-06-25", models.DocInfo{DocID: "S100AAAA", DocTypeCode: "120", SubmitDateTime: "2025-06-25 15:00", XbrlFlag: "1"})
+
+	if filings := r.Resolve(); len(filings) != 1 || len(filings[0].History) != 1 {
+		t.Errorf("同じ書類は1件として扱われるべきです: %+v", filings)
+	}
+}
@@ -0,0 +1,166 @@
+package syncstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"edinet-api-test/internal/models"
+	"edinet-api-test/internal/utils"
+)
+
+// jst EDINETの日付の基準のタイムゾーン
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Document 出力済みの書類
+type Document struct {
+	Date              string `json:"date"`                        // 書類一覧の日付
+	OpeDateTime       string `json:"opeDateTime,omitempty"`       // 出力時点の操作日時
+	DocInfoEditStatus string `json:"docInfoEditStatus,omitempty"` // 出力時点の書類情報修正区分
+	Withdrawn         bool   `json:"withdrawn,omitempty"`         // 出力後に取り下げられた
+}
+
+// MaxAttempts 失敗した書類を処理する最大回数（超えた場合は再試行しない）
+const MaxAttempts = 3
+
+// Failure 処理に失敗した書類（次回以降に同期済みの日付とは別に再試行する）
+type Failure struct {
+	Date     string         `json:"date"` // 書類一覧の日付
+	Doc      models.DocInfo `json:"doc"`
+	Attempts int            `json:"attempts"`
+	Error    string         `json:"error"`
+	GaveUp   bool           `json:"gaveUp,omitempty"` // 再試行しない（回数の上限に達した、または再試行しても変わらないエラー）
+}
+
+// State syncモードの状態（前回までに同期した日付と出力済み・失敗した書類）
+type State struct {
+	LastSyncedDate string              `json:"lastSyncedDate"` // 文書一覧を取得し終えた最後の日付
+	LastRunAt      time.Time           `json:"lastRunAt"`
+	Documents      map[string]Document `json:"documents"`        // 書類管理番号 → 出力済みの書類
+	Failed         map[string]Failure  `json:"failed,omitempty"` // 書類管理番号 → 処理に失敗した書類
+}
+
+// Load 状態ファイルを読み込む（ない場合は空の状態を返す）
+func Load(path string) (*State, error) {
+	state := &State{Documents: make(map[string]Document), Failed: make(map[string]Failure)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("状態ファイルの読み込みエラー: %v", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("状態ファイルが不正です (%s): %v", path, err)
+	}
+	if state.Documents == nil {
+		state.Documents = make(map[string]Document)
+	}
+	if state.Failed == nil {
+		state.Failed = make(map[string]Failure)
+	}
+	return state, nil
+}
+
+// Save 状態ファイルを保存
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("状態ファイルの作成エラー: %v", err)
+	}
+	if err := utils.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("状態ファイルの保存エラー: %v", err)
+	}
+	return nil
+}
+
+// Range 今回取得する日付の範囲
+//
+// 前回同期した日の翌日から今日（日本時間）までに加え、直近recentDays日を取得し直して
+// 出力済みの書類の訂正・取下げ・修正を拾う。初回はinitialStartから取得する。
+func (s *State) Range(initialStart, now time.Time, recentDays int) (time.Time, time.Time, error) {
+	y, m, d := now.In(jst).Date()
+	end := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	start := initialStart
+	if s.LastSyncedDate != "" {
+		last, err := time.Parse("2006-01-02", s.LastSyncedDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("状態ファイルの日付が不正です: %v", err)
+		}
+		start = last.AddDate(0, 0, 1)
+		if recent := end.AddDate(0, 0, -recentDays); recent.Before(start) {
+			start = recent
+		}
+	}
+	return start, end, nil
+}
+
+// NeedsProcessing 書類を出力する必要があるか
+// 未出力の書類か、出力後に操作日時・書類情報修正区分が変わった書類（財務局による修正等）。
+// 再試行をやめた書類は、その後に修正された場合のみ処理し直す。
+func (s *State) NeedsProcessing(doc models.DocInfo) bool {
+	if f, ok := s.Failed[doc.DocID]; ok && f.GaveUp {
+		return f.Doc.OpeDateTime != doc.OpeDateTime || f.Doc.DocInfoEditStatus != doc.DocInfoEditStatus
+	}
+	prev, ok := s.Documents[doc.DocID]
+	if !ok {
+		return true
+	}
+	return prev.OpeDateTime != doc.OpeDateTime || prev.DocInfoEditStatus != doc.DocInfoEditStatus
+}
+
+// MarkProcessed 書類を出力済みにする（失敗の記録は消す）
+func (s *State) MarkProcessed(doc models.DocInfo, date string) {
+	s.Documents[doc.DocID] = Document{
+		Date:              date,
+		OpeDateTime:       doc.OpeDateTime,
+		DocInfoEditStatus: doc.DocInfoEditStatus,
+	}
+	delete(s.Failed, doc.DocID)
+}
+
+// MarkFailed 書類の処理の失敗を記録し、再試行をやめた場合はtrueを返す
+// permanentは再試行しても結果が変わらないエラー（提供されていない取得種別・解析エラー等）
+func (s *State) MarkFailed(doc models.DocInfo, date string, err error, permanent bool) bool {
+	f := s.Failed[doc.DocID]
+	// 前回の失敗の後に修正された書類は数え直す
+	if f.Doc.OpeDateTime != doc.OpeDateTime || f.Doc.DocInfoEditStatus != doc.DocInfoEditStatus {
+		f.Attempts = 0
+	}
+	f.Date, f.Doc, f.Error = date, doc, err.Error()
+	f.Attempts++
+	f.GaveUp = permanent || f.Attempts >= MaxAttempts
+	s.Failed[doc.DocID] = f
+	return f.GaveUp
+}
+
+// Retries 再試行する書類（日付・書類管理番号の順）
+func (s *State) Retries() []Failure {
+	var retries []Failure
+	for _, f := range s.Failed {
+		if !f.GaveUp {
+			retries = append(retries, f)
+		}
+	}
+	sort.Slice(retries, func(i, j int) bool {
+		if retries[i].Date != retries[j].Date {
+			return retries[i].Date < retries[j].Date
+		}
+		return retries[i].Doc.DocID < retries[j].Doc.DocID
+	})
+	return retries
+}
+
+// MarkWithdrawn 出力済みの書類が取り下げられたことを記録し、初めて記録した場合はtrueを返す
+func (s *State) MarkWithdrawn(docID string) bool {
+	prev, ok := s.Documents[docID]
+	if !ok || prev.Withdrawn {
+		return false
+	}
+	prev.Withdrawn = true
+	s.Documents[docID] = prev
+	return true
+}
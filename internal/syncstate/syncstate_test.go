@@ -0,0 +1,162 @@
+package syncstate
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"edinet-api-test/internal/models"
+)
+
+func TestLoad_NotExist(t *testing.T) {
+	state, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if state.LastSyncedDate != "" || len(state.Documents) != 0 {
+		t.Errorf("空の状態であるべきです: %+v", state)
+	}
+}
+
+func TestState_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, _ := Load(path)
+	state.LastSyncedDate = "2025-07-15"
+	state.MarkProcessed(models.DocInfo{DocID: "S100ABCD", OpeDateTime: "2025-07-10 09:00"}, "2025-07-10")
+
+	if err := state.Save(path); err != nil {
+		t.Fatalf("保存エラー: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if loaded.LastSyncedDate != "2025-07-15" {
+		t.Errorf("LastSyncedDate不一致: %s", loaded.LastSyncedDate)
+	}
+	if doc := loaded.Documents["S100ABCD"]; doc.Date != "2025-07-10" || doc.OpeDateTime != "2025-07-10 09:00" {
+		t.Errorf("書類の状態が不正です: %+v", doc)
+	}
+}
+
+func TestState_Range(t *testing.T) {
+	initial := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 日本時間では7月16日
+	now := time.Date(2025, 7, 15, 16, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		lastSynced    string
+		recentDays    int
+		expectedStart string
+	}{
+		{"初回", "", 7, "2025-01-01"},
+		{"前回の翌日より直近の方が前", "2025-07-15", 7, "2025-07-09"},
+		{"前回から間が空いた", "2025-06-30", 7, "2025-07-01"},
+		{"直近を取得し直さない", "2025-07-15", 0, "2025-07-16"},
+	}
+
+	for _, tc := range testCases {
+		state := &State{LastSyncedDate: tc.lastSynced, Documents: map[string]Document{}}
+		start, end, err := state.Range(initial, now, tc.recentDays)
+		if err != nil {
+			t.Fatalf("%s: エラー: %v", tc.name, err)
+		}
+		if got := start.Format("2006-01-02"); got != tc.expectedStart {
+			t.Errorf("%s: 開始日不一致: 期待=%s, 実際=%s", tc.name, tc.expectedStart, got)
+		}
+		if got := end.Format("2006-01-02"); got != "2025-07-16" {
+			t.Errorf("%s: 終了日不一致: 期待=2025-07-16, 実際=%s", tc.name, got)
+		}
+	}
+}
+
+func TestState_NeedsProcessing(t *testing.T) {
+	state := &State{Documents: map[string]Document{}}
+	doc := models.DocInfo{DocID: "S100ABCD", OpeDateTime: "", DocInfoEditStatus: "0"}
+
+	if !state.NeedsProcessing(doc) {
+		t.Error("未出力の書類は出力するべきです")
+	}
+	state.MarkProcessed(doc, "2025-07-10")
+	if state.NeedsProcessing(doc) {
+		t.Error("出力済みの書類は出力しないべきです")
+	}
+
+	// 財務局による修正
+	edited := doc
+	edited.OpeDateTime = "2025-07-20 10:00"
+	edited.DocInfoEditStatus = "2"
+	if !state.NeedsProcessing(edited) {
+		t.Error("出力後に修正された書類は出力し直すべきです")
+	}
+}
+
+func TestState_MarkWithdrawn(t *testing.T) {
+	state := &State{Documents: map[string]Document{}}
+	if state.MarkWithdrawn("S100ABCD") {
+		t.Error("未出力の書類は記録しないべきです")
+	}
+
+	state.MarkProcessed(models.DocInfo{DocID: "S100ABCD"}, "2025-07-10")
+	if !state.MarkWithdrawn("S100ABCD") {
+		t.Error("出力済みの書類の取下げは記録するべきです")
+	}
+	if state.MarkWithdrawn("S100ABCD") {
+		t.Error("記録済みの取下げは再度記録しないべきです")
+	}
+}
+
+func TestState_MarkFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, _ := Load(path)
+	doc := models.DocInfo{DocID: "S100ABCD", OpeDateTime: ""}
+
+	for i := 1; i < MaxAttempts; i++ {
+		if state.MarkFailed(doc, "2025-07-10", errors.New("通信エラー"), false) {
+			t.Fatalf("%d回目で再試行をやめるべきではありません", i)
+		}
+	}
+	if retries := state.Retries(); len(retries) != 1 || retries[0].Doc.DocID != "S100ABCD" || retries[0].Date != "2025-07-10" {
+		t.Errorf("失敗した書類を再試行するべきです: %+v", retries)
+	}
+
+	// 状態ファイルに保存して次回に引き継ぐ
+	if err := state.Save(path); err != nil {
+		t.Fatalf("保存エラー: %v", err)
+	}
+	state, err := Load(path)
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if f := state.Failed["S100ABCD"]; f.Attempts != MaxAttempts-1 || f.Error != "通信エラー" {
+		t.Errorf("失敗の記録が不正です: %+v", f)
+	}
+
+	if !state.MarkFailed(doc, "2025-07-10", errors.New("通信エラー"), false) {
+		t.Error("上限の回数に達したら再試行をやめるべきです")
+	}
+	if len(state.Retries()) != 0 || state.NeedsProcessing(doc) {
+		t.Error("再試行をやめた書類は処理しないべきです")
+	}
+
+	// 再試行をやめた後に修正された書類は処理し直す
+	edited := doc
+	edited.OpeDateTime = "2025-07-20 10:00"
+	if !state.NeedsProcessing(edited) {
+		t.Error("修正された書類は処理し直すべきです")
+	}
+	if state.MarkFailed(edited, "2025-07-10", errors.New("通信エラー"), false) {
+		t.Error("修正された書類は回数を数え直すべきです")
+	}
+	state.MarkProcessed(edited, "2025-07-10")
+	if _, ok := state.Failed["S100ABCD"]; ok {
+		t.Error("出力した書類の失敗の記録は消すべきです")
+	}
+
+	// 再試行しても変わらないエラーは1回でやめる
+	if !state.MarkFailed(models.DocInfo{DocID: "S100EFGH"}, "2025-07-11", errors.New("XBRLパース失敗"), true) {
+		t.Error("再試行しても変わらないエラーは再試行しないべきです")
+	}
+}
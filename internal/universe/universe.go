@@ -0,0 +1,365 @@
+package universe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"edinet-api-test/internal/edinetcode"
+	"edinet-api-test/internal/models"
+)
+
+// Entry ウォッチリストの1件
+type Entry struct {
+	Key    string // 証券コード・EDINETコード・法人番号・提出者名
+	Market string // 市場区分（CSVに列がある場合のみ、例: プライム）
+}
+
+// Filter 業種・市場区分・決算月の条件（いずれも空の場合は絞り込まない）
+type Filter struct {
+	Industries          []string // 提出者業種（例: 電気機器）
+	Markets             []string // 上場区分（listed / unlisted）または市場区分（プライム等）
+	FiscalYearEndMonths []int    // 決算月（1〜12）
+}
+
+// IsEmpty 条件が指定されていないかどうか
+func (f Filter) IsEmpty() bool {
+	return len(f.Industries) == 0 && len(f.Markets) == 0 && len(f.FiscalYearEndMonths) == 0
+}
+
+// 上場区分の指定
+const (
+	MarketListed   = "listed"
+	MarketUnlisted = "unlisted"
+)
+
+// listCSVColumns ウォッチリストのCSVで証券コード等として読む列（先に見つかった列を使う）
+var listCSVColumns = []string{
+	"edinetcode", "ＥＤＩＮＥＴコード", "edinetコード", "seccode", "証券コード", "コード", "code",
+	"jcn", "法人番号", "提出者名", "銘柄名", "会社名", "name", "company",
+}
+
+// marketCSVColumns ウォッチリストのCSVで市場区分として読む列
+var marketCSVColumns = []string{"market", "市場・商品区分", "市場区分", "市場"}
+
+// LoadWatchlist ウォッチリストを読み込む
+//
+//   - .json: {"watchlists": {"名前": ["7203", "E01777", ...]}} の設定ファイル（PATH#名前で選択、1つだけの場合は省略可）
+//   - .csv: 証券コード・EDINETコード・提出者名等の列（列名がない場合は1列目）と、あれば市場区分の列
+//   - それ以外: 1行に1件（#以降はコメント）
+func LoadWatchlist(spec string) ([]Entry, error) {
+	path, name, _ := strings.Cut(spec, "#")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリストの読み込みエラー: %v", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		entries, err = parseJSONWatchlist(data, name)
+	case ".csv":
+		entries, err = parseCSVWatchlist(data)
+	default:
+		entries = parseTextWatchlist(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ウォッチリストが不正です (%s): %v", spec, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("ウォッチリストが空です (%s)", spec)
+	}
+	return entries, nil
+}
+
+// parseJSONWatchlist 設定ファイルから名前を付けたウォッチリストを読み込む
+func parseJSONWatchlist(data []byte, name string) ([]Entry, error) {
+	var file struct {
+		Watchlists map[string][]string `json:"watchlists"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(file.Watchlists))
+	for n := range file.Watchlists {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	if name == "" {
+		if len(names) != 1 {
+			return nil, fmt.Errorf("ウォッチリストの名前を「ファイル名#名前」で指定してください（%s）", strings.Join(names, ", "))
+		}
+		name = names[0]
+	}
+	keys, ok := file.Watchlists[name]
+	if !ok {
+		return nil, fmt.Errorf("ウォッチリスト「%s」がありません（%s）", name, strings.Join(names, ", "))
+	}
+	var entries []Entry
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			entries = append(entries, Entry{Key: key})
+		}
+	}
+	return entries, nil
+}
+
+// parseCSVWatchlist CSVのウォッチリストを読み込む
+func parseCSVWatchlist(data []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	keyColumn, marketColumn := -1, -1
+	header := make(map[string]int)
+	for i, name := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range listCSVColumns {
+		if i, ok := header[name]; ok {
+			keyColumn = i
+			break
+		}
+	}
+	for _, name := range marketCSVColumns {
+		if i, ok := header[name]; ok {
+			marketColumn = i
+			break
+		}
+	}
+	if keyColumn < 0 {
+		// 列名がない場合は1列目を証券コード等として読む
+		keyColumn = 0
+	} else {
+		records = records[1:]
+	}
+
+	var entries []Entry
+	for _, record := range records {
+		if keyColumn >= len(record) {
+			continue
+		}
+		e := Entry{Key: strings.TrimSpace(record[keyColumn])}
+		if marketColumn >= 0 && marketColumn < len(record) {
+			e.Market = strings.TrimSpace(record[marketColumn])
+		}
+		if e.Key != "" && !strings.HasPrefix(e.Key, "#") {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// parseTextWatchlist 1行に1件のウォッチリストを読み込む
+func parseTextWatchlist(data []byte) []Entry {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, Entry{Key: line})
+		}
+	}
+	return entries
+}
+
+// Universe 対象の提出者の集合（ウォッチリストと業種・市場区分・決算月の条件）
+//
+// 文書一覧の各文書をMatchesで判定するため、何社を対象にしても1日の文書一覧を1回取得するだけで済む。
+type Universe struct {
+	hasList     bool
+	edinetCodes map[string]bool   // ウォッチリストの提出者のEDINETコード
+	secCodes    map[string]bool   // 提出者の一覧で見つからなかった証券コード
+	markets     map[string]string // EDINETコードまたは証券コード → ウォッチリストの市場区分
+
+	filter   Filter
+	listed   map[bool]bool // 上場区分の条件
+	segments []string      // 市場区分の条件
+	months   map[int]bool
+	registry *edinetcode.Registry
+}
+
+// New ウォッチリスト（nilの場合は全提出者）と条件から対象の提出者の集合を作成
+//
+// ウォッチリストの提出者名・法人番号と、業種・上場区分・決算月の条件には提出者の一覧（registry）が必要。
+// 一覧で見つからない・1社に決まらない提出者は警告を出して除く（全て除いた場合はエラー）。
+// 一覧がない場合のみ、EDINETコード・証券コードをそのまま使う（4桁の証券コードは末尾に0を付ける）。
+func New(entries []Entry, filter Filter, registry *edinetcode.Registry) (*Universe, error) {
+	u := &Universe{
+		hasList:     entries != nil,
+		edinetCodes: make(map[string]bool),
+		secCodes:    make(map[string]bool),
+		markets:     make(map[string]string),
+		filter:      filter,
+		months:      make(map[int]bool),
+		registry:    registry,
+	}
+
+	for _, market := range filter.Markets {
+		switch strings.ToLower(strings.TrimSpace(market)) {
+		case MarketListed, "上場":
+			u.listed = setBool(u.listed, true)
+		case MarketUnlisted, "非上場":
+			u.listed = setBool(u.listed, false)
+		case "":
+		default:
+			u.segments = append(u.segments, strings.TrimSpace(market))
+		}
+	}
+	for _, m := range filter.FiscalYearEndMonths {
+		u.months[m] = true
+	}
+	if registry == nil && (len(filter.Industries) > 0 || len(u.listed) > 0 || len(u.months) > 0) {
+		return nil, fmt.Errorf("業種・上場区分・決算月で絞り込むには提出者の一覧が必要です（codelist update で取り込めます）")
+	}
+	if len(u.segments) > 0 && !hasMarkets(entries) {
+		return nil, fmt.Errorf("市場区分（%s）はEDINETコードリストに含まれないため、市場区分の列があるCSVのウォッチリストを指定してください", strings.Join(u.segments, ", "))
+	}
+
+	skipped := 0
+	for _, e := range entries {
+		code, isSecCode, err := resolveEntry(e.Key, registry)
+		if err != nil {
+			// 上場廃止・名称変更等で見つからない提出者があっても、他の提出者は取得する
+			slog.Warn("ウォッチリストの提出者をスキップします", "key", e.Key, "error", err)
+			skipped++
+			continue
+		}
+		if isSecCode {
+			u.secCodes[code] = true
+		} else {
+			u.edinetCodes[code] = true
+		}
+		if e.Market != "" {
+			u.markets[code] = e.Market
+		}
+	}
+	if len(entries) > 0 && skipped == len(entries) {
+		return nil, fmt.Errorf("ウォッチリストの提出者が1社も見つかりません（%d件）", len(entries))
+	}
+	return u, nil
+}
+
+// resolveEntry ウォッチリストの1件をEDINETコード（一覧がない場合は証券コード）に変換
+func resolveEntry(key string, registry *edinetcode.Registry) (code string, isSecCode bool, err error) {
+	c, err := registry.ResolveKey(key, true, 3)
+	if err != nil {
+		return "", false, err
+	}
+	if c.EdinetCode == "" {
+		return c.SecCode, true, nil
+	}
+	return c.EdinetCode, false, nil
+}
+
+// Size ウォッチリストの提出者数（ウォッチリストがない場合は0）
+func (u *Universe) Size() int {
+	return len(u.edinetCodes) + len(u.secCodes)
+}
+
+// Matches 文書が対象の提出者のものかどうか
+func (u *Universe) Matches(doc models.DocInfo) bool {
+	market, inList := u.markets[doc.EdinetCode], u.edinetCodes[doc.EdinetCode]
+	if !inList && doc.SecCode != "" && u.secCodes[doc.SecCode] {
+		market, inList = u.markets[doc.SecCode], true
+	}
+	if u.hasList && !inList {
+		return false
+	}
+
+	if len(u.segments) > 0 && !containsAny(market, u.segments) {
+		return false
+	}
+	if len(u.filter.Industries) == 0 && len(u.listed) == 0 && len(u.months) == 0 {
+		return true
+	}
+
+	// 業種・上場区分・決算月は提出者の一覧で判定する（一覧にない提出者は対象外）
+	c, ok := u.registry.ByEdinetCode(doc.EdinetCode)
+	if !ok {
+		return false
+	}
+	if len(u.filter.Industries) > 0 && !containsString(u.filter.Industries, c.Industry) {
+		return false
+	}
+	if len(u.listed) > 0 && !u.listed[c.Listed] {
+		return false
+	}
+	if len(u.months) > 0 && !u.months[FiscalYearEndMonth(c.FiscalYearEnd)] {
+		return false
+	}
+	return true
+}
+
+// fiscalYearEndPattern 決算日（例: 3月31日）の月
+var fiscalYearEndPattern = regexp.MustCompile(`^\s*(\d{1,2})\s*月`)
+
+// FiscalYearEndMonth 決算日（例: 3月31日）の月（読み取れない場合は0）
+func FiscalYearEndMonth(fiscalYearEnd string) int {
+	m := fiscalYearEndPattern.FindStringSubmatch(fiscalYearEnd)
+	if m == nil {
+		return 0
+	}
+	month, _ := strconv.Atoi(m[1])
+	return month
+}
+
+// setBool 集合に値を追加
+func setBool(set map[bool]bool, v bool) map[bool]bool {
+	if set == nil {
+		set = make(map[bool]bool)
+	}
+	set[v] = true
+	return set
+}
+
+// hasMarkets ウォッチリストに市場区分があるかどうか
+func hasMarkets(entries []Entry) bool {
+	for _, e := range entries {
+		if e.Market != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString 完全一致する値があるかどうか（前後の空白は無視）
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny 市場区分がいずれかの条件を含むかどうか（例: 「プライム（内国株式）」は「プライム」に一致）
+func containsAny(market string, segments []string) bool {
+	if market == "" {
+		return false
+	}
+	for _, s := range segments {
+		if strings.Contains(market, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package universe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"edinet-api-test/internal/edinetcode"
+	"edinet-api-test/internal/models"
+)
+
+// testRegistry テスト用の提出者の一覧
+func testRegistry(t *testing.T) *edinetcode.Registry {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "edinet_codes.json")
+	r := &edinetcode.Registry{Companies: []edinetcode.Company{
+		{EdinetCode: "E02144", SecCode: "72030", Name: "トヨタ自動車株式会社", Industry: "輸送用機器", FiscalYearEnd: "3月31日", Listed: true},
+		{EdinetCode: "E01777", SecCode: "67580", Name: "ソニーグループ株式会社", Industry: "電気機器", FiscalYearEnd: "3月31日", Listed: true},
+		{EdinetCode: "E02367", SecCode: "79740", Name: "任天堂株式会社", Industry: "その他製品", FiscalYearEnd: "3月31日", Listed: true},
+		{EdinetCode: "E00001", SecCode: "", Name: "テスト電機株式会社", Industry: "電気機器", FiscalYearEnd: "12月31日", Listed: false},
+	}}
+	// 索引を作成するため、保存して読み込み直す
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := edinetcode.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+// writeFile テスト用のファイルを作成
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testDocs 各提出者の文書
+var testDocs = []models.DocInfo{
+	{DocID: "S100AAAA", EdinetCode: "E02144", SecCode: "72030"},
+	{DocID: "S100BBBB", EdinetCode: "E01777", SecCode: "67580"},
+	{DocID: "S100CCCC", EdinetCode: "E02367", SecCode: "79740"},
+	{DocID: "S100DDDD", EdinetCode: "E00001"},
+	{DocID: "S100EEEE", EdinetCode: "E99999", SecCode: "99990"},
+}
+
+// matchedDocIDs 対象になった文書の書類管理番号
+func matchedDocIDs(u *Universe) []string {
+	var ids []string
+	for _, doc := range testDocs {
+		if u.Matches(doc) {
+			ids = append(ids, doc.DocID)
+		}
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoadWatchlist(t *testing.T) {
+	text := writeFile(t, "coverage.txt", "# カバレッジ\n7203\nE01777  # ソニー\n\n任天堂\n")
+	entries, err := LoadWatchlist(text)
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Key != "7203" || entries[1].Key != "E01777" || entries[2].Key != "任天堂" {
+		t.Errorf("テキストのウォッチリストが不正です: %+v", entries)
+	}
+
+	csvFile := writeFile(t, "coverage.csv", "\xef\xbb\xbf銘柄名,コード,市場・商品区分\nトヨタ自動車,7203,プライム（内国株式）\nソニーグループ,6758,プライム（内国株式）\n")
+	entries, err = LoadWatchlist(csvFile)
+	if err != nil {
+		t.Fatalf("読み込みエラー: %v", err)
+	}
+	// 提出者名よりコードの列を優先する
+	if len(entries) != 2 || entries[0].Key != "7203" || entries[0].Market != "プライム（内国株式）" {
+		t.Errorf("CSVのウォッチリストが不正です: %+v", entries)
+	}
+
+	noHeader := writeFile(t, "codes.csv", "7203,トヨタ\n6758,ソニー\n")
+	if entries, err := LoadWatchlist(noHeader); err != nil || len(entries) != 2 || entries[1].Key != "6758" {
+		t.Errorf("列名のないCSVは1列目を読むべきです: %+v, %v", entries, err)
+	}
+
+	config := writeFile(t, "watchlists.json", `{"watchlists": {"autos": ["7203", "7201"], "tech": ["6758"]}}`)
+	if entries, err := LoadWatchlist(config + "#autos"); err != nil || len(entries) != 2 || entries[1].Key != "7201" {
+		t.Errorf("名前を付けたウォッチリストが不正です: %+v, %v", entries, err)
+	}
+	if _, err := LoadWatchlist(config); err == nil {
+		t.Error("複数のウォッチリストがある場合、名前の指定がないとエラーになるべきです")
+	}
+	if _, err := LoadWatchlist(config + "#banks"); err == nil {
+		t.Error("存在しない名前の場合、エラーになるべきです")
+	}
+
+	empty := writeFile(t, "empty.txt", "# なし\n")
+	if _, err := LoadWatchlist(empty); err == nil {
+		t.Error("空のウォッチリストはエラーになるべきです")
+	}
+}
+
+func TestUniverse_Watchlist(t *testing.T) {
+	registry := testRegistry(t)
+
+	u, err := New([]Entry{{Key: "7203"}, {Key: "ソニーグループ"}}, Filter{}, registry)
+	if err != nil {
+		t.Fatalf("作成エラー: %v", err)
+	}
+	if u.Size() != 2 {
+		t.Errorf("提出者数不一致: 期待=2, 実際=%d", u.Size())
+	}
+	if got := matchedDocIDs(u); !equalStrings(got, []string{"S100AAAA", "S100BBBB"}) {
+		t.Errorf("対象の文書が不正です: %v", got)
+	}
+
+	// 一覧にない提出者は除き、他の提出者は対象にする
+	u, err = New([]Entry{{Key: "7203"}, {Key: "9999"}, {Key: "株式会社"}}, Filter{}, registry)
+	if err != nil {
+		t.Fatalf("一覧にない提出者があっても作成できるべきです: %v", err)
+	}
+	if got := matchedDocIDs(u); !equalStrings(got, []string{"S100AAAA"}) {
+		t.Errorf("一覧にない提出者は除くべきです: %v", got)
+	}
+	if _, err := New([]Entry{{Key: "9999"}}, Filter{}, registry); err == nil {
+		t.Error("全ての提出者が見つからない場合はエラーになるべきです")
+	}
+
+	// 一覧がない場合のみ、証券コードは末尾に0を付けて証券コードで判定する
+	u, err = New([]Entry{{Key: "9999"}, {Key: "E02144"}}, Filter{}, nil)
+	if err != nil {
+		t.Fatalf("作成エラー: %v", err)
+	}
+	if got := matchedDocIDs(u); !equalStrings(got, []string{"S100AAAA", "S100EEEE"}) {
+		t.Errorf("対象の文書が不正です: %v", got)
+	}
+	if _, err := New([]Entry{{Key: "ソニー"}}, Filter{}, nil); err == nil {
+		t.Error("一覧がない場合、提出者名だけのウォッチリストはエラーになるべきです")
+	}
+}
+
+func TestUniverse_Filters(t *testing.T) {
+	registry := testRegistry(t)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"業種", Filter{Industries: []string{"電気機器"}}, []string{"S100BBBB", "S100DDDD"}},
+		{"上場区分", Filter{Markets: []string{"listed"}}, []string{"S100AAAA", "S100BBBB", "S100CCCC"}},
+		{"決算月", Filter{FiscalYearEndMonths: []int{12}}, []string{"S100DDDD"}},
+		{"組み合わせ", Filter{Industries: []string{"電気機器"}, Markets: []string{"非上場"}}, []string{"S100DDDD"}},
+	}
+	for _, tt := range tests {
+		u, err := New(nil, tt.filter, registry)
+		if err != nil {
+			t.Fatalf("%s: 作成エラー: %v", tt.name, err)
+		}
+		if got := matchedDocIDs(u); !equalStrings(got, tt.want) {
+			t.Errorf("%s: 対象の文書が不正です: %v, 期待=%v", tt.name, got, tt.want)
+		}
+	}
+
+	// ウォッチリストと条件の両方に一致する提出者のみ
+	u, err := New([]Entry{{Key: "E02144"}, {Key: "E01777"}}, Filter{Industries: []string{"電気機器"}}, registry)
+	if err != nil {
+		t.Fatalf("作成エラー: %v", err)
+	}
+	if got := matchedDocIDs(u); !equalStrings(got, []string{"S100BBBB"}) {
+		t.Errorf("ウォッチリストと条件の両方で絞り込むべきです: %v", got)
+	}
+
+	if _, err := New(nil, Filter{Industries: []string{"電気機器"}}, nil); err == nil {
+		t.Error("提出者の一覧がない場合、業種の条件はエラーになるべきです")
+	}
+}
+
+func TestUniverse_MarketSegment(t *testing.T) {
+	registry := testRegistry(t)
+	entries := []Entry{
+		{Key: "7203", Market: "プライム（内国株式）"},
+		{Key: "7974", Market: "スタンダード（内国株式）"},
+	}
+
+	u, err := New(entries, Filter{Markets: []string{"プライム"}}, registry)
+	if err != nil {
+		t.Fatalf("作成エラー: %v", err)
+	}
+	if got := matchedDocIDs(u); !equalStrings(got, []string{"S100AAAA"}) {
+		t.Errorf("市場区分で絞り込むべきです: %v", got)
+	}
+
+	if _, err := New([]Entry{{Key: "7203"}}, Filter{Markets: []string{"プライム"}}, registry); err == nil {
+		t.Error("ウォッチリストに市場区分がない場合、市場区分の条件はエラーになるべきです")
+	}
+}
+
+func TestFiscalYearEndMonth(t *testing.T) {
+	tests := map[string]int{"3月31日": 3, "12月31日": 12, " 6月30日": 6, "": 0, "末日": 0}
+	for s, want := range tests {
+		if got := FiscalYearEndMonth(s); got != want {
+			t.Errorf("FiscalYearEndMonth(%q) = %d, 期待=%d", s, got, want)
+		}
+	}
+}
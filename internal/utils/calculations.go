@@ -206,6 +206,30 @@ func CalculateAdditionalMetrics(values map[string]string) map[string]string {
 	return metrics
 }
 
+// CalculateGrowthRates 当期と前期の値から成長率を計算
+func CalculateGrowthRates(current, prior map[string]string) map[string]string {
+	rates := make(map[string]string)
+
+	growthTags := map[string]string{
+		"jppfs_cor:NetSales":        "jppfs_cor:NetSalesGrowthRate",
+		"jppfs_cor:OperatingIncome": "jppfs_cor:OperatingIncomeGrowthRate",
+		"jppfs_cor:ProfitLoss":      "jppfs_cor:ProfitLossGrowthRate",
+		"jppfs_cor:TotalAssets":     "jppfs_cor:TotalAssetsGrowthRate",
+	}
+
+	for tag, rateTag := range growthTags {
+		cur := ParseFloat(current[tag])
+		prev := ParseFloat(prior[tag])
+		// 前期が0以下の場合は成長率が意味を持たないため計算しない
+		if current[tag] == "" || prev <= 0 {
+			continue
+		}
+		rates[rateTag] = FormatFloat(((cur - prev) / prev) * 100)
+	}
+
+	return rates
+}
+
 // GetCurrentTimestamp 現在のタイムスタンプを取得
 func GetCurrentTimestamp() string {
 	return time.Now().Format("2006-01-02 15:04:05")
@@ -1,11 +1,16 @@
 package writer
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"sort"
 	"strings"
 
+	"edinet-api-test/internal/concepts"
 	"edinet-api-test/internal/config"
 	"edinet-api-test/internal/models"
 	"edinet-api-test/internal/utils"
@@ -17,10 +22,17 @@ type CSVWriter struct {
 	file      *os.File
 	headers   []string
 	financialTags []string
+	hasHeader bool // 追記先のファイルにヘッダーが書き込み済み
 }
 
-// NewCSVWriter 新しいCSV出力器を作成
+// Stdout 標準出力に書き込む場合の出力ファイル名（ログは標準エラー出力に出す）
+const Stdout = config.OutputStdout
+
+// NewCSVWriter 新しいCSV出力器を作成（filenameがStdoutの場合は標準出力に書き込む）
 func NewCSVWriter(filename string) (*CSVWriter, error) {
+	if filename == Stdout {
+		return newStdoutWriter(), nil
+	}
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, fmt.Errorf("CSV作成エラー: %v", err)
@@ -36,8 +48,76 @@ func NewCSVWriter(filename string) (*CSVWriter, error) {
 	}, nil
 }
 
-// WriteHeader ヘッダーを書き込み
+// OpenCSVWriter 既存のCSVファイルに追記するCSV出力器を作成（ファイルがない場合は作成）
+// 既存のファイルのヘッダーが現在の列と一致しない場合はエラーにする
+func OpenCSVWriter(filename string) (*CSVWriter, error) {
+	if filename == Stdout {
+		return newStdoutWriter(), nil
+	}
+	hasHeader := false
+	if existing, err := os.Open(filename); err == nil {
+		header, err := csv.NewReader(existing).Read()
+		existing.Close()
+		switch {
+		case err == io.EOF:
+		case err != nil:
+			return nil, fmt.Errorf("既存のCSVの読み込みエラー: %v", err)
+		case strings.Join(header, ",") != strings.Join(config.JapaneseHeaders, ","):
+			return nil, fmt.Errorf("既存のCSV（%s）の列が現在の列と一致しません。別の出力ファイルを指定してください", filename)
+		default:
+			hasHeader = true
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("CSVオープンエラー: %v", err)
+	}
+
+	return &CSVWriter{
+		writer:        csv.NewWriter(file),
+		file:          file,
+		headers:       config.JapaneseHeaders,
+		financialTags: config.FinancialTags,
+		hasHeader:     hasHeader,
+	}, nil
+}
+
+// ResumeCSVWriter 出力ファイルをsizeバイトに切り詰めて追記するCSV出力器を作成
+// 中断した実行を再開する際に、ジャーナルに記録する前に書き込まれた行を取り除くために使う
+func ResumeCSVWriter(filename string, size int64) (*CSVWriter, error) {
+	info, err := os.Stat(filename)
+	switch {
+	case os.IsNotExist(err) && size == 0:
+	case err != nil:
+		return nil, fmt.Errorf("CSVオープンエラー: %v", err)
+	case info.Size() < size:
+		return nil, fmt.Errorf("出力ファイル（%s）が前回の記録（%d bytes）より短いため再開できません", filename, size)
+	default:
+		if err := os.Truncate(filename, size); err != nil {
+			return nil, fmt.Errorf("CSV切り詰めエラー: %v", err)
+		}
+		slog.Debug("出力ファイルを前回の記録まで切り詰めました", "file", filename, "from", info.Size(), "to", size)
+	}
+	return OpenCSVWriter(filename)
+}
+
+// newStdoutWriter 標準出力に書き込むCSV出力器を作成
+func newStdoutWriter() *CSVWriter {
+	return &CSVWriter{
+		writer:        csv.NewWriter(os.Stdout),
+		file:          os.Stdout,
+		headers:       config.JapaneseHeaders,
+		financialTags: config.FinancialTags,
+	}
+}
+
+// WriteHeader ヘッダーを書き込み（追記先にヘッダーがある場合は何もしない）
 func (c *CSVWriter) WriteHeader() error {
+	if c.hasHeader {
+		return nil
+	}
+	c.hasHeader = true
 	return c.writer.Write(c.headers)
 }
 
@@ -180,72 +260,150 @@ func (c *CSVWriter) WriteRow(row []string) error {
 	return c.writer.Write(row)
 }
 
+// WriteRows 1書類分の行をまとめて書き込み、ファイルに反映する
+// ctxが終了している場合は1行も書き込まない（中断時に書類の一部の行だけが残らないようにする）
+func (c *CSVWriter) WriteRows(ctx context.Context, rows [][]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := c.writer.Write(row); err != nil {
+			return fmt.Errorf("CSV書き込みエラー: %v", err)
+		}
+	}
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("CSV書き込みエラー: %v", err)
+	}
+	return nil
+}
+
+// Size 書き込んだ行をファイルに反映し、ファイルのサイズを返す
+func (c *CSVWriter) Size() (int64, error) {
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return 0, fmt.Errorf("CSV書き込みエラー: %v", err)
+	}
+	info, err := c.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("CSVサイズ取得エラー: %v", err)
+	}
+	return info.Size(), nil
+}
+
 // Flush バッファをフラッシュ
 func (c *CSVWriter) Flush() {
 	c.writer.Flush()
 }
 
-// Close ファイルを閉じる
+// Close バッファをフラッシュしてファイルを閉じる（標準出力は閉じない）
 func (c *CSVWriter) Close() error {
 	c.Flush()
+	if err := c.writer.Error(); err != nil {
+		if c.file != os.Stdout {
+			c.file.Close()
+		}
+		return fmt.Errorf("CSV書き込みエラー: %v", err)
+	}
+	if c.file == os.Stdout {
+		return nil
+	}
 	return c.file.Close()
 }
 
+// ResolveScopes 出力モードと連結財務諸表の有無から出力する連結・単体の区分を決める
+// 連結財務諸表を作成していない提出者は、どのモードでも単体のみを出力する
+func ResolveScopes(instance *models.XBRLInstance, mode string) []models.Scope {
+	if !instance.HasConsolidatedStatements() {
+		return []models.Scope{models.ScopeNonConsolidated}
+	}
+	switch mode {
+	case config.ConsolidationNonConsolidated:
+		return []models.Scope{models.ScopeNonConsolidated}
+	case config.ConsolidationBoth:
+		return []models.Scope{models.ScopeConsolidated, models.ScopeNonConsolidated}
+	default:
+		return []models.Scope{models.ScopeConsolidated}
+	}
+}
+
 // ExtractFinancialValues 財務タグから値を抽出（計算値も含む）
-func (c *CSVWriter) ExtractFinancialValues(values map[string]string) []string {
-	var result []string
-	
-	// 基本財務値を抽出
+// 会計基準に応じた要素名の候補と、報告書の種類に応じたコンテキストIDの優先順で
+// 当期・前期のファクトを選び、financialTagsと同じ順序で返す
+func (c *CSVWriter) ExtractFinancialValues(instance *models.XBRLInstance, kind models.ReportKind, scope models.Scope) []string {
+	policy := models.PolicyFor(kind)
+	standard := concepts.Detect(instance)
+
+	// 単体のみの提出者は単体の値をディメンションなしのコンテキストで報告する
+	contextScope := scope
+	if scope == models.ScopeNonConsolidated && !instance.HasConsolidatedStatements() {
+		contextScope = models.ScopeConsolidated
+	}
+
+	values := make(map[string]string)
+	prior := make(map[string]string)
+	usedContexts := make(map[string]bool)
 	for _, tag := range c.financialTags {
-		found := ""
-		tagOnly := tag
-		if strings.Contains(tag, ":") {
-			tagOnly = strings.Split(tag, ":")[1]
+		candidates := concepts.Candidates(tag, standard)
+		if sel, ok := instance.SelectCurrent(candidates, policy, contextScope); ok {
+			values[tag] = sel.Fact.Value
+			usedContexts[sel.ContextID] = true
 		}
-		
-		for k, v := range values {
-			if (strings.Contains(k, ":"+tagOnly+"|") || strings.HasSuffix(k, ":"+tagOnly)) &&
-				!strings.Contains(k, "TextBlock") {
-				found = v
-				break
-			}
+		if sel, ok := instance.SelectPrior(candidates, policy, contextScope); ok {
+			prior[tag] = sel.Fact.Value
 		}
-		result = append(result, found)
 	}
-	
+
 	// 計算値を追加
-	ratios := utils.CalculateFinancialRatios(values)
-	metrics := utils.CalculateAdditionalMetrics(values)
-	
-	// 計算された比率を追加（順序をヘッダーに合わせる）
-	calculatedValues := []string{
-		"", // 設立年月日
-		"", // 上場年月日
-		values["jppfs_cor:NumberOfEmployees"], // 従業員数
-		values["jppfs_cor:ResearchAndDevelopmentExpenses"], // 研究開発費
-		ratios["jppfs_cor:ResearchAndDevelopmentExpenseRatio"], // 研究開発費比率
-		"", // 会計基準
-		"", // 監査法人
-		"", // 連結・単体
-		"", // 決算月
-		"", // 年度開始月
-		ratios["jppfs_cor:OperatingIncomeRatio"], // 営業利益率
-		ratios["jppfs_cor:OrdinaryIncomeRatio"], // 経常利益率
-		ratios["jppfs_cor:ProfitLossRatio"], // 当期純利益率
-		ratios["jppfs_cor:GrossProfitRatio"], // 売上高総利益率
-		ratios["jppfs_cor:TotalAssetsTurnover"], // 総資産回転率
-		ratios["jppfs_cor:NetAssetsTurnover"], // 自己資本回転率
-		ratios["jppfs_cor:OperatingCashFlowRatio"], // 営業CF比率
-		ratios["jppfs_cor:InvestmentCashFlowRatio"], // 投資CF比率
-		metrics["jppfs_cor:WorkingCapital"], // 運転資本
-		metrics["jppfs_cor:DebtRatio"], // 負債比率
-		metrics["jppfs_cor:FixedRatio"], // 固定比率
-		metrics["jppfs_cor:FixedLongTermCoverageRatio"], // 固定長期適合率
-		utils.GetCurrentTimestamp(), // データ取得日時
-		"EDINET", // データソース
-		"", // XBRLタクソノミーバージョン
+	computed := make(map[string]string)
+	for k, v := range utils.CalculateFinancialRatios(values) {
+		computed[k] = v
+	}
+	for k, v := range utils.CalculateAdditionalMetrics(values) {
+		computed[k] = v
+	}
+	for k, v := range utils.CalculateGrowthRates(values, prior) {
+		computed[k] = v
+	}
+	dei := instance.DEI()
+	computed["jppfs_cor:AccountingStandards"] = string(standard)
+	computed["jppfs_cor:FiscalYearEnd"] = dei.FiscalYearEndMonth()
+	computed["jppfs_cor:FiscalYearStart"] = dei.FiscalYearStartMonth()
+	computed["jppfs_cor:ConsolidatedOrNonConsolidatedFinancialStatements"] = scope.Label()
+	computed["jppfs_cor:DataCollectionDate"] = utils.GetCurrentTimestamp()
+	computed["jppfs_cor:DataSource"] = "EDINET"
+	computed["jppfs_cor:TaxonomyVersion"] = instance.TaxonomyVersion()
+	computed["jppfs_cor:SelectedContexts"] = joinSorted(usedContexts)
+
+	result := make([]string, 0, len(c.financialTags))
+	for _, tag := range c.financialTags {
+		if v, ok := computed[tag]; ok {
+			result = append(result, v)
+			continue
+		}
+		result = append(result, values[tag])
 	}
-	
-	result = append(result, calculatedValues...)
 	return result
-} 
\ No newline at end of file
+}
+
+// SetValue ExtractFinancialValuesの結果のうち、指定タグの列に値を設定
+// 書類管理番号・訂正履歴など、インスタンスではなく書類一覧に由来する列に使う
+func (c *CSVWriter) SetValue(values []string, tag, value string) bool {
+	for i, t := range c.financialTags {
+		if t == tag && i < len(values) {
+			values[i] = value
+			return true
+		}
+	}
+	return false
+}
+
+// joinSorted 集合の要素をソートしてセミコロン区切りで連結
+func joinSorted(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
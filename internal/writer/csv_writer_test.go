@@ -1,11 +1,13 @@
 package writer
 
 import (
+	"context"
 	"encoding/csv"
 	"os"
 	"strings"
 	"testing"
 
+	"edinet-api-test/internal/config"
 	"edinet-api-test/internal/models"
 )
 
@@ -227,6 +229,31 @@ func TestCSVWriter_WriteFinancialData(t *testing.T) {
 	}
 }
 
+// newTestInstance テスト用のXBRLインスタンスを作成
+func newTestInstance() *models.XBRLInstance {
+	instance := models.NewXBRLInstance()
+	instance.AddContext(&models.Context{
+		ID:     "CurrentYearDuration",
+		Period: models.Period{Type: models.PeriodDuration, StartDate: "2024-04-01", EndDate: "2025-03-31"},
+	})
+	instance.AddContext(&models.Context{
+		ID:     "Prior1YearDuration",
+		Period: models.Period{Type: models.PeriodDuration, StartDate: "2023-04-01", EndDate: "2024-03-31"},
+	})
+	instance.AddContext(&models.Context{
+		ID:     "CurrentYearDuration_NonConsolidatedMember",
+		Period: models.Period{Type: models.PeriodDuration, StartDate: "2024-04-01", EndDate: "2025-03-31"},
+		Scenario: []models.DimensionMember{
+			{Dimension: "jppfs_cor:ConsolidatedOrNonConsolidatedAxis", Member: "jppfs_cor:NonConsolidatedMember"},
+		},
+	})
+	instance.AddContext(&models.Context{
+		ID:     "CurrentYearInstant",
+		Period: models.Period{Type: models.PeriodInstant, Instant: "2025-03-31"},
+	})
+	return instance
+}
+
 func TestCSVWriter_ExtractFinancialValues(t *testing.T) {
 	writer, err := NewCSVWriter("test_extract.csv")
 	if err != nil {
@@ -235,16 +262,19 @@ func TestCSVWriter_ExtractFinancialValues(t *testing.T) {
 	defer writer.Close()
 	defer os.Remove("test_extract.csv")
 
-	// テスト用のXBRL値を設定
-	values := map[string]string{
-		"jppfs_cor:NetSales|contextRef=CurrentYearDuration|unitRef=JPY": "1000000000",
-		"jppfs_cor:ProfitLoss|contextRef=CurrentYearDuration|unitRef=JPY": "100000000",
-		"jppfs_cor:TotalAssets|contextRef=CurrentYearDuration|unitRef=JPY": "2000000000",
-		"jppfs_cor:NetSalesTextBlock|contextRef=CurrentYearDuration": "売上高の説明",
-	}
+	// テスト用のXBRL値を設定（前期・単体の値が先に出現しても当期・連結が選ばれること）
+	instance := newTestInstance()
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "Prior1YearDuration", UnitRef: "JPY", Value: "900000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration_NonConsolidatedMember", UnitRef: "JPY", Value: "400000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1000000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:ProfitLoss", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "100000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:TotalAssets", ContextRef: "CurrentYearInstant", UnitRef: "JPY", Value: "2000000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSalesTextBlock", ContextRef: "CurrentYearDuration", Value: "売上高の説明"})
+	instance.AddFact(models.Fact{Name: "jpdei_cor:CurrentFiscalYearStartDateDEI", ContextRef: "CurrentYearDuration", Value: "2024-04-01"})
+	instance.AddFact(models.Fact{Name: "jpdei_cor:CurrentFiscalYearEndDateDEI", ContextRef: "CurrentYearDuration", Value: "2025-03-31"})
 
 	// 財務値を抽出
-	result := writer.ExtractFinancialValues(values)
+	result := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeConsolidated)
 
 	// 結果の長さを確認
 	expectedLength := len(writer.financialTags)
@@ -256,6 +286,40 @@ func TestCSVWriter_ExtractFinancialValues(t *testing.T) {
 	if len(result) > 0 && result[0] != "1000000000" {
 		t.Errorf("NetSalesの抽出値不一致: 期待=1000000000, 実際=%s", result[0])
 	}
+
+	// 計算値が選択済みの当期・前期の値から計算されているか確認
+	expected := map[string]string{
+		"jppfs_cor:ProfitLossRatio":    "10.00",
+		"jppfs_cor:NetSalesGrowthRate": "11.11",
+		"jppfs_cor:SelectedContexts":   "CurrentYearDuration;CurrentYearInstant",
+		"jppfs_cor:FiscalYearEnd":      "3月",
+		"jppfs_cor:FiscalYearStart":    "4月",
+	}
+	for i, tag := range writer.financialTags {
+		if want, ok := expected[tag]; ok && result[i] != want {
+			t.Errorf("%s不一致: 期待=%s, 実際=%s", tag, want, result[i])
+		}
+	}
+}
+
+func TestCSVWriter_ExtractFinancialValues_Deterministic(t *testing.T) {
+	writer, err := NewCSVWriter("test_deterministic.csv")
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+	defer os.Remove("test_deterministic.csv")
+
+	instance := newTestInstance()
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1000000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "Prior1YearDuration", UnitRef: "JPY", Value: "900000000"})
+
+	for i := 0; i < 20; i++ {
+		result := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeConsolidated)
+		if result[0] != "1000000000" {
+			t.Fatalf("試行%d: NetSalesの抽出値不一致: 期待=1000000000, 実際=%s", i, result[0])
+		}
+	}
 }
 
 func TestCSVWriter_ExtractFinancialValues_Empty(t *testing.T) {
@@ -266,11 +330,11 @@ func TestCSVWriter_ExtractFinancialValues_Empty(t *testing.T) {
 	defer writer.Close()
 	defer os.Remove("test_empty.csv")
 
-	// 空の値を設定
-	values := map[string]string{}
+	// 空のインスタンスを設定
+	instance := models.NewXBRLInstance()
 
 	// 財務値を抽出
-	result := writer.ExtractFinancialValues(values)
+	result := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeConsolidated)
 
 	// 結果の長さを確認
 	expectedLength := len(writer.financialTags)
@@ -278,10 +342,306 @@ func TestCSVWriter_ExtractFinancialValues_Empty(t *testing.T) {
 		t.Errorf("結果の長さ不一致: 期待=%d, 実際=%d", expectedLength, len(result))
 	}
 
-	// 全ての値が空文字列であることを確認
+	// メタデータ以外の全ての値が空文字列であることを確認
 	for i, value := range result {
+		switch writer.financialTags[i] {
+		case "jppfs_cor:DataCollectionDate", "jppfs_cor:DataSource", "jppfs_cor:AccountingStandards",
+			"jppfs_cor:ConsolidatedOrNonConsolidatedFinancialStatements":
+			continue
+		}
 		if value != "" {
 			t.Errorf("値[%d]が空でない: %s", i, value)
 		}
 	}
-} 
\ No newline at end of file
+}
+
+// columnValue 抽出結果から指定タグの値を取得
+func columnValue(writer *CSVWriter, result []string, tag string) string {
+	for i, t := range writer.financialTags {
+		if t == tag {
+			return result[i]
+		}
+	}
+	return ""
+}
+
+func TestCSVWriter_ExtractFinancialValues_NonConsolidated(t *testing.T) {
+	writer, err := NewCSVWriter("test_nonconsolidated.csv")
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+	defer os.Remove("test_nonconsolidated.csv")
+
+	instance := newTestInstance()
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1000000000"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration_NonConsolidatedMember", UnitRef: "JPY", Value: "400000000"})
+
+	scopes := ResolveScopes(instance, config.ConsolidationBoth)
+	if len(scopes) != 2 || scopes[0] != models.ScopeConsolidated || scopes[1] != models.ScopeNonConsolidated {
+		t.Fatalf("出力区分不一致: %v", scopes)
+	}
+
+	cons := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeConsolidated)
+	single := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeNonConsolidated)
+
+	if cons[0] != "1000000000" || single[0] != "400000000" {
+		t.Errorf("NetSales不一致: 連結=%s, 単体=%s", cons[0], single[0])
+	}
+	tag := "jppfs_cor:ConsolidatedOrNonConsolidatedFinancialStatements"
+	if columnValue(writer, cons, tag) != "連結" || columnValue(writer, single, tag) != "単体" {
+		t.Errorf("連結・単体列不一致: 連結=%s, 単体=%s", columnValue(writer, cons, tag), columnValue(writer, single, tag))
+	}
+}
+
+func TestCSVWriter_ExtractFinancialValues_NoSubsidiaries(t *testing.T) {
+	writer, err := NewCSVWriter("test_nosubsidiaries.csv")
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+	defer os.Remove("test_nosubsidiaries.csv")
+
+	// 連結財務諸表を作成しない提出者は単体の値をディメンションなしで報告する
+	instance := newTestInstance()
+	instance.AddContext(&models.Context{ID: "FilingDateInstant", Period: models.Period{Type: models.PeriodInstant, Instant: "2025-06-25"}})
+	instance.AddFact(models.Fact{Name: "jpdei_cor:WhetherConsolidatedFinancialStatementsArePreparedDEI", ContextRef: "FilingDateInstant", Value: "false"})
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "300000000"})
+
+	for _, mode := range []string{config.ConsolidationConsolidated, config.ConsolidationBoth} {
+		scopes := ResolveScopes(instance, mode)
+		if len(scopes) != 1 || scopes[0] != models.ScopeNonConsolidated {
+			t.Errorf("%s: 単体にフォールバックされるべきです: %v", mode, scopes)
+		}
+	}
+
+	result := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeNonConsolidated)
+	if result[0] != "300000000" {
+		t.Errorf("NetSales不一致: 期待=300000000, 実際=%s", result[0])
+	}
+	if v := columnValue(writer, result, "jppfs_cor:ConsolidatedOrNonConsolidatedFinancialStatements"); v != "単体" {
+		t.Errorf("連結・単体列不一致: 期待=単体, 実際=%s", v)
+	}
+}
+
+func TestCSVWriter_ExtractFinancialValues_IFRS(t *testing.T) {
+	writer, err := NewCSVWriter("test_ifrs.csv")
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+	defer os.Remove("test_ifrs.csv")
+
+	instance := newTestInstance()
+	instance.AddFact(models.Fact{Name: "jpigp_cor:RevenueIFRS", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "13000000000"})
+	instance.AddFact(models.Fact{Name: "jpigp_cor:OperatingProfitLossIFRS", ContextRef: "CurrentYearDuration", UnitRef: "JPY", Value: "1300000000"})
+	instance.AddFact(models.Fact{Name: "jpigp_cor:AssetsIFRS", ContextRef: "CurrentYearInstant", UnitRef: "JPY", Value: "35000000000"})
+	// 単体はJ-GAAPの要素で報告される
+	instance.AddFact(models.Fact{Name: "jppfs_cor:NetSales", ContextRef: "CurrentYearDuration_NonConsolidatedMember", UnitRef: "JPY", Value: "2000000000"})
+
+	result := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeConsolidated)
+	expected := map[string]string{
+		"jppfs_cor:NetSales":             "13000000000",
+		"jppfs_cor:OperatingIncome":      "1300000000",
+		"jppfs_cor:TotalAssets":          "35000000000",
+		"jppfs_cor:OperatingIncomeRatio": "10.00",
+		"jppfs_cor:AccountingStandards":  "IFRS",
+	}
+	for tag, want := range expected {
+		if got := columnValue(writer, result, tag); got != want {
+			t.Errorf("%s不一致: 期待=%s, 実際=%s", tag, want, got)
+		}
+	}
+
+	single := writer.ExtractFinancialValues(instance, models.ReportAnnual, models.ScopeNonConsolidated)
+	if single[0] != "2000000000" {
+		t.Errorf("単体NetSales不一致: 期待=2000000000, 実際=%s", single[0])
+	}
+}
+
+func TestCSVWriter_SetValue(t *testing.T) {
+	writer, err := NewCSVWriter("test_setvalue.csv")
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+	defer os.Remove("test_setvalue.csv")
+
+	result := writer.ExtractFinancialValues(newTestInstance(), models.ReportAnnual, models.ScopeConsolidated)
+	if !writer.SetValue(result, "jppfs_cor:DocID", "S100ABCD") {
+		t.Fatal("書類管理番号の列が見つかりません")
+	}
+	writer.SetValue(result, "jppfs_cor:RevisionHistory", "S100ABCD(2025-06-25 15:00 提出)")
+
+	if v := columnValue(writer, result, "jppfs_cor:DocID"); v != "S100ABCD" {
+		t.Errorf("書類管理番号不一致: 期待=S100ABCD, 実際=%s", v)
+	}
+	if v := columnValue(writer, result, "jppfs_cor:RevisionHistory"); v != "S100ABCD(2025-06-25 15:00 提出)" {
+		t.Errorf("訂正履歴不一致: 実際=%s", v)
+	}
+	if writer.SetValue(result, "jppfs_cor:Unknown", "x") {
+		t.Error("存在しないタグはfalseを返すべきです")
+	}
+}
+
+func TestCSVWriter_WriteRows(t *testing.T) {
+	tmpFile := "test_rows.csv"
+	defer os.Remove(tmpFile)
+
+	writer, err := NewCSVWriter(tmpFile)
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	defer writer.Close()
+
+	rows := [][]string{{"2025-06-25", "連結"}, {"2025-06-25", "単体"}}
+	if err := writer.WriteRows(context.Background(), rows); err != nil {
+		t.Fatalf("行書き込みエラー: %v", err)
+	}
+
+	// 中断後は書き込まない
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := writer.WriteRows(ctx, rows); err != context.Canceled {
+		t.Errorf("context.Canceledが返されるべきです: %v", err)
+	}
+
+	// WriteRowsはファイルに反映済みなので、閉じる前に読める
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ファイル読み込みエラー: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV解析エラー: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("レコード数不一致: 期待=2, 実際=%d", len(records))
+	}
+}
+
+func TestOpenCSVWriter_Append(t *testing.T) {
+	tmpFile := t.TempDir() + "/append.csv"
+	row := make([]string, len(config.JapaneseHeaders))
+	row[0] = "2025-07-10"
+
+	// 2回に分けて追記しても、ヘッダーは1行だけ
+	for i := 0; i < 2; i++ {
+		writer, err := OpenCSVWriter(tmpFile)
+		if err != nil {
+			t.Fatalf("CSVWriter作成エラー: %v", err)
+		}
+		if err := writer.WriteHeader(); err != nil {
+			t.Fatalf("ヘッダー書き込みエラー: %v", err)
+		}
+		if err := writer.WriteRows(context.Background(), [][]string{row}); err != nil {
+			t.Fatalf("行書き込みエラー: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("クローズエラー: %v", err)
+		}
+	}
+
+	content, _ := os.ReadFile(tmpFile)
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV解析エラー: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("レコード数不一致: 期待=3（ヘッダー+2行）, 実際=%d", len(records))
+	}
+	if records[0][0] != config.JapaneseHeaders[0] {
+		t.Errorf("1行目がヘッダーではありません: %v", records[0][:3])
+	}
+}
+
+func TestOpenCSVWriter_HeaderMismatch(t *testing.T) {
+	tmpFile := t.TempDir() + "/old.csv"
+	if err := os.WriteFile(tmpFile, []byte("日付,証券コード\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenCSVWriter(tmpFile); err == nil {
+		t.Error("列が一致しない場合、エラーが発生すべきです")
+	}
+}
+
+func TestResumeCSVWriter(t *testing.T) {
+	tmpFile := t.TempDir() + "/resume.csv"
+	row := make([]string, len(config.JapaneseHeaders))
+	row[0] = "2025-07-10"
+
+	writer, err := NewCSVWriter(tmpFile)
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	writer.WriteHeader()
+	if err := writer.WriteRows(context.Background(), [][]string{row}); err != nil {
+		t.Fatalf("行書き込みエラー: %v", err)
+	}
+	size, err := writer.Size()
+	if err != nil {
+		t.Fatalf("サイズ取得エラー: %v", err)
+	}
+	// 記録する前に中断した書類の行
+	writer.WriteRows(context.Background(), [][]string{row})
+	writer.Close()
+
+	// 記録したサイズまで切り詰めて追記する
+	writer, err = ResumeCSVWriter(tmpFile, size)
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	writer.WriteHeader()
+	row[0] = "2025-07-11"
+	writer.WriteRows(context.Background(), [][]string{row})
+	if err := writer.Close(); err != nil {
+		t.Fatalf("クローズエラー: %v", err)
+	}
+
+	content, _ := os.ReadFile(tmpFile)
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV解析エラー: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("レコード数不一致: 期待=3（ヘッダー+2行）, 実際=%d", len(records))
+	}
+	if records[1][0] != "2025-07-10" || records[2][0] != "2025-07-11" {
+		t.Errorf("行が一致しません: %s, %s", records[1][0], records[2][0])
+	}
+
+	// 記録より短いファイルは再開できない
+	if _, err := ResumeCSVWriter(tmpFile, int64(len(content))+1); err == nil {
+		t.Error("ファイルが記録より短い場合、エラーが発生すべきです")
+	}
+}
+
+func TestNewCSVWriter_Stdout(t *testing.T) {
+	// 標準出力を一時ファイルに差し替える
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	writer, err := NewCSVWriter(Stdout)
+	if err != nil {
+		t.Fatalf("CSVWriter作成エラー: %v", err)
+	}
+	writer.WriteHeader()
+	if err := writer.Close(); err != nil {
+		t.Fatalf("クローズエラー: %v", err)
+	}
+	// 標準出力は閉じない
+	if _, err := out.WriteString("x"); err != nil {
+		t.Errorf("標準出力が閉じられています: %v", err)
+	}
+
+	content, _ := os.ReadFile(out.Name())
+	if !strings.HasPrefix(string(content), strings.Join(config.JapaneseHeaders, ",")) {
+		t.Errorf("標準出力にヘッダーが書き込まれるべきです: %.40s", content)
+	}
+	out.Close()
+}
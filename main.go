@@ -1,157 +1,822 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
 	"edinet-api-test/internal/api"
+	"edinet-api-test/internal/archive"
 	"edinet-api-test/internal/config"
+	"edinet-api-test/internal/journal"
+	"edinet-api-test/internal/listcache"
+	"edinet-api-test/internal/logging"
 	"edinet-api-test/internal/parser"
+	"edinet-api-test/internal/pipeline"
+	"edinet-api-test/internal/reconcile"
+	"edinet-api-test/internal/syncstate"
+	"edinet-api-test/internal/universe"
+	"edinet-api-test/internal/utils"
 	"edinet-api-test/internal/writer"
 	"edinet-api-test/internal/models"
 )
 
 func main() {
+	// サブコマンド
+	syncMode := false
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "archive":
+			if err := runArchiveCommand(os.Args[2:], os.Stdout); err != nil {
+				fatal(err)
+			}
+			return
+		case "codelist":
+			if err := runCodelistCommand(context.Background(), os.Args[2:], os.Stdout); err != nil {
+				fatal(err)
+			}
+			return
+		case "sync":
+			// 以降のオプションは通常の実行と同じ
+			syncMode = true
+			os.Args = append(os.Args[:1:1], os.Args[2:]...)
+		}
+	}
+
 	// ヘルプメッセージを設定
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "EDINET API XBRL財務データ抽出ツール\n\n")
 		fmt.Fprintf(os.Stderr, "使用方法:\n")
-		fmt.Fprintf(os.Stderr, "  %s [オプション]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s [オプション]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s sync [オプション]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s archive list|verify|prune -dir DIR\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s codelist update|lookup [-registry FILE]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "オプション:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\n例:\n")
 		fmt.Fprintf(os.Stderr, "  %s -start 2025-01-01 -end 2025-01-31 -code 40260\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -start 2024-12-01 -end 2024-12-31 -code 6758 -output toshiba_data.csv\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -start 2025-06-01 -end 2025-06-30 -company ソニー\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -start 2025-06-01 -end 2025-06-30 -watchlist coverage.txt -output - -q | gzip > coverage.csv.gz\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\n注意: EDINET_API_KEY環境変数が設定されている必要があります。\n")
 	}
 
-	// .envファイルを読み込み
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .envファイルを読み込めませんでした: %v", err)
-	}
+	// .envファイルを読み込み（警告はログの設定後に出す）
+	envErr := godotenv.Load()
 
 	// 設定を読み込み
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+
+	// ログは標準エラー出力に出し、標準出力はデータ（-output -）のために空けておく
+	logger, err := logging.New(os.Stderr, logging.Level(cfg.Verbose, cfg.Quiet), cfg.LogFormat)
+	if err != nil {
+		fatal(err)
+	}
+	slog.SetDefault(logger)
+	if envErr != nil {
+		slog.Warn(".envファイルを読み込めませんでした", "error", envErr)
+	}
+
+	cfg.Sync = syncMode
+	if cfg.Sync && cfg.Resume {
+		fatal(errors.New("syncサブコマンドでは-resumeを指定できません（状態ファイルで前回の続きから取得します）"))
+	}
+	if err := resolveTarget(cfg, os.Stdin, os.Stderr, isTerminal(os.Stdin)); err != nil {
+		fatal(err)
 	}
 
 	// 設定情報を表示
-	fmt.Printf("設定情報:\n")
-	fmt.Printf("  開始日: %s\n", cfg.StartDate)
-	fmt.Printf("  終了日: %s\n", cfg.EndDate)
-	fmt.Printf("  対象証券コード: %s\n", cfg.TargetSecCode)
-	fmt.Printf("  出力ファイル: %s\n", cfg.OutputFile)
-	if cfg.QuarterOnly {
-		fmt.Printf("  対象文書: 四半期報告書のみ\n")
-	} else {
-		fmt.Printf("  対象文書: 有価証券報告書・四半期報告書\n")
+	slog.Info("設定", configAttrs(cfg)...)
+
+	// Ctrl-C（SIGINT）・SIGTERMで中断する（2回目は即座に終了）
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := run(ctx, cfg); err != nil {
+		fatal(err)
 	}
-	fmt.Printf("\n")
+}
 
+// fatal エラーをログに出して終了
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
+}
+
+// configAttrs 設定情報のログの属性
+func configAttrs(cfg *config.Config) []any {
+	documents := "有価証券報告書・四半期報告書・半期報告書"
+	switch {
+	case cfg.Interim:
+		documents = "中間期（第2四半期報告書・半期報告書）"
+	case cfg.QuarterOnly:
+		documents = "四半期報告書・半期報告書のみ"
+	}
+	attrs := []any{"start", cfg.StartDate, "end", cfg.EndDate}
+	if cfg.TargetSecCode != "" {
+		attrs = append(attrs, logging.KeySecCode, cfg.TargetSecCode)
+	}
+	if cfg.TargetEdinetCode != "" {
+		attrs = append(attrs, "edinetCode", cfg.TargetEdinetCode)
+	}
+	if cfg.Watchlist != "" {
+		attrs = append(attrs, "watchlist", cfg.Watchlist)
+	}
+	if len(cfg.Industries) > 0 {
+		attrs = append(attrs, "industries", cfg.Industries)
+	}
+	if len(cfg.Markets) > 0 {
+		attrs = append(attrs, "markets", cfg.Markets)
+	}
+	if len(cfg.FiscalYearEndMonths) > 0 {
+		attrs = append(attrs, "fiscalYearEnd", cfg.FiscalYearEndMonths)
+	}
+	attrs = append(attrs, "output", cfg.OutputFile, "consolidation", cfg.Consolidation, "source", cfg.Source,
+		"workers", cfg.Workers, "rate", cfg.RequestsPerSecond, "documents", documents)
+	if cfg.DailyLimit > 0 {
+		attrs = append(attrs, "dailyLimit", cfg.DailyLimit)
+	}
+	if cfg.BaseURL != "" {
+		attrs = append(attrs, "baseURL", cfg.BaseURL)
+	}
+	return attrs
+}
+
+// docAttrs 書類ごとのログの属性（書類管理番号・証券コード・書類一覧の日付）
+func docAttrs(filing reconcile.Filing) []any {
+	return []any{logging.KeyDocID, filing.Doc.DocID, logging.KeySecCode, filing.Doc.SecCode, logging.KeyDay, filing.Date}
+}
+
+// run 日付範囲の書類を取得してCSVに出力する
+//
+// ctxが終了した場合は新しい書類の取得をやめ、処理中の書類は出力せずに破棄する。
+// 出力済みの行はフラッシュし、一時ファイルは作業ディレクトリごと削除する。
+func run(ctx context.Context, cfg *config.Config) (err error) {
 	// 日付範囲を取得
 	start, end, err := cfg.GetDateRange()
 	if err != nil {
-		log.Fatalf("日付範囲の取得エラー: %v", err)
+		return fmt.Errorf("日付範囲の取得エラー: %v", err)
+	}
+
+	// syncモードでは前回の続きから今日までを取得し、出力済みの書類は出力しない
+	var state *syncstate.State
+	if cfg.Sync {
+		if state, err = syncstate.Load(cfg.StateFile); err != nil {
+			return err
+		}
+		if start, end, err = state.Range(start, time.Now(), cfg.RecentDays); err != nil {
+			return err
+		}
+		slog.Info("同期", "start", start.Format("2006-01-02"), "end", end.Format("2006-01-02"), "lastSynced", state.LastSyncedDate, "documents", len(state.Documents))
+	}
+
+	// 対象の提出者
+	target, err := loadTarget(cfg)
+	if err != nil {
+		return err
 	}
 
 	// 各コンポーネントを初期化
-	edinetAPI := api.NewEdinetAPI(cfg.APIKey)
+	apiOptions := []api.Option{
+		api.WithTimeout(cfg.Timeout),
+		api.WithRateLimiter(api.NewRateLimiter(cfg.RequestsPerSecond, api.DefaultBurst, cfg.DailyLimit)),
+	}
+	if cfg.BaseURL != "" {
+		apiOptions = append(apiOptions, api.WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.Proxy != nil {
+		apiOptions = append(apiOptions, api.WithProxy(cfg.Proxy))
+	}
+	edinetAPI := api.NewEdinetAPI(cfg.APIKey, apiOptions...)
 	xbrlParser := parser.NewXBRLParser()
-	csvWriter, err := writer.NewCSVWriter(cfg.OutputFile)
+
+	// ダウンロードしたZIPは作業ディレクトリに置き、終了時にまとめて削除する
+	workDir, err := os.MkdirTemp("", "edinet-api-test-")
 	if err != nil {
-		log.Fatalf("CSV出力器の初期化エラー: %v", err)
+		return fmt.Errorf("作業ディレクトリの作成エラー: %v", err)
 	}
-	defer csvWriter.Close()
+	defer os.RemoveAll(workDir)
+
+	// ダウンロード済みのZIPを再利用するアーカイブ
+	var store *archive.Archive
+	if cfg.ArchiveDir != "" {
+		if store, err = archive.Open(cfg.ArchiveDir); err != nil {
+			return err
+		}
+	}
+	if cfg.PDFDir != "" {
+		if err := os.MkdirAll(cfg.PDFDir, 0755); err != nil {
+			return fmt.Errorf("PDFディレクトリの作成エラー: %v", err)
+		}
+	}
+
+	// syncモード以外では日・書類ごとの処理結果をジャーナルに記録し、-resumeで再開できるようにする
+	// （標準出力に書き込む場合は記録しない）
+	var jrnl *journal.Journal
+	if !cfg.Sync && cfg.JournalFile != "" {
+		if jrnl, err = journal.Open(cfg.JournalFile, journalKey(cfg, start, end), cfg.Resume); err != nil {
+			return err
+		}
+		defer jrnl.Close()
+		if cfg.Resume {
+			days, done, failed := jrnl.Counts()
+			slog.Info("再開", "days", days, "done", done, "retry", failed, "journal", cfg.JournalFile)
+		}
+	}
+
+	newWriter := writer.NewCSVWriter
+	switch {
+	case cfg.Sync:
+		newWriter = writer.OpenCSVWriter
+	case cfg.Resume:
+		// ジャーナルに記録する前に書き込まれた行は取り除いてから追記する
+		newWriter = func(filename string) (*writer.CSVWriter, error) {
+			return writer.ResumeCSVWriter(filename, jrnl.OutputSize())
+		}
+	}
+	csvWriter, err := newWriter(cfg.OutputFile)
+	if err != nil {
+		return fmt.Errorf("CSV出力器の初期化エラー: %v", err)
+	}
+	defer func() {
+		if closeErr := csvWriter.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
 
 	// ヘッダーを書き込み
 	if err := csvWriter.WriteHeader(); err != nil {
-		log.Fatalf("ヘッダー書き込みエラー: %v", err)
+		return fmt.Errorf("ヘッダー書き込みエラー: %v", err)
+	}
+
+	// 文書一覧の取得元（キャッシュを使う場合は変更のない日のAPI呼び出しを省く）
+	var lister documentLister = edinetAPI
+	if cfg.ListCacheDir != "" {
+		cache, err := listcache.New(cfg.ListCacheDir, edinetAPI)
+		if err != nil {
+			return err
+		}
+		cache.RecentDays = cfg.RecentDays
+		cache.Force = cfg.RefreshLists
+		lister = cache
+	}
+
+	// 中間期の系列では、書類一覧の期間から第1・第3四半期と判断できる四半期報告書をダウンロードする前に除く
+	// （3か月の期間の書類は提出者の一覧の決算月で判断する）
+	var fiscalYearEndMonth func(edinetCode string) int
+	if cfg.Interim {
+		registry, err := loadRegistry(cfg.RegistryFile)
+		if err != nil {
+			return err
+		}
+		if registry != nil {
+			fiscalYearEndMonth = func(edinetCode string) int {
+				c, ok := registry.ByEdinetCode(edinetCode)
+				if !ok {
+					return 0
+				}
+				return universe.FiscalYearEndMonth(c.FiscalYearEnd)
+			}
+		}
 	}
 
 	// 処理件数をカウント
 	processedCount := 0
 
-	// 日付範囲でループ
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+	// 日付範囲の書類一覧を集め、訂正・取下げを突き合わせる
+	// syncedThroughは文書一覧を漏れなく取得できた最後の日（syncモードの次回の開始日の基準）
+	reconciler := reconcile.NewReconciler()
+	syncedThrough := start.AddDate(0, 0, -1)
+	if state != nil {
+		// 前回までに失敗した書類は日付の範囲に関わらず再試行する（範囲内の日は取得した一覧の情報で置き換える）
+		retries := state.Retries()
+		for _, f := range retries {
+			reconciler.Add(f.Date, f.Doc)
+		}
+		if len(retries) > 0 {
+			slog.Info("失敗した書類を再試行します", "count", len(retries))
+		}
+	}
+	listFailed := false
+	for d := start; !d.After(end) && ctx.Err() == nil; d = d.AddDate(0, 0, 1) {
 		dateStr := d.Format("2006-01-02")
-		fmt.Printf("処理中: %s\n", dateStr)
+
+		// 再開時は取得済みの日の書類をジャーナルから読み込む
+		if jrnl != nil {
+			if day, ok := jrnl.Day(dateStr); ok {
+				slog.Info("ジャーナルから書類一覧を読み込み", logging.KeyDay, dateStr, "matched", len(day.Docs))
+				reconciler.Add(dateStr, day.Docs...)
+				continue
+			}
+		}
 		
 		// 文書一覧を取得
-		docList, err := edinetAPI.GetDocuments(d)
+		docList, err := lister.GetDocuments(ctx, d)
 		if err != nil {
-			log.Printf("文書一覧取得エラー (%s): %v", dateStr, err)
+			if ctx.Err() != nil {
+				break
+			}
+			if jrnl != nil {
+				if err := jrnl.RecordDay(dateStr, nil, err); err != nil {
+					return err
+				}
+			}
+			// APIキーが無効な場合は以降の日付も失敗するため中断
+			if errors.Is(err, api.ErrUnauthorized) {
+				return fmt.Errorf("文書一覧取得エラー (%s): %v", dateStr, err)
+			}
+			slog.Error("文書一覧取得エラー", logging.KeyDay, dateStr, "error", err)
+			listFailed = true
 			continue
 		}
+		if !listFailed {
+			syncedThrough = d
+		}
 
 		// 文書をフィルタリング
-		filteredDocs := api.FilterDocuments(docList.Results, cfg.TargetSecCode, cfg.QuarterOnly)
+		filteredDocs := api.FilterDocuments(docList.Results, target, cfg.QuarterOnly || cfg.Interim)
+		if cfg.Interim {
+			interimDocs := api.FilterInterim(filteredDocs, fiscalYearEndMonth)
+			if excluded := len(filteredDocs) - len(interimDocs); excluded > 0 {
+				slog.Debug("第1・第3四半期報告書を除外しました", logging.KeyDay, dateStr, "count", excluded)
+			}
+			filteredDocs = interimDocs
+		}
+		slog.Info("文書一覧", logging.KeyDay, dateStr, "documents", len(docList.Results), "matched", len(filteredDocs))
+		reconciler.Add(dateStr, filteredDocs...)
+		if jrnl != nil {
+			if err := jrnl.RecordDay(dateStr, filteredDocs, nil); err != nil {
+				return err
+			}
+		}
+	}
 
-		// 各文書を処理
-		for _, doc := range filteredDocs {
-			if err := processDocument(doc, dateStr, edinetAPI, xbrlParser, csvWriter); err != nil {
-				log.Printf("文書処理エラー (%s): %v", doc.DocID, err)
-				continue
+	// 会社・期間ごとに採用する書類を並行に処理し、日付・書類管理番号の順に出力する
+	var filings []reconcile.Filing
+	skipped := 0
+	for _, filing := range reconciler.Resolve() {
+		if state != nil {
+			// 出力済みの書類が後から取り下げられた場合は知らせる（追記した行は削除しない）
+			for _, rev := range filing.History {
+				if rev.Status == reconcile.StatusWithdrawn && state.MarkWithdrawn(rev.DocID) {
+					slog.Warn("出力済みの書類が取り下げられました。出力ファイルの行を確認してください", logging.KeyDocID, rev.DocID, "output", cfg.OutputFile)
+				}
+			}
+		}
+		if filing.Withdrawn {
+			slog.Info("スキップ: 取り下げられた書類です", append(docAttrs(filing), "history", filing.HistoryString())...)
+			continue
+		}
+		if (state != nil && !state.NeedsProcessing(filing.Doc)) || (jrnl != nil && jrnl.Completed(filing.Doc.DocID)) {
+			skipped++
+			continue
+		}
+		filings = append(filings, filing)
+	}
+	if skipped > 0 {
+		slog.Info("処理済みの書類をスキップしました", "count", skipped)
+	}
+	sort.SliceStable(filings, func(i, j int) bool {
+		if filings[i].Date != filings[j].Date {
+			return filings[i].Date < filings[j].Date
+		}
+		return filings[i].Doc.DocID < filings[j].Doc.DocID
+	})
+
+	processor := &documentProcessor{
+		cfg:        cfg,
+		workDir:    workDir,
+		store:      store,
+		edinetAPI:  edinetAPI,
+		xbrlParser: xbrlParser,
+		csvWriter:  csvWriter,
+	}
+	process := func(ctx context.Context, filing reconcile.Filing) documentResult {
+		// API・解析のログにも書類の属性を付ける
+		ctx = logging.With(ctx, docAttrs(filing)...)
+		rows, err := processor.processDocument(ctx, filing)
+		return documentResult{rows: rows, err: err}
+	}
+	// record 書類の処理結果をジャーナルに記録（出力した場合は行をファイルに反映した後のサイズも記録する）
+	record := func(filing reconcile.Filing, status string, docErr error) {
+		if jrnl == nil {
+			return
+		}
+		var offset int64
+		if status == journal.StatusDone {
+			size, err := csvWriter.Size()
+			if err != nil {
+				slog.Error("ジャーナル記録エラー", append(docAttrs(filing), "error", err)...)
+				return
+			}
+			offset = size
+		}
+		if err := jrnl.RecordDoc(filing.Date, filing.Doc.DocID, status, offset, docErr); err != nil {
+			slog.Error("ジャーナル記録エラー", append(docAttrs(filing), "error", err)...)
+		}
+	}
+	// markSynced 書類を出力済みとして状態ファイルに保存
+	// 行をファイルに反映した後に保存し、途中で強制終了されても次回に同じ書類を追記しないようにする
+	// （保存に失敗した場合は以降の書類を出力せずに終了する）
+	pipelineCtx, stopPipeline := context.WithCancel(ctx)
+	defer stopPipeline()
+	var stateErr error
+	markSynced := func(filing reconcile.Filing) {
+		if state == nil {
+			return
+		}
+		if _, err := csvWriter.Size(); err != nil {
+			stateErr = err
+		} else {
+			state.MarkProcessed(filing.Doc, filing.Date)
+			stateErr = state.Save(cfg.StateFile)
+		}
+		if stateErr != nil {
+			stopPipeline()
+		}
+	}
+	pipeline.Ordered(pipelineCtx, cfg.Workers, filings, process, func(filing reconcile.Filing, result documentResult) {
+		if stateErr != nil {
+			return
+		}
+		logger := slog.With(docAttrs(filing)...)
+		err := result.err
+		if err == nil {
+			err = csvWriter.WriteRows(ctx, result.rows)
+		}
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				logger.Info("破棄: 処理中に中断されました")
+				return
+			case errors.Is(err, errNotInterim):
+				logger.Info("スキップ: 中間期の書類ではありません")
+				record(filing, journal.StatusSkipped, nil)
+			default:
+				logger.Error("文書処理エラー", "error", err)
+				record(filing, journal.StatusFailed, err)
+				if state != nil && state.MarkFailed(filing.Doc, filing.Date, err, isPermanent(err)) {
+					logger.Warn("この書類は再試行しません", "attempts", state.Failed[filing.Doc.DocID].Attempts)
+				}
+				return
 			}
+		} else {
 			processedCount++
+			logger.Debug("出力", "filer", filing.Doc.FilerName, "rows", len(result.rows))
+			record(filing, journal.StatusDone, nil)
+		}
+		markSynced(filing)
+	})
+
+	if state != nil {
+		if stateErr != nil {
+			return stateErr
+		}
+		// 最後の行までファイルに反映してから同期済みの日付を保存する
+		if _, err := csvWriter.Size(); err != nil {
+			return err
+		}
+		if err := saveSyncState(state, cfg.StateFile, syncedThrough, ctx.Err() != nil); err != nil {
+			return err
 		}
 	}
 
-	fmt.Printf("\n処理完了: %d件の文書を処理し、%s に主要財務項目を出力しました。\n", processedCount, cfg.OutputFile)
+	if ctx.Err() != nil {
+		slog.Warn("中断しました", "documents", processedCount, "output", cfg.OutputFile)
+		if jrnl != nil {
+			slog.Info("同じオプションに-resumeを付けて実行すると、続きから処理します")
+		}
+		return nil
+	}
+	slog.Info("処理完了", "documents", processedCount, "output", cfg.OutputFile)
+	return nil
 }
 
-// processDocument 個別文書を処理
-func processDocument(doc models.DocInfo, dateStr string, edinetAPI *api.EdinetAPI, xbrlParser *parser.XBRLParser, csvWriter *writer.CSVWriter) error {
-	// XBRL ZIPをダウンロード
-	zipData, err := edinetAPI.DownloadXBRLZip(doc.DocID)
-	if err != nil {
-		return fmt.Errorf("ZIPダウンロード失敗: %v", err)
-	}
+// journalKey ジャーナルに記録する実行の設定（出力する書類・行が変わる設定が異なる場合は再開しない）
+func journalKey(cfg *config.Config, start, end time.Time) string {
+	return fmt.Sprintf("start=%s end=%s edinet=%s code=%s watchlist=%s industry=%s market=%s fye=%v quarter=%t interim=%t consolidation=%s source=%s output=%s pdf=%s",
+		start.Format("2006-01-02"), end.Format("2006-01-02"), cfg.TargetEdinetCode, cfg.TargetSecCode,
+		cfg.Watchlist, strings.Join(cfg.Industries, ","), strings.Join(cfg.Markets, ","), cfg.FiscalYearEndMonths,
+		cfg.QuarterOnly, cfg.Interim, cfg.Consolidation, cfg.Source, cfg.OutputFile, cfg.PDFDir)
+}
 
-	// 一時ZIPファイルを作成
-	zipFile := doc.DocID + ".zip"
-	if err := ioutil.WriteFile(zipFile, zipData, 0644); err != nil {
-		return fmt.Errorf("ZIPファイル保存失敗: %v", err)
+// saveSyncState syncモードの状態を保存
+// 失敗した書類は状態ファイルに記録して別に再試行するため、同期済みの日付は進める。
+// 中断した場合は処理できなかった書類の日から取得し直せるよう、同期済みの日付を進めない。
+func saveSyncState(state *syncstate.State, path string, syncedThrough time.Time, interrupted bool) error {
+	if !interrupted {
+		if synced := syncedThrough.Format("2006-01-02"); synced > state.LastSyncedDate {
+			state.LastSyncedDate = synced
+		}
 	}
-	defer os.Remove(zipFile)
+	state.LastRunAt = time.Now()
+	return state.Save(path)
+}
+
+// documentLister 日ごとの文書一覧の取得元（api.EdinetAPIまたはlistcache.Cache）
+type documentLister interface {
+	GetDocuments(ctx context.Context, date time.Time) (*models.DocumentListResponse, error)
+}
 
-	// XBRLファイルを抽出
-	xbrlPath, err := xbrlParser.ExtractPublicDocXBRL(zipFile)
+// documentResult 1書類分の処理結果
+type documentResult struct {
+	rows [][]string
+	err  error
+}
+
+// errNotInterim 中間期の系列の対象外（第1・第3四半期報告書）
+var errNotInterim = errors.New("中間期の書類ではありません")
+
+// permanentError 再試行しても結果が変わらない書類のエラー（提供されていない取得種別・解析エラー）
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (e permanentError) Unwrap() error { return e.err }
+
+// isPermanent 再試行しても結果が変わらないエラーかどうか（存在しない書類・不正なリクエストを含む）
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p) || errors.Is(err, api.ErrNotFound) || errors.Is(err, api.ErrBadRequest)
+}
+
+// documentProcessor 書類ごとの処理に使う設定と部品
+type documentProcessor struct {
+	cfg        *config.Config
+	workDir    string
+	store      *archive.Archive // nilの場合はアーカイブを使わない
+	edinetAPI  *api.EdinetAPI
+	xbrlParser *parser.XBRLParser
+	csvWriter  *writer.CSVWriter
+}
+
+// processDocument 会社・期間ごとに採用した書類をダウンロード・解析し、出力する行を作成
+// 複数のゴルーチンから同時に呼ばれるため、CSVへの書き込みは呼び出し側で行う
+func (p *documentProcessor) processDocument(ctx context.Context, filing reconcile.Filing) ([][]string, error) {
+	doc, dateStr := filing.Doc, filing.Date
+	cfg, xbrlParser, csvWriter := p.cfg, p.xbrlParser, p.csvWriter
+
+	// XBRL ZIP（-source csvの場合はCSVのZIP）を取得（アーカイブにあればダウンロードしない）
+	docType := api.DocumentXBRL
+	if cfg.Source == config.SourceCSV {
+		docType = api.DocumentCSV
+	}
+	if !docType.Available(doc) {
+		return nil, permanentError{fmt.Errorf("%sが提供されていない書類です", docType)}
+	}
+	zipFile, temporary, err := p.fetchDocument(ctx, doc.DocID, docType)
 	if err != nil {
-		return fmt.Errorf("XBRL抽出失敗: %v", err)
+		return nil, err
+	}
+	if temporary {
+		defer os.Remove(zipFile)
 	}
-	defer os.Remove(xbrlPath)
 
-	// XBRLファイルを解析
-	values, err := xbrlParser.ParseAllXBRL(xbrlPath)
+	// XBRL（またはiXBRL・CSV）を解析
+	instance, err := parseInstance(ctx, xbrlParser, zipFile, cfg.Source)
 	if err != nil {
-		return fmt.Errorf("XBRLパース失敗: %v", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, permanentError{fmt.Errorf("XBRLパース失敗: %v", err)}
+	}
+
+	// .xbrlとiXBRLの数値を突き合わせる
+	if cfg.CrossCheck {
+		crossCheck(ctx, xbrlParser, zipFile)
+	}
+
+	// XBRLとCSVの数値を突き合わせる
+	if cfg.CrossCheckCSV {
+		p.crossCheckCSV(ctx, doc, docType, zipFile)
+	}
+
+	// DEIから会計期間を取得（DEIがない場合は書類一覧の期間・提出日から推定）
+	dei := instance.DEI()
+	fiscalPeriod := dei.FiscalPeriodLabel()
+	if cfg.Interim {
+		// 中間期の系列では書類一覧の期間で判断できなかった第1・第3四半期をDEIで除き、第2四半期も「中間期」と表示する
+		if !dei.IsInterimPeriod() {
+			return nil, errNotInterim
+		}
+		fiscalPeriod = dei.InterimPeriodLabel()
+	}
+	if fiscalPeriod == "" {
+		fiscalPeriod = xbrlParser.GetFiscalPeriodFromDoc(doc, dateStr)
 	}
 
-	// 提出日と文書タイプから正しい会計期間を計算
-	fiscalPeriod := xbrlParser.GetCorrectFiscalPeriod(dateStr, doc.DocTypeCode)
+	// 書類一覧に証券コードがない場合はDEIの値を使う
+	secCode := doc.SecCode
+	if secCode == "" {
+		secCode = dei.SecurityCode
+	}
 
 	// 文書タイプ名を取得
 	docTypeName := xbrlParser.GetDocTypeName(doc.DocTypeCode)
 
-	// 財務値を抽出（計算値も含む）
-	financialValues := csvWriter.ExtractFinancialValues(values)
+	// PDFを保存し、CSVの行から元の書類を開けるようにする（失敗しても財務データは出力する）
+	pdfPath := ""
+	if cfg.PDFDir != "" && doc.HasPDF() {
+		if pdfPath, err = p.savePDF(ctx, doc.DocID); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			logging.FromContext(ctx).Warn("PDF保存エラー", "error", err)
+		}
+	}
+
+	// 連結・単体の区分ごとに行を作成
+	var rows [][]string
+	for _, scope := range writer.ResolveScopes(instance, cfg.Consolidation) {
+		// 財務値を抽出（計算値も含む）
+		financialValues := csvWriter.ExtractFinancialValues(instance, xbrlParser.GetReportKind(doc.DocTypeCode), scope)
+		csvWriter.SetValue(financialValues, "jppfs_cor:DocID", doc.DocID)
+		csvWriter.SetValue(financialValues, "jppfs_cor:RevisionHistory", filing.HistoryString())
+		csvWriter.SetValue(financialValues, "jppfs_cor:PDFPath", pdfPath)
 
-	// 行データを作成
-	row := []string{
-		dateStr,           // 日付
-		doc.SecCode,       // 証券コード
-		doc.FilerName,     // 会社名
-		docTypeName,       // 文書タイプ
-		fiscalPeriod,      // 会計期間（修正済み）
+		// 行データを作成
+		row := []string{
+			dateStr,           // 日付
+			secCode,           // 証券コード
+			doc.FilerName,     // 会社名
+			docTypeName,       // 文書タイプ
+			fiscalPeriod,      // 会計期間
+		}
+		row = append(row, financialValues...)
+		rows = append(rows, row)
 	}
-	row = append(row, financialValues...)
 
-	// CSVに書き込み
-	return csvWriter.WriteRow(row)
-}
\ No newline at end of file
+	return rows, nil
+}
+
+// fetchDocument 取得種別を指定して書類のファイルのパスを返す
+// アーカイブを使う場合はアーカイブ内のファイル、使わない場合は作業ディレクトリの一時ファイル（temporary=true）
+func (p *documentProcessor) fetchDocument(ctx context.Context, docID string, docType api.DocumentType) (path string, temporary bool, err error) {
+	if p.store != nil {
+		entry, ok, err := p.store.Get(docID, int(docType))
+		if err != nil {
+			return "", false, err
+		}
+		if ok {
+			logging.FromContext(ctx).Debug("アーカイブから読み込み", "type", docType.String())
+			return p.store.Path(entry), false, nil
+		}
+	}
+
+	data, err := p.edinetAPI.DownloadDocument(ctx, docID, docType)
+	if err != nil {
+		return "", false, err
+	}
+
+	if p.store != nil {
+		entry, err := p.store.Put(docID, int(docType), p.edinetAPI.DocumentURL(docID, docType), data)
+		if err != nil {
+			return "", false, err
+		}
+		return p.store.Path(entry), false, nil
+	}
+
+	// 一時ファイルを作成
+	file := filepath.Join(p.workDir, fmt.Sprintf("%s_%d%s", docID, int(docType), docType.Ext()))
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return "", false, fmt.Errorf("%sファイル保存失敗: %v", docType, err)
+	}
+	return file, true, nil
+}
+
+// savePDF 書類のPDFを-pdf-dirに書類管理番号.pdfとして保存し、そのパスを返す（保存済みの場合はダウンロードしない）
+func (p *documentProcessor) savePDF(ctx context.Context, docID string) (string, error) {
+	dest := filepath.Join(p.cfg.PDFDir, docID+api.DocumentPDF.Ext())
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+
+	src, temporary, err := p.fetchDocument(ctx, docID, api.DocumentPDF)
+	if err != nil {
+		return "", err
+	}
+	if temporary {
+		defer os.Remove(src)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("PDF読み込みエラー: %v", err)
+	}
+	if err := utils.WriteFileAtomic(dest, data); err != nil {
+		return "", fmt.Errorf("PDF保存エラー: %v", err)
+	}
+	return dest, nil
+}
+
+// parseInstance 読み込み元の指定に従ってZIPからインスタンスを解析
+func parseInstance(ctx context.Context, xbrlParser *parser.XBRLParser, zipFile string, source string) (*models.XBRLInstance, error) {
+	switch source {
+	case config.SourceXBRL:
+		return xbrlParser.ParseZipXBRL(ctx, zipFile)
+	case config.SourceIXBRL:
+		return xbrlParser.ParseZipIXBRL(ctx, zipFile)
+	case config.SourceCSV:
+		return xbrlParser.ParseZipCSV(ctx, zipFile)
+	default:
+		return xbrlParser.ParseZip(ctx, zipFile)
+	}
+}
+
+// crossCheck .xbrlとiXBRLの両方を解析し、数値ファクトの差異を表示
+func crossCheck(ctx context.Context, xbrlParser *parser.XBRLParser, zipFile string) {
+	logger := logging.FromContext(ctx)
+	xbrlInstance, err := xbrlParser.ParseZipXBRL(ctx, zipFile)
+	if err != nil {
+		logger.Warn("突き合わせ不可: .xbrlの解析に失敗", "error", err)
+		return
+	}
+	ixbrlInstance, err := xbrlParser.ParseZipIXBRL(ctx, zipFile)
+	if err != nil {
+		logger.Warn("突き合わせ不可: iXBRLの解析に失敗", "error", err)
+		return
+	}
+
+	reportDiffs(ctx, "xbrl", "ixbrl", models.CompareInstances(xbrlInstance, ixbrlInstance))
+}
+
+// crossCheckCSV XBRL（ZIPの.xbrl、なければiXBRL）とXBRLから変換したCSV（type=5）を解析し、数値ファクトの差異を表示
+// parsedは解析に使った取得種別とそのファイルで、もう一方の種別を取得して比べる
+func (p *documentProcessor) crossCheckCSV(ctx context.Context, doc models.DocInfo, parsed api.DocumentType, parsedFile string) {
+	logger := logging.FromContext(ctx)
+	xbrlFile, csvFile := parsedFile, parsedFile
+	other := api.DocumentCSV
+	if parsed == api.DocumentCSV {
+		other = api.DocumentXBRL
+	}
+	if !other.Available(doc) {
+		logger.Info("突き合わせ不可: 提供されていない取得種別です", "type", other.String())
+		return
+	}
+	otherFile, temporary, err := p.fetchDocument(ctx, doc.DocID, other)
+	if err != nil {
+		logger.Warn("突き合わせ不可", "error", err)
+		return
+	}
+	if temporary {
+		defer os.Remove(otherFile)
+	}
+	if other == api.DocumentCSV {
+		csvFile = otherFile
+	} else {
+		xbrlFile = otherFile
+	}
+
+	xbrlInstance, err := p.xbrlParser.ParseZip(ctx, xbrlFile)
+	if err != nil {
+		logger.Warn("突き合わせ不可: XBRLの解析に失敗", "error", err)
+		return
+	}
+	csvInstance, err := p.xbrlParser.ParseZipCSV(ctx, csvFile)
+	if err != nil {
+		logger.Warn("突き合わせ不可: CSVの解析に失敗", "error", err)
+		return
+	}
+
+	reportDiffs(ctx, "xbrl", "csv", models.CompareInstances(xbrlInstance, csvInstance))
+}
+
+// reportDiffs 突き合わせの差異を表示（多い場合は先頭のみ）
+func reportDiffs(ctx context.Context, left, right string, diffs []models.FactDiff) {
+	const maxDiffs = 10
+
+	logger := logging.FromContext(ctx).With("compare", left+"/"+right)
+	if len(diffs) == 0 {
+		logger.Info("突き合わせ: 差異なし")
+		return
+	}
+
+	logger.Warn("突き合わせ: 差異があります", "diffs", len(diffs))
+	for i, d := range diffs {
+		if i == maxDiffs {
+			logger.Warn("突き合わせ: 表示しなかった差異", "remaining", len(diffs)-maxDiffs)
+			break
+		}
+		logger.Warn("突き合わせ: 差異", "name", d.Name, "context", d.ContextRef, left, d.Left, right, d.Right)
+	}
+}
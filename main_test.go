@@ -1,8 +1,27 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"edinet-api-test/internal/api"
+	"edinet-api-test/internal/config"
+	"edinet-api-test/internal/journal"
+	"edinet-api-test/internal/models"
+	"edinet-api-test/internal/syncstate"
 )
 
 func TestMain_Integration(t *testing.T) {
@@ -32,4 +51,238 @@ func TestProcessDocumentFunction(t *testing.T) {
 	// processDocument関数の存在確認
 	// 実際の実行は統合テストで行う
 	t.Log("processDocument関数のテスト")
-} 
\ No newline at end of file
+} 
+
+func TestSaveSyncState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	through := time.Date(2025, 7, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		last        string
+		interrupted bool
+		want        string
+	}{
+		{"全て取得", "2025-07-10", false, "2025-07-16"},
+		{"中断した場合は進めない", "2025-07-10", true, "2025-07-10"},
+		{"前回より戻さない", "2025-07-20", false, "2025-07-20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &syncstate.State{LastSyncedDate: tt.last, Documents: map[string]syncstate.Document{}}
+			if err := saveSyncState(state, path, through, tt.interrupted); err != nil {
+				t.Fatalf("保存エラー: %v", err)
+			}
+			loaded, err := syncstate.Load(path)
+			if err != nil {
+				t.Fatalf("読み込みエラー: %v", err)
+			}
+			if loaded.LastSyncedDate != tt.want {
+				t.Errorf("LastSyncedDate不一致: 期待=%s, 実際=%s", tt.want, loaded.LastSyncedDate)
+			}
+			if loaded.LastRunAt.IsZero() {
+				t.Error("LastRunAtが設定されていません")
+			}
+		})
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{permanentError{errors.New("XBRLパース失敗")}, true},
+		{fmt.Errorf("ダウンロード: %w", api.ErrNotFound), true},
+		{fmt.Errorf("ダウンロード: %w", api.ErrServerUnavailable), false},
+		{errors.New("通信エラー"), false},
+	}
+	for _, tt := range tests {
+		if got := isPermanent(tt.err); got != tt.want {
+			t.Errorf("isPermanent(%v) = %t, 期待=%t", tt.err, got, tt.want)
+		}
+	}
+}
+
+// runTestServer 文書一覧と書類のXBRL ZIPを返すテスト用のEDINET API
+type runTestServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	docs      map[string][]models.DocInfo // 日付ごとの書類
+	downloads int                         // 書類のダウンロード回数
+	limit     int                         // この回数のダウンロードの後はinterruptを呼ぶ（0の場合は制限なし）
+	interrupt func()
+}
+
+func newRunTestServer(t *testing.T, docs map[string][]models.DocInfo) *runTestServer {
+	s := &runTestServer{docs: docs}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *runTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/documents.json" {
+		results := s.docs[r.URL.Query().Get("date")]
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(models.DocumentListResponse{
+			Metadata: models.Metadata{Status: "200", Message: "OK"},
+			Results:  results,
+		})
+		return
+	}
+
+	docID := strings.TrimPrefix(r.URL.Path, "/documents/")
+	if s.limit > 0 && s.downloads >= s.limit {
+		// 強制終了の代わりに実行を中断する（このZIPの行は出力されない）
+		s.interrupt()
+	}
+	s.downloads++
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("XBRL/PublicDoc/jpcrp030000-asr-001_" + docID + ".xbrl")
+	fmt.Fprint(f, `<?xml version="1.0" encoding="UTF-8"?>
+<xbrli:xbrl xmlns:xbrli="http://www.xbrl.org/2003/instance" xmlns:jppfs_cor="http://disclosure.edinet-fsa.go.jp/taxonomy/jppfs/2024-11-01/jppfs_cor">
+  <xbrli:context id="CurrentYearDuration">
+    <xbrli:entity><xbrli:identifier scheme="http://disclosure.edinet-fsa.go.jp">E00001-000</xbrli:identifier></xbrli:entity>
+    <xbrli:period><xbrli:startDate>2024-04-01</xbrli:startDate><xbrli:endDate>2025-03-31</xbrli:endDate></xbrli:period>
+  </xbrli:context>
+  <jppfs_cor:NetSales contextRef="CurrentYearDuration" unitRef="JPY" decimals="-6">1000000000</jppfs_cor:NetSales>
+</xbrli:xbrl>`)
+	zw.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(buf.Bytes())
+}
+
+// downloadCount 書類のダウンロード回数
+func (s *runTestServer) downloadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.downloads
+}
+
+// readOutputDocIDs 出力ファイルの見出し行の数と、データ行の書類管理番号を返す
+func readOutputDocIDs(t *testing.T, path string) (headers int, docIDs []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("出力ファイル読み込みエラー: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("出力ファイルのCSVが不正です: %v", err)
+	}
+	column := len(config.JapaneseHeaders) - 3 // 書類管理番号
+	for _, record := range records {
+		if record[0] == config.JapaneseHeaders[0] {
+			headers++
+			continue
+		}
+		docIDs = append(docIDs, record[column])
+	}
+	return headers, docIDs
+}
+
+func TestRun_ResumeAfterInterrupt(t *testing.T) {
+	// 2日分・6件の有価証券報告書
+	docs := make(map[string][]models.DocInfo)
+	var all []string
+	for i := 1; i <= 6; i++ {
+		date := "2025-06-25"
+		if i > 3 {
+			date = "2025-06-26"
+		}
+		doc := models.DocInfo{
+			DocID:          fmt.Sprintf("S100A%03d", i),
+			EdinetCode:     fmt.Sprintf("E%05d", i),
+			SecCode:        fmt.Sprintf("%04d0", 1000+i),
+			FilerName:      fmt.Sprintf("テスト株式会社%d", i),
+			DocTypeCode:    "120",
+			PeriodStart:    "2024-04-01",
+			PeriodEnd:      "2025-03-31",
+			SubmitDateTime: date + " 15:00",
+			XbrlFlag:       "1",
+		}
+		docs[date] = append(docs[date], doc)
+		all = append(all, doc.DocID)
+	}
+	server := newRunTestServer(t, docs)
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		APIKey:            "test-key",
+		StartDate:         "2025-06-25",
+		EndDate:           "2025-06-26",
+		OutputFile:        filepath.Join(dir, "out.csv"),
+		JournalFile:       filepath.Join(dir, "out.csv.journal"),
+		Consolidation:     config.ConsolidationConsolidated,
+		Source:            config.SourceAuto,
+		RequestsPerSecond: 1000,
+		Timeout:           5 * time.Second,
+		Workers:           2,
+		BaseURL:           server.URL,
+	}
+
+	// 3件ダウンロードした後に中断する
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.limit, server.interrupt = 3, cancel
+	if err := run(ctx, cfg); err != nil {
+		t.Fatalf("1回目の実行エラー: %v", err)
+	}
+	_, first := readOutputDocIDs(t, cfg.OutputFile)
+	if len(first) == 0 || len(first) >= len(all) {
+		t.Fatalf("中断前に一部の書類だけが出力されるべきです: %v", first)
+	}
+
+	// ジャーナルに記録する前に強制終了された書類の行（書きかけの行を含む）を残す
+	out, err := os.OpenFile(cfg.OutputFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.WriteString("2025-06-26,10060,記録前の行\n2025-06-26,100")
+	out.Close()
+
+	// 同じ設定に-resumeを付けて再開する
+	server.mu.Lock()
+	server.limit, server.interrupt = 0, nil
+	server.downloads = 0
+	server.mu.Unlock()
+	cfg.Resume = true
+	if err := run(context.Background(), cfg); err != nil {
+		t.Fatalf("再開した実行のエラー: %v", err)
+	}
+
+	// 出力済みの書類はダウンロードし直さない
+	if got, want := server.downloadCount(), len(all)-len(first); got != want {
+		t.Errorf("再開時のダウンロード数不一致: 期待=%d, 実際=%d", want, got)
+	}
+
+	// 見出し行は1つ、全ての書類が重複も欠落もなく日付・書類管理番号の順に並ぶ
+	headers, docIDs := readOutputDocIDs(t, cfg.OutputFile)
+	if headers != 1 {
+		t.Errorf("見出し行の数不一致: 期待=1, 実際=%d", headers)
+	}
+	if strings.Join(docIDs, ",") != strings.Join(all, ",") {
+		t.Errorf("出力した書類不一致: 期待=%v, 実際=%v", all, docIDs)
+	}
+
+	// 出力ファイルはジャーナルに記録した最後のサイズと一致する（記録前の行は切り詰められている）
+	data, _ := os.ReadFile(cfg.OutputFile)
+	if strings.Contains(string(data), "記録前の行") {
+		t.Error("ジャーナルに記録する前の行が残っています")
+	}
+	start, end, _ := cfg.GetDateRange()
+	jrnl, err := journal.Open(cfg.JournalFile, journalKey(cfg, start, end), true)
+	if err != nil {
+		t.Fatalf("ジャーナルのオープンエラー: %v", err)
+	}
+	defer jrnl.Close()
+	if jrnl.OutputSize() != int64(len(data)) {
+		t.Errorf("出力ファイルのサイズ不一致: ジャーナル=%d, 実際=%d", jrnl.OutputSize(), len(data))
+	}
+}